@@ -60,6 +60,7 @@ func main() {
 					if err != nil {
 						panic(err)
 					}
+					defer rapio.CloseBinaries(recording)
 					fmt.Fprintf(c.App.Writer, "Time to read in: %s\n", time.Now().Sub(timeLoadStarted))
 					printRecording(c.App.Writer, recording, 0)
 
@@ -87,6 +88,7 @@ func main() {
 					if err != nil {
 						panic(err)
 					}
+					defer rapio.CloseBinaries(recBack)
 					printRecording(c.App.Writer, recBack, 0)
 
 					fmt.Fprintf(c.App.Writer, "New Size: %s\n", newSize)
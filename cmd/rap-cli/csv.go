@@ -9,9 +9,39 @@ import (
 
 	"github.com/recolude/rap/format"
 	"github.com/recolude/rap/format/collection/position"
+	"github.com/recolude/rap/format/collection/vector2"
+	"github.com/recolude/rap/format/encoding"
+	positionEncoding "github.com/recolude/rap/format/encoding/position"
+	vector2Encoding "github.com/recolude/rap/format/encoding/vector2"
 	"github.com/recolude/rap/format/metadata"
 )
 
+// csvEncoders resolves every registered encoder, storing positions from CSVs
+// losslessly whether or not they have a z axis.
+func csvEncoders() []encoding.Encoder {
+	return encoding.RegisteredWith(
+		positionEncoding.NewEncoder(positionEncoding.Raw64),
+		vector2Encoding.NewEncoder(vector2Encoding.Raw64),
+	)
+}
+
+// positionCollection builds the collection of captures read from a CSV,
+// dropping the z axis when the CSV didn't have one.
+func positionCollection(captures []position.Capture, twoDimensional bool) format.CaptureCollection {
+	if !twoDimensional {
+		return position.NewCollection("Position", captures)
+	}
+
+	flattened := make([]vector2.Capture, len(captures))
+	for i, capture := range captures {
+		flattened[i] = vector2.NewCapture(capture.Time(), capture.Position().X(), capture.Position().Y())
+	}
+	return vector2.NewCollection("Position", flattened)
+}
+
+// RecordingFromCSV builds a recording per id and name found within the CSV,
+// each containing a position collection, or a vector2 collection when the
+// CSV has no z column.
 func RecordingFromCSV(in io.Reader) (format.Recording, error) {
 	csvReader := csv.NewReader(in)
 
@@ -86,9 +116,12 @@ func RecordingFromCSV(in io.Reader) (format.Recording, error) {
 			return nil, fmt.Errorf("unable to parse y entry: %w", err)
 		}
 
-		z, err := strconv.ParseFloat(strings.TrimSpace(row[posZIndex]), 64)
-		if err != nil {
-			return nil, fmt.Errorf("unable to parse z entry: %w", err)
+		z := 0.0
+		if posZIndex != -1 {
+			z, err = strconv.ParseFloat(strings.TrimSpace(row[posZIndex]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse z entry: %w", err)
+			}
 		}
 
 		if workingData[id] == nil {
@@ -107,7 +140,7 @@ func RecordingFromCSV(in io.Reader) (format.Recording, error) {
 					id,
 					name,
 					[]format.CaptureCollection{
-						position.NewCollection("Position", captures),
+						positionCollection(captures, posZIndex == -1),
 					},
 					nil,
 					metadata.EmptyBlock(),
@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/recolude/rap/format/collection/position"
+	"github.com/recolude/rap/format/collection/vector2"
+	rapio "github.com/recolude/rap/format/io"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -41,3 +43,59 @@ func Test_CSV_Simple(t *testing.T) {
 		}
 	}
 }
+
+func Test_CSV_TwoDimensional(t *testing.T) {
+	// ARRANGE ================================================================
+	csv := `id, name, time, x, y
+0, bob, 1, 2, 3
+0, bob, 2, 5, 6
+`
+
+	// ACT ====================================================================
+	recording, err := RecordingFromCSV(bytes.NewReader([]byte(csv)))
+
+	// ASSERT =================================================================
+	assert.NoError(t, err)
+	if assert.Len(t, recording.Recordings(), 1) == false {
+		return
+	}
+
+	if assert.Len(t, recording.Recordings()[0].CaptureCollections(), 1) == false {
+		return
+	}
+
+	collection := recording.Recordings()[0].CaptureCollections()[0]
+	assert.Equal(t, "Position", collection.Name())
+	assert.Equal(t, "recolude.vector2", collection.Signature())
+	if assert.Len(t, collection.Captures(), 2) {
+		assert.Equal(t, vector2.NewCapture(1, 2, 3), collection.Captures()[0])
+		assert.Equal(t, vector2.NewCapture(2, 5, 6), collection.Captures()[1])
+	}
+}
+
+func Test_CSV_TwoDimensionalIsLossless(t *testing.T) {
+	// ARRANGE ================================================================
+	csv := `id, name, time, x, y
+0, bob, 1, 2.123456789, 3.987654321
+0, bob, 2, 5000.000001, -6.5
+`
+	recording, err := RecordingFromCSV(bytes.NewReader([]byte(csv)))
+	assert.NoError(t, err)
+	fileData := new(bytes.Buffer)
+
+	// ACT ====================================================================
+	_, errWrite := rapio.NewWriter(csvEncoders(), true, fileData, rapio.Raw64).Write(recording)
+	recOut, _, errRead := rapio.Load(bytes.NewReader(fileData.Bytes()))
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	if !assert.NoError(t, errRead) || !assert.Len(t, recOut.Recordings(), 1) {
+		return
+	}
+
+	collection := recOut.Recordings()[0].CaptureCollections()[0]
+	if assert.Len(t, collection.Captures(), 2) {
+		assert.Equal(t, vector2.NewCapture(1, 2.123456789, 3.987654321), collection.Captures()[0])
+		assert.Equal(t, vector2.NewCapture(2, 5000.000001, -6.5), collection.Captures()[1])
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	rapio "github.com/recolude/rap/format/io"
+	"github.com/urfave/cli/v2"
+)
+
+// readEncryptionKey reads an AES-256 key from a file, stored either as the
+// raw 32 bytes or hex encoded.
+func readEncryptionKey(keyFile string) ([]byte, error) {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) == 32 {
+		return data, nil
+	}
+
+	key, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("%s: expected a 32 byte key, either raw or hex encoded", keyFile)
+	}
+	return key, nil
+}
+
+// keyID names the key within a key file after the file itself.
+func keyID(keyFile string) string {
+	name := filepath.Base(keyFile)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// readerEncryption builds the options needed to read recordings encrypted
+// with the key file the CLI was given, if any.
+func readerEncryption(c *cli.Context) ([]rapio.ReaderOption, error) {
+	if !c.IsSet("key-file") {
+		return nil, nil
+	}
+
+	key, err := readEncryptionKey(c.String("key-file"))
+	if err != nil {
+		return nil, err
+	}
+	return []rapio.ReaderOption{rapio.Decryption(rapio.StaticKey(key))}, nil
+}
+
+// writerEncryption builds the options needed to encrypt recordings with the
+// key file the CLI was given, if any.
+func writerEncryption(c *cli.Context) ([]rapio.WriterOption, error) {
+	if !c.IsSet("key-file") {
+		return nil, nil
+	}
+
+	keyFile := c.String("key-file")
+	key, err := readEncryptionKey(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return []rapio.WriterOption{rapio.Encryption(rapio.StaticKey(key), keyID(keyFile))}, nil
+}
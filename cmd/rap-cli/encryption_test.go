@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/io"
+	"github.com/recolude/rap/format/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ReadEncryptionKey(t *testing.T) {
+	// ARRANGE ================================================================
+	dir := t.TempDir()
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+
+	rawFile := filepath.Join(dir, "raw.key")
+	hexFile := filepath.Join(dir, "hex.key")
+	badFile := filepath.Join(dir, "bad.key")
+	assert.NoError(t, os.WriteFile(rawFile, key, 0600))
+	assert.NoError(t, os.WriteFile(hexFile, []byte(hex.EncodeToString(key)+"\n"), 0600))
+	assert.NoError(t, os.WriteFile(badFile, []byte("not a key"), 0600))
+
+	// ACT ====================================================================
+	raw, errRaw := readEncryptionKey(rawFile)
+	hexed, errHex := readEncryptionKey(hexFile)
+	_, errBad := readEncryptionKey(badFile)
+
+	// ASSERT =================================================================
+	assert.NoError(t, errRaw)
+	assert.NoError(t, errHex)
+	assert.Error(t, errBad)
+	assert.Equal(t, key, raw)
+	assert.Equal(t, key, hexed)
+	assert.Equal(t, "hex", keyID(hexFile))
+}
+
+func Test_KeyFileDecryptsRecordings(t *testing.T) {
+	// ARRANGE ================================================================
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "session.key")
+	assert.NoError(t, os.WriteFile(keyFile, key, 0600))
+
+	appIn := bytes.Buffer{}
+	appOut := bytes.Buffer{}
+	app := BuildApp(&appIn, &appOut, &bytes.Buffer{})
+
+	rapWriter := io.NewRecoludeWriter(&appIn, io.Encryption(io.StaticKey(key), "session"))
+	_, writeErr := rapWriter.Write(format.NewRecording("", "secret", nil, nil, metadata.EmptyBlock(), nil, nil))
+	encrypted := append([]byte{}, appIn.Bytes()...)
+
+	// ACT ====================================================================
+	errWithKey := app.Run([]string{"rap-cli", "--key-file", keyFile, "to-json"})
+	appIn.Write(encrypted)
+	errWithoutKey := app.Run([]string{"rap-cli", "to-json"})
+
+	// ASSERT =================================================================
+	assert.NoError(t, writeErr)
+	assert.NoError(t, errWithKey)
+	assert.Contains(t, appOut.String(), `"name": "secret"`)
+	assert.ErrorIs(t, errWithoutKey, io.ErrEncrypted)
+}
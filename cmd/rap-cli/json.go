@@ -7,6 +7,7 @@ import (
 
 	"github.com/recolude/rap/format"
 	"github.com/recolude/rap/format/collection/event"
+	"github.com/recolude/rap/format/collection/vector2"
 	"github.com/recolude/rap/format/metadata"
 )
 
@@ -70,6 +71,22 @@ func toJson(out io.Writer, recording format.Recording, depth int) error {
 				}
 			}
 			fmt.Fprintf(out, "%s\t]\n", subsubIndentation)
+		} else if vectorCollection, ok := collection.(vector2.Collection); ok {
+			fmt.Fprintf(out, ",\n%s\t\"captures\": [\n", subsubIndentation)
+			for capIndex := 0; capIndex < vectorCollection.Length(); capIndex++ {
+				capture := vectorCollection.CaptureAt(capIndex).(vector2.Capture)
+
+				fmt.Fprintf(out, "%s\t\t{\n", subsubIndentation)
+				fmt.Fprintf(out, "%s\t\t\t\"time\": %f,\n", subsubIndentation, capture.Time())
+				fmt.Fprintf(out, "%s\t\t\t\"data\": {\"x\": %f, \"y\": %f}\n", subsubIndentation, capture.Value().X(), capture.Value().Y())
+				fmt.Fprintf(out, "%s\t\t}", subsubIndentation)
+				if capIndex < vectorCollection.Length()-1 {
+					fmt.Fprintf(out, ",\n")
+				} else {
+					fmt.Fprintf(out, "\n")
+				}
+			}
+			fmt.Fprintf(out, "%s\t]\n", subsubIndentation)
 		} else {
 			fmt.Fprint(out, "\n")
 		}
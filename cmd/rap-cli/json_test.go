@@ -7,6 +7,7 @@ import (
 	"github.com/recolude/rap/format"
 	"github.com/recolude/rap/format/collection/event"
 	"github.com/recolude/rap/format/collection/position"
+	"github.com/recolude/rap/format/collection/vector2"
 	"github.com/recolude/rap/format/io"
 	"github.com/recolude/rap/format/metadata"
 	"github.com/stretchr/testify/assert"
@@ -143,3 +144,66 @@ func Test_JSON(t *testing.T) {
 	]
 }`, appOut.String())
 }
+
+func Test_JSON_Vector2(t *testing.T) {
+	// ARRANGE ================================================================
+	appIn := bytes.Buffer{}
+	appOut := bytes.Buffer{}
+	appErrOut := bytes.Buffer{}
+	app := BuildApp(&appIn, &appOut, &appErrOut)
+	if assert.NotNil(t, app) == false {
+		return
+	}
+
+	rapWriter := io.NewRecoludeWriter(&appIn)
+	_, writeErr := rapWriter.Write(
+		format.NewRecording(
+			"",
+			"parent",
+			[]format.CaptureCollection{
+				vector2.NewCollection(
+					"Cursor",
+					[]vector2.Capture{
+						vector2.NewCapture(1, 2, 3),
+						vector2.NewCapture(2, -4, 5),
+					},
+				),
+			},
+			nil,
+			metadata.EmptyBlock(),
+			nil,
+			nil,
+		),
+	)
+
+	// ACT ====================================================================
+	err := app.Run([]string{"rap-cli", "to-json"})
+
+	// ASSERT =================================================================
+	assert.NoError(t, err)
+	assert.NoError(t, writeErr)
+	assert.Equal(t, "", appErrOut.String())
+	assert.Equal(t, `{
+	"id": "",
+	"name": "parent",
+	"metadata": {},
+	"collections": [
+		{
+			"name": "Cursor",
+			"signature" : "recolude.vector2",
+			"count" : 2,
+			"captures": [
+				{
+					"time": 1.000000,
+					"data": {"x": 2.000000, "y": 3.000000}
+				},
+				{
+					"time": 1.999992,
+					"data": {"x": -4.000000, "y": 5.000000}
+				}
+			]
+		}
+	],
+	"recordings": []
+}`, appOut.String())
+}
@@ -10,10 +10,9 @@ import (
 
 	"github.com/recolude/rap/format"
 	"github.com/recolude/rap/format/encoding"
-	"github.com/recolude/rap/format/encoding/enum"
 	"github.com/recolude/rap/format/encoding/euler"
-	"github.com/recolude/rap/format/encoding/event"
 	"github.com/recolude/rap/format/encoding/position"
+	"github.com/recolude/rap/format/encoding/transform"
 	rapio "github.com/recolude/rap/format/io"
 	"github.com/recolude/rap/format/parsing"
 	"github.com/urfave/cli/v2"
@@ -23,6 +22,27 @@ func kb(byteCount int) string {
 	return fmt.Sprintf("%d kb", byteCount/1024)
 }
 
+// compactEncoders resolves every registered encoder, preferring the smaller
+// position, euler and transform techniques for files written by the CLI.
+func compactEncoders() []encoding.Encoder {
+	return encoding.RegisteredWith(
+		position.NewEncoder(position.Oct24),
+		euler.NewEncoder(euler.Raw16),
+		transform.NewEncoder(position.Oct24, euler.Raw16, position.Oct24),
+	)
+}
+
+// loadRecording reads a recording, passing through any collections written
+// with encoders the CLI doesn't have so they survive being rewritten.
+func loadRecording(c *cli.Context, in io.Reader, options ...rapio.ReaderOption) (format.Recording, int, error) {
+	encryption, err := readerEncryption(c)
+	if err != nil {
+		return nil, 0, err
+	}
+	options = append(options, rapio.PreserveUnknownEncoders())
+	return rapio.Load(in, append(options, encryption...)...)
+}
+
 func BuildApp(in io.Reader, out io.Writer, errOut io.Writer) *cli.App {
 	return &cli.App{
 		Name:  "RAP CLI",
@@ -37,6 +57,12 @@ func BuildApp(in io.Reader, out io.Writer, errOut io.Writer) *cli.App {
 		Reader:    in,
 		Writer:    out,
 		ErrWriter: errOut,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "key-file",
+				Usage: "File containing a 32 byte AES-256 key, raw or hex encoded, used to decrypt recordings read and encrypt recordings written",
+			},
+		},
 		Commands: []*cli.Command{
 			{
 				Name: "summarize",
@@ -63,10 +89,11 @@ func BuildApp(in io.Reader, out io.Writer, errOut io.Writer) *cli.App {
 						return err
 					}
 
-					recording, _, err := rapio.Load(file)
+					recording, _, err := loadRecording(c, file)
 					if err != nil {
 						return err
 					}
+					defer rapio.CloseBinaries(recording)
 
 					printSummary(c.App.Writer, recording, size)
 					return nil
@@ -91,13 +118,13 @@ func BuildApp(in io.Reader, out io.Writer, errOut io.Writer) *cli.App {
 						if err != nil {
 							return err
 						}
-						recording, _, err = rapio.Load(file)
+						recording, _, err = loadRecording(c, file)
 						if err != nil {
 							return err
 						}
 					} else {
 						var err error
-						recording, _, err = rapio.Load(c.App.Reader)
+						recording, _, err = loadRecording(c, c.App.Reader)
 						if err != nil {
 							return err
 						}
@@ -106,6 +133,7 @@ func BuildApp(in io.Reader, out io.Writer, errOut io.Writer) *cli.App {
 					if recording == nil {
 						return errors.New("can not build json from nil recording")
 					}
+					defer rapio.CloseBinaries(recording)
 
 					return toJson(c.App.Writer, recording, 0)
 				},
@@ -166,14 +194,14 @@ func BuildApp(in io.Reader, out io.Writer, errOut io.Writer) *cli.App {
 						rapStream = file
 					}
 
-					encoders := []encoding.Encoder{
-						event.NewEncoder(),
-						position.NewEncoder(position.Oct24),
-						euler.NewEncoder(euler.Raw16),
-						enum.NewEncoder(),
+					encoders := compactEncoders()
+
+					encryption, err := writerEncryption(c)
+					if err != nil {
+						return err
 					}
 
-					recordingWriter := rapio.NewWriter(encoders, true, rapStream, rapio.BST16)
+					recordingWriter := rapio.NewWriter(encoders, true, rapStream, rapio.BST16, encryption...)
 					_, err = recordingWriter.Write(builtRecording)
 					return err
 				},
@@ -187,6 +215,10 @@ func BuildApp(in io.Reader, out io.Writer, errOut io.Writer) *cli.App {
 						Required: true,
 						Usage:    "File to upgrade",
 					},
+					&cli.BoolFlag{
+						Name:  "quaternions",
+						Usage: "Converts v1 rotations to quaternions",
+					},
 				},
 				Usage: "Upgrades a file from v1 to v2",
 				Action: func(c *cli.Context) error {
@@ -196,23 +228,122 @@ func BuildApp(in io.Reader, out io.Writer, errOut io.Writer) *cli.App {
 						return err
 					}
 
-					recording, _, err := rapio.Load(file)
+					options := make([]rapio.ReaderOption, 0)
+					if c.Bool("quaternions") {
+						options = append(options, rapio.ConvertV1Rotations())
+					}
+
+					recording, _, err := loadRecording(c, file, options...)
 					if err != nil {
 						return err
 					}
+					defer rapio.CloseBinaries(recording)
+
+					encoders := compactEncoders()
 
-					encoders := []encoding.Encoder{
-						event.NewEncoder(),
-						position.NewEncoder(position.Oct24),
-						euler.NewEncoder(euler.Raw16),
-						enum.NewEncoder(),
+					encryption, err := writerEncryption(c)
+					if err != nil {
+						return err
 					}
 
-					recordingWriter := rapio.NewWriter(encoders, true, c.App.Writer, rapio.BST16)
+					recordingWriter := rapio.NewWriter(encoders, true, c.App.Writer, rapio.BST16, encryption...)
 					_, err = recordingWriter.Write(recording)
 					return err
 				},
 			},
+			{
+				Name: "verify",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "file",
+						Aliases:  []string{"f"},
+						Required: true,
+						Usage:    "File to verify",
+					},
+				},
+				Usage: "Checks a file's checksums, reporting any damaged sections",
+				Action: func(c *cli.Context) error {
+					file, err := os.Open(c.String("file"))
+					if err != nil {
+						return err
+					}
+					defer file.Close()
+
+					encryption, err := readerEncryption(c)
+					if err != nil {
+						return err
+					}
+
+					report, err := rapio.NewReader(encoding.RegisteredVersions(), file, append([]rapio.ReaderOption{rapio.PreserveUnknownEncoders()}, encryption...)...).Verify()
+					if err != nil {
+						return err
+					}
+
+					if !printVerifyReport(c.App.Writer, report) {
+						return fmt.Errorf("%d damaged sections found", len(report.Damaged))
+					}
+					return nil
+				},
+			},
+			{
+				Name: "sign",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "file",
+						Aliases:  []string{"f"},
+						Required: true,
+						Usage:    "File to sign",
+					},
+					&cli.StringFlag{
+						Name:     "key",
+						Aliases:  []string{"k"},
+						Required: true,
+						Usage:    "PEM file containing the ed25519 private key to sign with",
+					},
+				},
+				Usage: "Signs a file, writing the signed recording out",
+				Action: func(c *cli.Context) error {
+					file, err := os.Open(c.String("file"))
+					if err != nil {
+						return err
+					}
+					defer file.Close()
+
+					return signRecording(file, c.App.Writer, c.String("key"))
+				},
+			},
+			{
+				Name: "verify-signature",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "file",
+						Aliases:  []string{"f"},
+						Required: true,
+						Usage:    "File to verify",
+					},
+					&cli.StringSliceFlag{
+						Name:     "key",
+						Aliases:  []string{"k"},
+						Required: true,
+						Usage:    "PEM file containing trusted ed25519 public keys",
+					},
+				},
+				Usage: "Checks a file was signed by a trusted key and is unaltered since",
+				Action: func(c *cli.Context) error {
+					file, err := os.Open(c.String("file"))
+					if err != nil {
+						return err
+					}
+					defer file.Close()
+
+					encryption, err := readerEncryption(c)
+					if err != nil {
+						return err
+					}
+
+					return verifyRecordingSignature(file, c.App.Writer, c.StringSlice("key"), encryption...)
+				},
+			},
 			{
 				Name: "from-csv",
 				Flags: []cli.Flag{
@@ -236,11 +367,12 @@ func BuildApp(in io.Reader, out io.Writer, errOut io.Writer) *cli.App {
 						return err
 					}
 
-					encoders := []encoding.Encoder{
-						position.NewEncoder(position.Raw64),
+					encryption, err := writerEncryption(c)
+					if err != nil {
+						return err
 					}
 
-					recordingWriter := rapio.NewWriter(encoders, true, c.App.Writer, rapio.Raw64)
+					recordingWriter := rapio.NewWriter(csvEncoders(), true, c.App.Writer, rapio.Raw64, encryption...)
 					_, err = recordingWriter.Write(recording)
 					return err
 				},
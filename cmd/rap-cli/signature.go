@@ -0,0 +1,65 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/recolude/rap/format/encoding"
+	rapio "github.com/recolude/rap/format/io"
+)
+
+// loadTrustedKeys reads every public key found within the PEM files provided.
+func loadTrustedKeys(keyFiles []string) ([]ed25519.PublicKey, error) {
+	trusted := make([]ed25519.PublicKey, 0)
+	for _, keyFile := range keyFiles {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		keys, err := rapio.ParsePublicKeysPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", keyFile, err)
+		}
+		trusted = append(trusted, keys...)
+	}
+	return trusted, nil
+}
+
+// signRecording signs the recording read from in with the private key found
+// within keyFile, writing the signed recording to out.
+func signRecording(in io.Reader, out io.Writer, keyFile string) error {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return err
+	}
+
+	key, err := rapio.ParsePrivateKeyPEM(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", keyFile, err)
+	}
+
+	_, err = rapio.SignRecording(in, out, key)
+	return err
+}
+
+// verifyRecordingSignature reads the recording in full, failing unless it was
+// signed by one of the keys found within keyFiles and is unaltered since.
+func verifyRecordingSignature(in io.Reader, out io.Writer, keyFiles []string, options ...rapio.ReaderOption) error {
+	trusted, err := loadTrustedKeys(keyFiles)
+	if err != nil {
+		return err
+	}
+
+	options = append([]rapio.ReaderOption{rapio.PreserveUnknownEncoders(), rapio.TrustedKeys(trusted...)}, options...)
+	recording, _, err := rapio.NewReader(encoding.RegisteredVersions(), in, options...).Read()
+	if err != nil {
+		return err
+	}
+	rapio.CloseBinaries(recording)
+
+	fmt.Fprintln(out, "Signature valid")
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/position"
+	rapio "github.com/recolude/rap/format/io"
+	"github.com/recolude/rap/format/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeKeyFiles(t *testing.T, dir string) (string, string) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	assert.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	assert.NoError(t, err)
+
+	privateFile := filepath.Join(dir, "private.pem")
+	publicFile := filepath.Join(dir, "public.pem")
+	assert.NoError(t, os.WriteFile(privateFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0600))
+	assert.NoError(t, os.WriteFile(publicFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0600))
+	return privateFile, publicFile
+}
+
+func Test_SignAndVerifySignature(t *testing.T) {
+	// ARRANGE ================================================================
+	dir := t.TempDir()
+	privateFile, publicFile := writeKeyFiles(t, dir)
+	otherDir := t.TempDir()
+	_, otherPublicFile := writeKeyFiles(t, otherDir)
+
+	recording := format.NewRecording(
+		"",
+		"Test Recording",
+		[]format.CaptureCollection{
+			position.NewCollection("Position", []position.Capture{
+				position.NewCapture(1, 1, 2, 3),
+			}),
+		},
+		nil,
+		metadata.EmptyBlock(),
+		nil,
+		nil,
+	)
+
+	original := bytes.Buffer{}
+	_, err := rapio.NewWriter(compactEncoders(), true, &original, rapio.BST16).Write(recording)
+	assert.NoError(t, err)
+
+	signed := bytes.Buffer{}
+	out := bytes.Buffer{}
+
+	// ACT ====================================================================
+	errSign := signRecording(bytes.NewReader(original.Bytes()), &signed, privateFile)
+	errVerify := verifyRecordingSignature(bytes.NewReader(signed.Bytes()), &out, []string{otherPublicFile, publicFile})
+	errUntrusted := verifyRecordingSignature(bytes.NewReader(signed.Bytes()), &bytes.Buffer{}, []string{otherPublicFile})
+	errUnsigned := verifyRecordingSignature(bytes.NewReader(original.Bytes()), &bytes.Buffer{}, []string{publicFile})
+
+	// ASSERT =================================================================
+	assert.NoError(t, errSign)
+	assert.NoError(t, errVerify)
+	assert.Equal(t, "Signature valid\n", out.String())
+	assert.ErrorIs(t, errUntrusted, rapio.ErrUntrustedSigner)
+	assert.ErrorIs(t, errUnsigned, rapio.ErrUnsigned)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	rapio "github.com/recolude/rap/format/io"
+)
+
+// printVerifyReport writes out every damaged section of a recording, returning
+// whether or not the recording was found to be intact.
+func printVerifyReport(out io.Writer, report rapio.VerifyReport) bool {
+	if !report.Checksummed {
+		fmt.Fprintln(out, "Recording has no checksums, only checked that it can be read")
+	}
+
+	for _, damage := range report.Damaged {
+		location := "header"
+		if len(damage.Path) > 0 {
+			location = strings.Join(damage.Path, "/")
+		}
+
+		if damage.Collection != "" {
+			location += ", collection " + damage.Collection
+		}
+
+		if damage.Binary != "" {
+			location += ", binary " + damage.Binary
+		}
+
+		fmt.Fprintf(out, "Damaged: %s (byte %d)\n", location, damage.Offset)
+	}
+
+	if len(report.Damaged) > 0 {
+		return false
+	}
+
+	fmt.Fprintln(out, "ok")
+	return true
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	rapio "github.com/recolude/rap/format/io"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PrintVerifyReport(t *testing.T) {
+	// ARRANGE ================================================================
+	report := rapio.VerifyReport{
+		Checksummed: true,
+		Damaged: []*rapio.DecodeError{
+			{Offset: 12, Err: rapio.ErrChecksumMismatch},
+			{Offset: 80, Path: []string{"root", "child"}, Collection: "Position", Err: rapio.ErrChecksumMismatch},
+			{Offset: 120, Path: []string{"root"}, Binary: "voice", Err: rapio.ErrChecksumMismatch},
+		},
+	}
+	out := bytes.Buffer{}
+
+	// ACT ====================================================================
+	intact := printVerifyReport(&out, report)
+
+	// ASSERT =================================================================
+	assert.False(t, intact)
+	assert.Equal(t, "Damaged: header (byte 12)\nDamaged: root/child, collection Position (byte 80)\nDamaged: root, binary voice (byte 120)\n", out.String())
+}
+
+func Test_PrintVerifyReport_Intact(t *testing.T) {
+	// ARRANGE ================================================================
+	checksummed := bytes.Buffer{}
+	unchecksummed := bytes.Buffer{}
+
+	// ACT ====================================================================
+	intact := printVerifyReport(&checksummed, rapio.VerifyReport{Checksummed: true})
+	printVerifyReport(&unchecksummed, rapio.VerifyReport{})
+
+	// ASSERT =================================================================
+	assert.True(t, intact)
+	assert.Equal(t, "ok\n", checksummed.String())
+	assert.Equal(t, "Recording has no checksums, only checked that it can be read\nok\n", unchecksummed.String())
+}
@@ -11,17 +11,34 @@ type Capture struct {
 	euler vector3.Float64
 }
 
-func NewEulerZXYCapture(time, eulerX, eulerY, eulerZ float64) Capture {
+// NewCapture builds a capture of euler angles in degrees, interpreted in the
+// rotation order of the collection it's placed in.
+func NewCapture(time, eulerX, eulerY, eulerZ float64) Capture {
 	return Capture{
 		time:  time,
 		euler: vector3.New(eulerX, eulerY, eulerZ),
 	}
 }
 
+// NewEulerZXYCapture builds a capture of euler angles in degrees, meant for
+// collections using the ZXY rotation order.
+func NewEulerZXYCapture(time, eulerX, eulerY, eulerZ float64) Capture {
+	return NewCapture(time, eulerX, eulerY, eulerZ)
+}
+
 func (c Capture) Time() float64 {
 	return c.time
 }
 
+// Angles are the rotations in degrees around each axis, applied in the
+// rotation order of the collection the capture belongs to.
+func (c Capture) Angles() vector3.Float64 {
+	return c.euler
+}
+
+// EulerZXY are the rotations in degrees around each axis, which are only
+// applied in the ZXY order when the collection the capture belongs to uses
+// it.
 func (c Capture) EulerZXY() vector3.Float64 {
 	return c.euler
 }
@@ -6,12 +6,22 @@ import (
 
 type Collection struct {
 	name     string
+	order    RotationOrder
 	captures []Capture
 }
 
+// NewCollection builds a collection of captures using the ZXY rotation
+// order.
 func NewCollection(name string, captures []Capture) Collection {
+	return NewCollectionWithOrder(name, ZXY, captures)
+}
+
+// NewCollectionWithOrder builds a collection of captures whose angles are
+// applied in the rotation order provided.
+func NewCollectionWithOrder(name string, order RotationOrder, captures []Capture) Collection {
 	return Collection{
 		name:     name,
+		order:    order,
 		captures: captures,
 	}
 }
@@ -20,6 +30,21 @@ func (s Collection) Name() string {
 	return s.name
 }
 
+// Order is the order the angles of every capture are applied in.
+func (s Collection) Order() RotationOrder {
+	return s.order
+}
+
+// WithOrder converts every capture into the rotation order provided,
+// producing the same rotations.
+func (s Collection) WithOrder(order RotationOrder) Collection {
+	captures := make([]Capture, len(s.captures))
+	for i, c := range s.captures {
+		captures[i] = Capture{time: c.time, euler: Convert(c.euler, s.order, order)}
+	}
+	return NewCollectionWithOrder(s.name, order, captures)
+}
+
 func (s Collection) Captures() []format.Capture {
 	returnVal := make([]format.Capture, len(s.captures))
 	for i := range s.captures {
@@ -39,7 +64,7 @@ func (c Collection) Slice(beginning, end float64) format.CaptureCollection {
 			slicedCaptures = append(slicedCaptures, c)
 		}
 	}
-	return NewCollection(c.Name(), slicedCaptures)
+	return NewCollectionWithOrder(c.Name(), c.order, slicedCaptures)
 }
 
 func (c Collection) Start() float64 {
@@ -0,0 +1,66 @@
+package euler
+
+import "fmt"
+
+// RotationOrder is the order rotations around each axis are applied in, with
+// ZXY rotating around the Z axis first, then the X axis, and then the Y axis.
+// Every rotation is around the original, unrotated axes.
+type RotationOrder byte
+
+const (
+	// ZXY is the order used by Unity, and the order assumed by collections
+	// built without one
+	ZXY RotationOrder = iota
+
+	// XYZ is the order used by Unreal, rolling, then pitching, then yawing.
+	// It's also the yaw, pitch, roll convention common to robotics and
+	// aerospace, which applies the same rotations around the rotated axes
+	// starting from Z
+	XYZ
+
+	XZY
+
+	YXZ
+
+	YZX
+
+	// ZYX rotates around Z, then Y, then X, all around the original axes.
+	// Data in the yaw, pitch, roll convention of robotics and aerospace
+	// belongs in XYZ instead
+	ZYX
+)
+
+var rotationOrderNames = []string{"ZXY", "XYZ", "XZY", "YXZ", "YZX", "ZYX"}
+
+// rotationOrderAxes are the indices of the axes rotated around, in the order
+// they are applied.
+var rotationOrderAxes = [][3]int{
+	{2, 0, 1},
+	{0, 1, 2},
+	{0, 2, 1},
+	{1, 0, 2},
+	{1, 2, 0},
+	{2, 1, 0},
+}
+
+// ParseRotationOrder finds the order with the name provided, such as "XYZ".
+func ParseRotationOrder(name string) (RotationOrder, error) {
+	for i, orderName := range rotationOrderNames {
+		if orderName == name {
+			return RotationOrder(i), nil
+		}
+	}
+	return ZXY, fmt.Errorf("unrecognized rotation order: '%s'", name)
+}
+
+// Valid determines whether or not the order is one of the orders defined.
+func (o RotationOrder) Valid() bool {
+	return int(o) < len(rotationOrderNames)
+}
+
+func (o RotationOrder) String() string {
+	if !o.Valid() {
+		return fmt.Sprintf("RotationOrder(%d)", int(o))
+	}
+	return rotationOrderNames[o]
+}
@@ -0,0 +1,180 @@
+package euler
+
+import (
+	"math"
+
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/EliCDavis/vector/vector4"
+)
+
+// Matrix is a rotation matrix in row major order, rotating column vectors.
+type Matrix [3][3]float64
+
+// gimbalLockThreshold is how close the cosine of the middle angle must come
+// to zero for the first and last axes to be considered aligned.
+const gimbalLockThreshold = 1e-12
+
+func axisMatrix(axis int, degrees float64) Matrix {
+	radians := degrees * math.Pi / 180
+	sin, cos := math.Sin(radians), math.Cos(radians)
+
+	next := (axis + 1) % 3
+	last := (axis + 2) % 3
+
+	var m Matrix
+	m[axis][axis] = 1
+	m[next][next] = cos
+	m[next][last] = -sin
+	m[last][next] = sin
+	m[last][last] = cos
+	return m
+}
+
+// Multiply combines two rotations into one, equivalent to rotating by b and
+// then by a.
+func (a Matrix) Multiply(b Matrix) Matrix {
+	var m Matrix
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			for i := 0; i < 3; i++ {
+				m[row][col] += a[row][i] * b[i][col]
+			}
+		}
+	}
+	return m
+}
+
+// Transpose flips the matrix along it's diagonal, which for a rotation matrix
+// is the rotation undoing it.
+func (a Matrix) Transpose() Matrix {
+	var m Matrix
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			m[row][col] = a[col][row]
+		}
+	}
+	return m
+}
+
+// Rotate applies the rotation to the vector provided.
+func (a Matrix) Rotate(v vector3.Float64) vector3.Float64 {
+	return vector3.New(
+		a[0][0]*v.X()+a[0][1]*v.Y()+a[0][2]*v.Z(),
+		a[1][0]*v.X()+a[1][1]*v.Y()+a[1][2]*v.Z(),
+		a[2][0]*v.X()+a[2][1]*v.Y()+a[2][2]*v.Z(),
+	)
+}
+
+// ToMatrix builds the rotation matrix of euler angles in degrees applied in
+// the order provided.
+func ToMatrix(angles vector3.Float64, order RotationOrder) Matrix {
+	values := [3]float64{angles.X(), angles.Y(), angles.Z()}
+	axes := rotationOrderAxes[order]
+
+	m := axisMatrix(axes[0], values[axes[0]])
+	m = axisMatrix(axes[1], values[axes[1]]).Multiply(m)
+	return axisMatrix(axes[2], values[axes[2]]).Multiply(m)
+}
+
+// FromMatrix finds euler angles in degrees that, applied in the order
+// provided, produce the rotation matrix. When the first and last axes end up
+// aligned, the rotation around the first axis is taken as zero.
+func FromMatrix(m Matrix, order RotationOrder) vector3.Float64 {
+	axes := rotationOrderAxes[order]
+	first, middle, last := axes[0], axes[1], axes[2]
+
+	// Orders that don't cycle through X, Y, Z from last to middle flip signs
+	sign := 1.0
+	if (last+1)%3 != middle {
+		sign = -1
+	}
+
+	var values [3]float64
+	cosMiddle := math.Hypot(m[first][first], m[middle][first])
+	values[middle] = math.Atan2(sign*m[last][first], cosMiddle)
+
+	if cosMiddle > gimbalLockThreshold {
+		values[last] = math.Atan2(-sign*m[middle][first], m[first][first])
+		values[first] = math.Atan2(-sign*m[last][middle], m[last][last])
+	} else {
+		values[last] = math.Atan2(math.Copysign(1, values[middle])*m[middle][last], m[middle][middle])
+	}
+
+	return vector3.New(values[0], values[1], values[2]).Scale(180 / math.Pi)
+}
+
+// ToQuaternion builds the quaternion of euler angles in degrees applied in
+// the order provided, with W being the scalar component.
+func ToQuaternion(angles vector3.Float64, order RotationOrder) vector4.Float64 {
+	return MatrixToQuaternion(ToMatrix(angles, order))
+}
+
+// FromQuaternion finds euler angles in degrees that, applied in the order
+// provided, produce the rotation of the quaternion.
+func FromQuaternion(q vector4.Float64, order RotationOrder) vector3.Float64 {
+	return FromMatrix(QuaternionToMatrix(q), order)
+}
+
+// Convert finds the euler angles in degrees that produce the same rotation
+// in the order to as the angles provided do in the order from.
+func Convert(angles vector3.Float64, from, to RotationOrder) vector3.Float64 {
+	if from == to {
+		return angles
+	}
+	return FromMatrix(ToMatrix(angles, from), to)
+}
+
+// QuaternionToMatrix builds the rotation matrix of a quaternion, normalizing
+// it first.
+func QuaternionToMatrix(q vector4.Float64) Matrix {
+	scale := 2 / q.Dot(q)
+	x, y, z, w := q.X(), q.Y(), q.Z(), q.W()
+
+	return Matrix{
+		{1 - scale*(y*y+z*z), scale * (x*y - z*w), scale * (x*z + y*w)},
+		{scale * (x*y + z*w), 1 - scale*(x*x+z*z), scale * (y*z - x*w)},
+		{scale * (x*z - y*w), scale * (y*z + x*w), 1 - scale*(x*x+y*y)},
+	}
+}
+
+// MatrixToQuaternion builds the normalized quaternion of a rotation matrix,
+// with W being the scalar component and kept positive.
+func MatrixToQuaternion(m Matrix) vector4.Float64 {
+	var x, y, z, w float64
+
+	trace := m[0][0] + m[1][1] + m[2][2]
+	switch {
+	case trace > 0:
+		s := math.Sqrt(trace+1) * 2
+		w = s / 4
+		x = (m[2][1] - m[1][2]) / s
+		y = (m[0][2] - m[2][0]) / s
+		z = (m[1][0] - m[0][1]) / s
+
+	case m[0][0] > m[1][1] && m[0][0] > m[2][2]:
+		s := math.Sqrt(1+m[0][0]-m[1][1]-m[2][2]) * 2
+		w = (m[2][1] - m[1][2]) / s
+		x = s / 4
+		y = (m[0][1] + m[1][0]) / s
+		z = (m[0][2] + m[2][0]) / s
+
+	case m[1][1] > m[2][2]:
+		s := math.Sqrt(1+m[1][1]-m[0][0]-m[2][2]) * 2
+		w = (m[0][2] - m[2][0]) / s
+		x = (m[0][1] + m[1][0]) / s
+		y = s / 4
+		z = (m[1][2] + m[2][1]) / s
+
+	default:
+		s := math.Sqrt(1+m[2][2]-m[0][0]-m[1][1]) * 2
+		w = (m[1][0] - m[0][1]) / s
+		x = (m[0][2] + m[2][0]) / s
+		y = (m[1][2] + m[2][1]) / s
+		z = s / 4
+	}
+
+	if w < 0 {
+		x, y, z, w = -x, -y, -z, -w
+	}
+	return vector4.New(x, y, z, w)
+}
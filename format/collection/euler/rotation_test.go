@@ -0,0 +1,209 @@
+package euler_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/recolude/rap/format/collection/euler"
+	"github.com/recolude/rap/format/collection/quaternion"
+	"github.com/stretchr/testify/assert"
+)
+
+var allOrders = []euler.RotationOrder{euler.ZXY, euler.XYZ, euler.XZY, euler.YXZ, euler.YZX, euler.ZYX}
+
+func assertMatricesMatch(t *testing.T, expected, actual euler.Matrix, msgAndArgs ...interface{}) bool {
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			if !assert.InDelta(t, expected[row][col], actual[row][col], 1e-9, msgAndArgs...) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func Test_ToMatrix_AppliesAxesInOrder(t *testing.T) {
+	// Rotating the X axis 90 degrees around Z and then 90 around X points it
+	// along Z, while the opposite order leaves it pointing along Y
+	xAxis := func(m euler.Matrix) vector3.Float64 {
+		return vector3.New(m[0][0], m[1][0], m[2][0])
+	}
+
+	zThenX := xAxis(euler.ToMatrix(vector3.New(90., 0, 90), euler.ZXY))
+	xThenZ := xAxis(euler.ToMatrix(vector3.New(90., 0, 90), euler.XYZ))
+
+	assert.InDelta(t, 1, zThenX.Z(), 1e-9)
+	assert.InDelta(t, 1, xThenZ.Y(), 1e-9)
+}
+
+func Test_ToMatrix_YawPitchRoll(t *testing.T) {
+	// ARRANGE ================================================================
+	roll, pitch, yaw := 20.0, -35.0, 110.0
+	rad := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	sr, cr := math.Sincos(rad(roll))
+	sp, cp := math.Sincos(rad(pitch))
+	sy, cy := math.Sincos(rad(yaw))
+
+	// Rz(yaw) * Ry(pitch) * Rx(roll), as found in robotics and aerospace
+	yawPitchRoll := euler.Matrix{
+		{cy * cp, cy*sp*sr - sy*cr, cy*sp*cr + sy*sr},
+		{sy * cp, sy*sp*sr + cy*cr, sy*sp*cr - cy*sr},
+		{-sp, cp * sr, cp * cr},
+	}
+
+	// Rx(roll) * Ry(pitch) * Rz(yaw)
+	rollPitchYaw := euler.Matrix{
+		{cp * cy, -cp * sy, sp},
+		{sr*sp*cy + cr*sy, -sr*sp*sy + cr*cy, -sr * cp},
+		{-cr*sp*cy + sr*sy, cr*sp*sy + sr*cy, cr * cp},
+	}
+
+	// ACT ====================================================================
+	xyz := euler.ToMatrix(vector3.New(roll, pitch, yaw), euler.XYZ)
+	zyx := euler.ToMatrix(vector3.New(roll, pitch, yaw), euler.ZYX)
+
+	// ASSERT =================================================================
+	assertMatricesMatch(t, yawPitchRoll, xyz)
+	assertMatricesMatch(t, rollPitchYaw, zyx)
+}
+
+func Test_Matrix_RoundTrip(t *testing.T) {
+	for _, order := range allOrders {
+		t.Run(order.String(), func(t *testing.T) {
+			for i := 0; i < 1000; i++ {
+				angles := vector3.New(rand.Float64()*360-180, rand.Float64()*180-90, rand.Float64()*360-180)
+
+				// ACT ========================================================
+				m := euler.ToMatrix(angles, order)
+				back := euler.ToMatrix(euler.FromMatrix(m, order), order)
+
+				// ASSERT =====================================================
+				if !assertMatricesMatch(t, m, back, "%v", angles) {
+					return
+				}
+			}
+		})
+	}
+}
+
+func Test_Matrix_TransposeUndoesRotation(t *testing.T) {
+	// ARRANGE ================================================================
+	m := euler.ToMatrix(vector3.New(30., -45, 120), euler.ZXY)
+	v := vector3.New(1., 2, 3)
+
+	// ACT ====================================================================
+	rotated := m.Rotate(v)
+	back := m.Transpose().Rotate(rotated)
+
+	// ASSERT =================================================================
+	assert.InDelta(t, v.Length(), rotated.Length(), 1e-9)
+	assert.InDelta(t, 0, v.Distance(back), 1e-9)
+	assertMatricesMatch(t, euler.ToMatrix(vector3.Zero[float64](), euler.ZXY), m.Transpose().Multiply(m))
+}
+
+func Test_Matrix_GimbalLock(t *testing.T) {
+	for _, order := range allOrders {
+		for _, middle := range []float64{90, -90} {
+			t.Run(order.String(), func(t *testing.T) {
+				// ARRANGE ====================================================
+				values := [3]float64{}
+				for axis := range values {
+					values[axis] = 30 + float64(axis)*10
+				}
+
+				// The middle axis of every order is the one gimbal locking
+				middleAxis := map[euler.RotationOrder]int{
+					euler.ZXY: 0, euler.XYZ: 1, euler.XZY: 2, euler.YXZ: 0, euler.YZX: 2, euler.ZYX: 1,
+				}[order]
+				values[middleAxis] = middle
+				angles := vector3.New(values[0], values[1], values[2])
+
+				// ACT ========================================================
+				m := euler.ToMatrix(angles, order)
+				back := euler.ToMatrix(euler.FromMatrix(m, order), order)
+
+				// ASSERT =====================================================
+				assertMatricesMatch(t, m, back, "%v", angles)
+			})
+		}
+	}
+}
+
+func Test_Quaternion_RoundTrip(t *testing.T) {
+	for _, order := range allOrders {
+		t.Run(order.String(), func(t *testing.T) {
+			for i := 0; i < 1000; i++ {
+				angles := vector3.New(rand.Float64()*720-360, rand.Float64()*720-360, rand.Float64()*720-360)
+
+				// ACT ========================================================
+				q := euler.ToQuaternion(angles, order)
+				back := euler.ToQuaternion(euler.FromQuaternion(q, order), order)
+
+				// ASSERT =====================================================
+				assert.InDelta(t, 1, q.Dot(q), 1e-9)
+				if !assert.Less(t, quaternion.Angle(q, back), 1e-5, "%v", angles) {
+					return
+				}
+			}
+		})
+	}
+}
+
+func Test_ToQuaternion_MatchesZXYCaptures(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		angles := vector3.New(rand.Float64()*360, rand.Float64()*360, rand.Float64()*360)
+
+		// ACT ================================================================
+		q := euler.ToQuaternion(angles, euler.ZXY)
+		expected := quaternion.NewEulerZXYCapture(0, angles.X(), angles.Y(), angles.Z()).Rotation()
+
+		// ASSERT =============================================================
+		assert.Less(t, quaternion.Angle(expected, q), 1e-5)
+	}
+}
+
+func Test_Convert(t *testing.T) {
+	// ARRANGE ================================================================
+	collection := euler.NewCollection("Rot", []euler.Capture{
+		euler.NewCapture(1, 10, 20, 30),
+		euler.NewCapture(2, -45, 80, 170),
+	})
+
+	for _, order := range allOrders {
+		t.Run(order.String(), func(t *testing.T) {
+			// ACT ============================================================
+			converted := collection.WithOrder(order)
+
+			// ASSERT =========================================================
+			assert.Equal(t, order, converted.Order())
+			assert.Equal(t, euler.ZXY, collection.Order())
+			for i, c := range converted.Captures() {
+				original := collection.Captures()[i].(euler.Capture)
+				assert.Equal(t, original.Time(), c.Time())
+				angle := quaternion.Angle(
+					euler.ToQuaternion(original.Angles(), euler.ZXY),
+					euler.ToQuaternion(c.(euler.Capture).Angles(), order),
+				)
+				assert.Less(t, angle, 1e-5)
+			}
+		})
+	}
+
+	assert.Equal(t, vector3.New(1., 2, 3), euler.Convert(vector3.New(1., 2, 3), euler.XYZ, euler.XYZ))
+	assert.InDelta(t, 45, euler.Convert(vector3.New(0., 0, 45), euler.XYZ, euler.ZYX).Z(), 1e-9)
+}
+
+func Test_ParseRotationOrder(t *testing.T) {
+	for _, order := range allOrders {
+		parsed, err := euler.ParseRotationOrder(order.String())
+		assert.NoError(t, err)
+		assert.Equal(t, order, parsed)
+	}
+
+	_, err := euler.ParseRotationOrder("XXY")
+	assert.EqualError(t, err, "unrecognized rotation order: 'XXY'")
+	assert.Equal(t, "RotationOrder(9)", euler.RotationOrder(9).String())
+	assert.False(t, euler.RotationOrder(6).Valid())
+}
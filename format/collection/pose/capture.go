@@ -0,0 +1,65 @@
+package pose
+
+import (
+	"fmt"
+
+	"github.com/EliCDavis/vector/vector3"
+)
+
+// Joint is the position and euler rotation in degrees of a joint, relative to
+// it's parent joint, or the world for the root.
+type Joint struct {
+	position vector3.Float64
+	rotation vector3.Float64
+}
+
+func NewJoint(position, rotation vector3.Float64) Joint {
+	return Joint{
+		position: position,
+		rotation: rotation,
+	}
+}
+
+func (j Joint) Position() vector3.Float64 {
+	return j.position
+}
+
+// Rotation are the rotations in degrees around each axis, applied in the
+// rotation order of the collection the joint's capture belongs to.
+func (j Joint) Rotation() vector3.Float64 {
+	return j.rotation
+}
+
+type Capture struct {
+	time   float64
+	joints []Joint
+}
+
+// NewCapture builds a capture of every joint of a skeleton, in the same order
+// the skeleton lists them.
+func NewCapture(time float64, joints []Joint) Capture {
+	return Capture{
+		time:   time,
+		joints: joints,
+	}
+}
+
+func (c Capture) Time() float64 {
+	return c.time
+}
+
+func (c Capture) Joints() []Joint {
+	return append([]Joint{}, c.joints...)
+}
+
+func (c Capture) Joint(index int) Joint {
+	return c.joints[index]
+}
+
+func (c Capture) JointCount() int {
+	return len(c.joints)
+}
+
+func (c Capture) String() string {
+	return fmt.Sprintf("[%.2f] Pose - %d joints", c.time, len(c.joints))
+}
@@ -0,0 +1,101 @@
+package pose
+
+import (
+	"fmt"
+
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/euler"
+)
+
+type Collection struct {
+	name     string
+	skeleton Skeleton
+	order    euler.RotationOrder
+	captures []Capture
+}
+
+// NewCollection builds a collection of poses of the skeleton provided, whose
+// joint rotations use the ZXY rotation order.
+func NewCollection(name string, skeleton Skeleton, captures []Capture) Collection {
+	return NewCollectionWithOrder(name, skeleton, euler.ZXY, captures)
+}
+
+// NewCollectionWithOrder builds a collection of poses of the skeleton
+// provided, whose joint rotations are applied in the rotation order provided.
+func NewCollectionWithOrder(name string, skeleton Skeleton, order euler.RotationOrder, captures []Capture) Collection {
+	return Collection{
+		name:     name,
+		skeleton: skeleton,
+		order:    order,
+		captures: captures,
+	}
+}
+
+func (s Collection) Name() string {
+	return s.name
+}
+
+func (s Collection) Skeleton() Skeleton {
+	return s.skeleton
+}
+
+// Order is the order the rotation of every joint is applied in.
+func (s Collection) Order() euler.RotationOrder {
+	return s.order
+}
+
+func (s Collection) Captures() []format.Capture {
+	returnVal := make([]format.Capture, len(s.captures))
+	for i := range s.captures {
+		returnVal[i] = s.captures[i]
+	}
+	return returnVal
+}
+
+func (Collection) Signature() string {
+	return "recolude.pose"
+}
+
+func (c Collection) Slice(beginning, end float64) format.CaptureCollection {
+	slicedCaptures := make([]Capture, 0)
+	for _, c := range c.captures {
+		if format.CaptureFallsWithin(c, beginning, end) {
+			slicedCaptures = append(slicedCaptures, c)
+		}
+	}
+	return NewCollectionWithOrder(c.Name(), c.skeleton, c.order, slicedCaptures)
+}
+
+func (c Collection) Start() float64 {
+	return c.captures[0].Time()
+}
+
+func (c Collection) End() float64 {
+	return c.captures[len(c.captures)-1].Time()
+}
+
+func (c Collection) Length() int {
+	return len(c.captures)
+}
+
+func (c Collection) CaptureAt(index int) format.Capture {
+	return c.captures[index]
+}
+
+// Validate checks every capture holds exactly one joint for every joint of
+// the collection's skeleton.
+func (c Collection) Validate() error {
+	for i, capture := range c.captures {
+		if capture.JointCount() != c.skeleton.JointCount() {
+			return fmt.Errorf(
+				"capture %d of pose collection %s has %d joints but skeleton %s has %d",
+				i,
+				c.name,
+				capture.JointCount(),
+				c.skeleton.Name(),
+				c.skeleton.JointCount(),
+			)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package pose
+
+import (
+	"fmt"
+
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/euler"
+	"github.com/recolude/rap/format/collection/position"
+	"github.com/recolude/rap/format/metadata"
+)
+
+// ToRecordings breaks the collection apart into a recording per joint, named
+// after the joint, each holding a "Position" and "Rotation" collection of the
+// joint's transform in world space.
+func (c Collection) ToRecordings() ([]format.Recording, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	positions := make([][]position.Capture, c.skeleton.JointCount())
+	rotations := make([][]euler.Capture, c.skeleton.JointCount())
+	for joint := range positions {
+		positions[joint] = make([]position.Capture, len(c.captures))
+		rotations[joint] = make([]euler.Capture, len(c.captures))
+	}
+
+	worldPositions := make([]vector3.Float64, c.skeleton.JointCount())
+	worldRotations := make([]euler.Matrix, c.skeleton.JointCount())
+	for i, capture := range c.captures {
+		for joint, local := range capture.joints {
+			angles := local.rotation
+			worldPositions[joint] = local.position
+			worldRotations[joint] = euler.ToMatrix(local.rotation, c.order)
+
+			if parent := c.skeleton.Parent(joint); parent != -1 {
+				worldPositions[joint] = worldPositions[parent].Add(worldRotations[parent].Rotate(local.position))
+				worldRotations[joint] = worldRotations[parent].Multiply(worldRotations[joint])
+				angles = euler.FromMatrix(worldRotations[joint], c.order)
+			}
+
+			positions[joint][i] = position.NewCapture(capture.time, worldPositions[joint].X(), worldPositions[joint].Y(), worldPositions[joint].Z())
+			rotations[joint][i] = euler.NewCapture(capture.time, angles.X(), angles.Y(), angles.Z())
+		}
+	}
+
+	recordings := make([]format.Recording, c.skeleton.JointCount())
+	for joint, name := range c.skeleton.joints {
+		recordings[joint] = format.NewRecording(
+			"",
+			name,
+			[]format.CaptureCollection{
+				position.NewCollection("Position", positions[joint]),
+				euler.NewCollectionWithOrder("Rotation", c.order, rotations[joint]),
+			},
+			nil,
+			metadata.EmptyBlock(),
+			nil,
+			nil,
+		)
+	}
+	return recordings, nil
+}
+
+// jointCollections finds the world space position and rotation collections
+// of a joint within the recording named after it.
+func jointCollections(joint string, recordings []format.Recording) (position.Collection, euler.Collection, error) {
+	for _, recording := range recordings {
+		if recording.Name() != joint {
+			continue
+		}
+
+		var positions *position.Collection
+		var rotations *euler.Collection
+		for _, collection := range recording.CaptureCollections() {
+			switch typed := collection.(type) {
+			case position.Collection:
+				if positions == nil {
+					positions = &typed
+				}
+			case euler.Collection:
+				if rotations == nil {
+					rotations = &typed
+				}
+			}
+		}
+
+		if positions == nil {
+			return position.Collection{}, euler.Collection{}, fmt.Errorf("recording for joint %s has no position collection", joint)
+		}
+
+		if rotations == nil {
+			return position.Collection{}, euler.Collection{}, fmt.Errorf("recording for joint %s has no euler collection", joint)
+		}
+
+		return *positions, *rotations, nil
+	}
+
+	return position.Collection{}, euler.Collection{}, fmt.Errorf("no recording found for joint %s", joint)
+}
+
+// FromRecordings builds a pose collection out of a recording per joint of the
+// skeleton, each named after it's joint and holding a position and euler
+// collection of the joint's transform in world space. Every joint must have
+// been captured at the same times, and the rotation order of the root joint's
+// collection is used for the pose.
+func FromRecordings(name string, skeleton Skeleton, recordings []format.Recording) (Collection, error) {
+	positions := make([]position.Collection, skeleton.JointCount())
+	rotations := make([]euler.Collection, skeleton.JointCount())
+	for joint, jointName := range skeleton.joints {
+		var err error
+		positions[joint], rotations[joint], err = jointCollections(jointName, recordings)
+		if err != nil {
+			return Collection{}, err
+		}
+	}
+
+	order := rotations[0].Order()
+	times := positions[0].Captures()
+	for joint, jointName := range skeleton.joints {
+		rotations[joint] = rotations[joint].WithOrder(order)
+
+		if positions[joint].Length() != len(times) || rotations[joint].Length() != len(times) {
+			return Collection{}, fmt.Errorf(
+				"joint %s has %d positions and %d rotations but the root joint has %d captures",
+				jointName,
+				positions[joint].Length(),
+				rotations[joint].Length(),
+				len(times),
+			)
+		}
+
+		for i, rootCapture := range times {
+			if positions[joint].CaptureAt(i).Time() != rootCapture.Time() || rotations[joint].CaptureAt(i).Time() != rootCapture.Time() {
+				return Collection{}, fmt.Errorf("capture %d of joint %s is not at %g like the root joint's", i, jointName, rootCapture.Time())
+			}
+		}
+	}
+
+	worldPositions := make([]vector3.Float64, skeleton.JointCount())
+	worldRotations := make([]euler.Matrix, skeleton.JointCount())
+	captures := make([]Capture, len(times))
+	for i, rootCapture := range times {
+		joints := make([]Joint, skeleton.JointCount())
+		for joint := range joints {
+			worldPositions[joint] = positions[joint].CaptureAt(i).(position.Capture).Position()
+			worldRotations[joint] = euler.ToMatrix(rotations[joint].CaptureAt(i).(euler.Capture).Angles(), order)
+
+			parent := skeleton.Parent(joint)
+			if parent == -1 {
+				joints[joint] = NewJoint(worldPositions[joint], rotations[joint].CaptureAt(i).(euler.Capture).Angles())
+				continue
+			}
+
+			inverseParent := worldRotations[parent].Transpose()
+			joints[joint] = NewJoint(
+				inverseParent.Rotate(worldPositions[joint].Sub(worldPositions[parent])),
+				euler.FromMatrix(inverseParent.Multiply(worldRotations[joint]), order),
+			)
+		}
+		captures[i] = NewCapture(rootCapture.Time(), joints)
+	}
+
+	return NewCollectionWithOrder(name, skeleton, order, captures), nil
+}
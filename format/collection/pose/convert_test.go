@@ -0,0 +1,143 @@
+package pose_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/euler"
+	"github.com/recolude/rap/format/collection/pose"
+	"github.com/recolude/rap/format/collection/position"
+	"github.com/recolude/rap/format/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildSkeleton(t *testing.T) pose.Skeleton {
+	skeleton, err := pose.NewSkeleton("Humanoid", []string{"Hips", "Spine", "Head", "Left Leg"}, []int{-1, 0, 1, 0})
+	assert.NoError(t, err)
+	return skeleton
+}
+
+// buildWalk sways every joint of the skeleton back and forth as it walks.
+func buildWalk(skeleton pose.Skeleton, n int) []pose.Capture {
+	offsets := []vector3.Float64{
+		vector3.New(0., 1., 0.),
+		vector3.New(0., 0.3, 0.),
+		vector3.New(0., 0.5, 0.1),
+		vector3.New(-0.1, -0.1, 0.),
+	}
+
+	captures := make([]pose.Capture, n)
+	for i := range captures {
+		time := float64(i) / 30
+		joints := make([]pose.Joint, skeleton.JointCount())
+		for j := range joints {
+			offset := offsets[j]
+			if j == 0 {
+				offset = offset.Add(vector3.New(time, 0, 0))
+			}
+			joints[j] = pose.NewJoint(offset, vector3.New(
+				math.Sin(time+float64(j))*30,
+				math.Cos(time*2+float64(j))*45,
+				float64(j)*10,
+			))
+		}
+		captures[i] = pose.NewCapture(time, joints)
+	}
+	return captures
+}
+
+func Test_ToRecordings(t *testing.T) {
+	// ARRANGE ================================================================
+	skeleton, err := pose.NewSkeleton("Arm", []string{"Shoulder", "Hand"}, []int{-1, 0})
+	assert.NoError(t, err)
+
+	collection := pose.NewCollection("Arm", skeleton, []pose.Capture{
+		pose.NewCapture(1, []pose.Joint{
+			pose.NewJoint(vector3.New(0., 2., 0.), vector3.New(0., 90., 0.)),
+			pose.NewJoint(vector3.New(1., 0., 0.), vector3.New(0., 0., 0.)),
+		}),
+	})
+
+	// ACT ====================================================================
+	recordings, err := collection.ToRecordings()
+
+	// ASSERT =================================================================
+	assert.NoError(t, err)
+	if assert.Len(t, recordings, 2) {
+		assert.Equal(t, "Shoulder", recordings[0].Name())
+		assert.Equal(t, "Hand", recordings[1].Name())
+		assert.Equal(t, "Position", recordings[1].CaptureCollections()[0].Name())
+		assert.Equal(t, "Rotation", recordings[1].CaptureCollections()[1].Name())
+
+		hand := recordings[1].CaptureCollections()[0].CaptureAt(0).(position.Capture).Position()
+		assert.InDelta(t, 0, hand.Distance(vector3.New(0., 2., -1.)), 1e-9)
+
+		handRotation := recordings[1].CaptureCollections()[1].CaptureAt(0).(euler.Capture).Angles()
+		assert.InDelta(t, 0, handRotation.Distance(vector3.New(0., 90., 0.)), 1e-9)
+	}
+}
+
+func Test_ToRecordings_FromRecordings(t *testing.T) {
+	// ARRANGE ================================================================
+	skeleton := buildSkeleton(t)
+	captures := buildWalk(skeleton, 100)
+	collection := pose.NewCollectionWithOrder("Walk", skeleton, euler.XYZ, captures)
+
+	// ACT ====================================================================
+	recordings, errSplit := collection.ToRecordings()
+	joined, errJoin := pose.FromRecordings("Walk", skeleton, recordings)
+
+	// ASSERT =================================================================
+	assert.NoError(t, errSplit)
+	assert.NoError(t, errJoin)
+	assert.Equal(t, euler.XYZ, joined.Order())
+	assert.True(t, skeleton.Equal(joined.Skeleton()))
+	if assert.Equal(t, len(captures), joined.Length()) {
+		for i, c := range joined.Captures() {
+			actual := c.(pose.Capture)
+			assert.Equal(t, captures[i].Time(), actual.Time())
+			for j, joint := range actual.Joints() {
+				expected := captures[i].Joint(j)
+				assert.InDelta(t, 0, expected.Position().Distance(joint.Position()), 1e-9)
+				assert.InDelta(t, 0, expected.Rotation().Distance(joint.Rotation()), 1e-6)
+			}
+		}
+	}
+}
+
+func Test_FromRecordings_Errors(t *testing.T) {
+	// ARRANGE ================================================================
+	skeleton := buildSkeleton(t)
+	recordings, err := pose.NewCollection("Walk", skeleton, buildWalk(skeleton, 10)).ToRecordings()
+	assert.NoError(t, err)
+
+	shortLeg := format.NewRecording("", "Left Leg", []format.CaptureCollection{
+		recordings[3].CaptureCollections()[0].Slice(0, 0.1),
+		recordings[3].CaptureCollections()[1],
+	}, nil, metadata.EmptyBlock(), nil, nil)
+
+	// ACT ====================================================================
+	_, errMissing := pose.FromRecordings("Walk", skeleton, recordings[:3])
+	_, errNoRotation := pose.FromRecordings("Walk", skeleton, append(recordings[:3:3], format.NewRecording("", "Left Leg", recordings[3].CaptureCollections()[:1], nil, metadata.EmptyBlock(), nil, nil)))
+	_, errLength := pose.FromRecordings("Walk", skeleton, append(recordings[:3:3], shortLeg))
+
+	// ASSERT =================================================================
+	assert.EqualError(t, errMissing, "no recording found for joint Left Leg")
+	assert.EqualError(t, errNoRotation, "recording for joint Left Leg has no euler collection")
+	assert.EqualError(t, errLength, "joint Left Leg has 3 positions and 10 rotations but the root joint has 10 captures")
+}
+
+func Test_ToRecordings_MissingJoints(t *testing.T) {
+	// ARRANGE ================================================================
+	collection := pose.NewCollection("Walk", buildSkeleton(t), []pose.Capture{
+		pose.NewCapture(1, []pose.Joint{pose.NewJoint(vector3.Zero[float64](), vector3.Zero[float64]())}),
+	})
+
+	// ACT ====================================================================
+	_, err := collection.ToRecordings()
+
+	// ASSERT =================================================================
+	assert.EqualError(t, err, "capture 0 of pose collection Walk has 1 joints but skeleton Humanoid has 4")
+}
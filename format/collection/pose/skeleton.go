@@ -0,0 +1,93 @@
+package pose
+
+import "fmt"
+
+// Skeleton names every joint of a pose and the joint each is attached to. The
+// first joint is the root, and every other joint comes after it's parent.
+type Skeleton struct {
+	name    string
+	joints  []string
+	parents []int
+}
+
+// NewSkeleton builds a skeleton out of joint names and the index of each
+// joint's parent, with the root joint coming first and having a parent of -1.
+func NewSkeleton(name string, joints []string, parents []int) (Skeleton, error) {
+	if len(joints) == 0 {
+		return Skeleton{}, fmt.Errorf("skeleton %s has no joints", name)
+	}
+
+	if len(joints) != len(parents) {
+		return Skeleton{}, fmt.Errorf("skeleton %s has %d joints but %d parents", name, len(joints), len(parents))
+	}
+
+	if parents[0] != -1 {
+		return Skeleton{}, fmt.Errorf("root joint %s of skeleton %s can not have a parent", joints[0], name)
+	}
+
+	seen := make(map[string]bool, len(joints))
+	for i, joint := range joints {
+		if seen[joint] {
+			return Skeleton{}, fmt.Errorf("skeleton %s has multiple joints named %s", name, joint)
+		}
+		seen[joint] = true
+
+		if i > 0 && (parents[i] < 0 || parents[i] >= i) {
+			return Skeleton{}, fmt.Errorf("joint %s of skeleton %s must come after it's parent", joint, name)
+		}
+	}
+
+	return Skeleton{
+		name:    name,
+		joints:  append([]string{}, joints...),
+		parents: append([]int{}, parents...),
+	}, nil
+}
+
+func (s Skeleton) Name() string {
+	return s.name
+}
+
+// Joints are the names of every joint, starting with the root.
+func (s Skeleton) Joints() []string {
+	return append([]string{}, s.joints...)
+}
+
+// Parents are the index of every joint's parent, -1 for the root.
+func (s Skeleton) Parents() []int {
+	return append([]int{}, s.parents...)
+}
+
+func (s Skeleton) JointCount() int {
+	return len(s.joints)
+}
+
+// Parent is the index of the joint's parent, -1 for the root.
+func (s Skeleton) Parent(joint int) int {
+	return s.parents[joint]
+}
+
+// JointIndex finds the index of the joint with the name provided, -1 if the
+// skeleton doesn't have one.
+func (s Skeleton) JointIndex(name string) int {
+	for i, joint := range s.joints {
+		if joint == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Equal is whether or not both skeletons share a name and joints.
+func (s Skeleton) Equal(other Skeleton) bool {
+	if s.name != other.name || len(s.joints) != len(other.joints) {
+		return false
+	}
+
+	for i := range s.joints {
+		if s.joints[i] != other.joints[i] || s.parents[i] != other.parents[i] {
+			return false
+		}
+	}
+	return true
+}
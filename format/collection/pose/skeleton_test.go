@@ -0,0 +1,48 @@
+package pose_test
+
+import (
+	"testing"
+
+	"github.com/recolude/rap/format/collection/pose"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewSkeleton(t *testing.T) {
+	// ACT ====================================================================
+	skeleton, err := pose.NewSkeleton("Humanoid", []string{"Hips", "Spine", "Head", "Left Leg"}, []int{-1, 0, 1, 0})
+
+	// ASSERT =================================================================
+	assert.NoError(t, err)
+	assert.Equal(t, "Humanoid", skeleton.Name())
+	assert.Equal(t, 4, skeleton.JointCount())
+	assert.Equal(t, []string{"Hips", "Spine", "Head", "Left Leg"}, skeleton.Joints())
+	assert.Equal(t, []int{-1, 0, 1, 0}, skeleton.Parents())
+	assert.Equal(t, 1, skeleton.Parent(2))
+	assert.Equal(t, 2, skeleton.JointIndex("Head"))
+	assert.Equal(t, -1, skeleton.JointIndex("Tail"))
+}
+
+func Test_NewSkeleton_Errors(t *testing.T) {
+	tests := map[string]struct {
+		joints  []string
+		parents []int
+		err     string
+	}{
+		"no joints":       {joints: nil, parents: nil, err: "skeleton Humanoid has no joints"},
+		"missing parents": {joints: []string{"Hips", "Spine"}, parents: []int{-1}, err: "skeleton Humanoid has 2 joints but 1 parents"},
+		"parented root":   {joints: []string{"Hips", "Spine"}, parents: []int{1, 0}, err: "root joint Hips of skeleton Humanoid can not have a parent"},
+		"second root":     {joints: []string{"Hips", "Spine"}, parents: []int{-1, -1}, err: "joint Spine of skeleton Humanoid must come after it's parent"},
+		"parent after":    {joints: []string{"Hips", "Spine", "Head"}, parents: []int{-1, 2, 0}, err: "joint Spine of skeleton Humanoid must come after it's parent"},
+		"duplicate":       {joints: []string{"Hips", "Hips"}, parents: []int{-1, 0}, err: "skeleton Humanoid has multiple joints named Hips"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// ACT ============================================================
+			_, err := pose.NewSkeleton("Humanoid", tc.joints, tc.parents)
+
+			// ASSERT =========================================================
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
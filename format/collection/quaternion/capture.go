@@ -0,0 +1,68 @@
+package quaternion
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/EliCDavis/vector/vector4"
+	"github.com/recolude/rap/format/collection/euler"
+)
+
+type Capture struct {
+	time     float64
+	rotation vector4.Float64
+}
+
+// NewCapture builds a capture of the rotation described by the quaternion
+// components provided, w being the scalar component.
+func NewCapture(time, x, y, z, w float64) Capture {
+	return Capture{
+		time:     time,
+		rotation: vector4.New(x, y, z, w),
+	}
+}
+
+// NewEulerCapture builds a capture of the rotation described by euler angles
+// in degrees applied in the order provided.
+func NewEulerCapture(time float64, angles vector3.Float64, order euler.RotationOrder) Capture {
+	return Capture{time: time, rotation: euler.ToQuaternion(angles, order)}
+}
+
+// NewEulerZXYCapture builds a capture of the rotation described by euler
+// angles in degrees, rotating around the Z axis first, then the X axis, and
+// then the Y axis, matching how euler collections are interpreted by
+// default.
+func NewEulerZXYCapture(time, eulerX, eulerY, eulerZ float64) Capture {
+	return NewEulerCapture(time, vector3.New(eulerX, eulerY, eulerZ), euler.ZXY)
+}
+
+// Multiply combines two rotations into one, equivalent to rotating by b and
+// then by a.
+func Multiply(a, b vector4.Float64) vector4.Float64 {
+	return vector4.New(
+		a.W()*b.X()+a.X()*b.W()+a.Y()*b.Z()-a.Z()*b.Y(),
+		a.W()*b.Y()-a.X()*b.Z()+a.Y()*b.W()+a.Z()*b.X(),
+		a.W()*b.Z()+a.X()*b.Y()-a.Y()*b.X()+a.Z()*b.W(),
+		a.W()*b.W()-a.X()*b.X()-a.Y()*b.Y()-a.Z()*b.Z(),
+	)
+}
+
+// Angle is the smallest angle in degrees needed to rotate from a to b.
+func Angle(a, b vector4.Float64) float64 {
+	dot := math.Abs(a.Dot(b)) / math.Sqrt(a.Dot(a)*b.Dot(b))
+	return math.Acos(math.Min(dot, 1)) * 360 / math.Pi
+}
+
+func (c Capture) Time() float64 {
+	return c.time
+}
+
+// Rotation is the quaternion captured, with W being the scalar component.
+func (c Capture) Rotation() vector4.Float64 {
+	return c.rotation
+}
+
+func (c Capture) String() string {
+	return fmt.Sprintf("[%.2f] Quaternion - %.2f, %.2f, %.2f, %.2f", c.time, c.rotation.X(), c.rotation.Y(), c.rotation.Z(), c.rotation.W())
+}
@@ -0,0 +1,44 @@
+package quaternion_test
+
+import (
+	"testing"
+
+	"github.com/recolude/rap/format/collection/quaternion"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewEulerZXYCapture(t *testing.T) {
+	tests := map[string]struct {
+		x, y, z    float64
+		qx, qy, qz float64
+		qw         float64
+	}{
+		"identity": {qw: 1},
+		"x":        {x: 90, qx: 0.7071068, qw: 0.7071068},
+		"y":        {y: 90, qy: 0.7071068, qw: 0.7071068},
+		"z":        {z: 90, qz: 0.7071068, qw: 0.7071068},
+		"zxy":      {x: 30, y: 60, z: 90, qx: 0.5, qy: 0.1830127, qz: 0.5, qw: 0.6830127},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// ACT ================================================================
+			capture := quaternion.NewEulerZXYCapture(1, tc.x, tc.y, tc.z)
+
+			// ASSERT =============================================================
+			assert.Equal(t, 1.0, capture.Time())
+			assert.InDelta(t, tc.qx, capture.Rotation().X(), 1e-6)
+			assert.InDelta(t, tc.qy, capture.Rotation().Y(), 1e-6)
+			assert.InDelta(t, tc.qz, capture.Rotation().Z(), 1e-6)
+			assert.InDelta(t, tc.qw, capture.Rotation().W(), 1e-6)
+		})
+	}
+}
+
+func Test_Angle(t *testing.T) {
+	identity := quaternion.NewCapture(0, 0, 0, 0, 1).Rotation()
+
+	assert.InDelta(t, 0, quaternion.Angle(identity, quaternion.NewCapture(0, 0, 0, 0, -1).Rotation()), 1e-9)
+	assert.InDelta(t, 90, quaternion.Angle(identity, quaternion.NewEulerZXYCapture(0, 0, 90, 0).Rotation()), 1e-6)
+	assert.InDelta(t, 90, quaternion.Angle(identity, quaternion.NewEulerZXYCapture(0, 0, -90, 0).Rotation()), 1e-6)
+}
@@ -0,0 +1,54 @@
+package transform
+
+import (
+	"fmt"
+
+	"github.com/EliCDavis/vector/vector3"
+)
+
+type Capture struct {
+	time     float64
+	position vector3.Float64
+	rotation vector3.Float64
+	scale    vector3.Float64
+}
+
+// NewCapture builds a capture of an object's position, euler rotation in
+// degrees, and scale, with the rotation interpreted in the rotation order of
+// the collection it's placed in.
+func NewCapture(time float64, position, rotation, scale vector3.Float64) Capture {
+	return Capture{
+		time:     time,
+		position: position,
+		rotation: rotation,
+		scale:    scale,
+	}
+}
+
+func (c Capture) Time() float64 {
+	return c.time
+}
+
+func (c Capture) Position() vector3.Float64 {
+	return c.position
+}
+
+// Rotation are the rotations in degrees around each axis, applied in the
+// rotation order of the collection the capture belongs to.
+func (c Capture) Rotation() vector3.Float64 {
+	return c.rotation
+}
+
+func (c Capture) Scale() vector3.Float64 {
+	return c.scale
+}
+
+func (c Capture) String() string {
+	return fmt.Sprintf(
+		"[%.2f] Transform - (%.2f, %.2f, %.2f), (%.2f, %.2f, %.2f), (%.2f, %.2f, %.2f)",
+		c.time,
+		c.position.X(), c.position.Y(), c.position.Z(),
+		c.rotation.X(), c.rotation.Y(), c.rotation.Z(),
+		c.scale.X(), c.scale.Y(), c.scale.Z(),
+	)
+}
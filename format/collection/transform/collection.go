@@ -0,0 +1,75 @@
+package transform
+
+import (
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/euler"
+)
+
+type Collection struct {
+	name     string
+	order    euler.RotationOrder
+	captures []Capture
+}
+
+// NewCollection builds a collection of captures whose rotations use the ZXY
+// rotation order.
+func NewCollection(name string, captures []Capture) Collection {
+	return NewCollectionWithOrder(name, euler.ZXY, captures)
+}
+
+// NewCollectionWithOrder builds a collection of captures whose rotations are
+// applied in the rotation order provided.
+func NewCollectionWithOrder(name string, order euler.RotationOrder, captures []Capture) Collection {
+	return Collection{
+		name:     name,
+		order:    order,
+		captures: captures,
+	}
+}
+
+func (s Collection) Name() string {
+	return s.name
+}
+
+// Order is the order the rotation of every capture is applied in.
+func (s Collection) Order() euler.RotationOrder {
+	return s.order
+}
+
+func (s Collection) Captures() []format.Capture {
+	returnVal := make([]format.Capture, len(s.captures))
+	for i := range s.captures {
+		returnVal[i] = s.captures[i]
+	}
+	return returnVal
+}
+
+func (Collection) Signature() string {
+	return "recolude.transform"
+}
+
+func (c Collection) Slice(beginning, end float64) format.CaptureCollection {
+	slicedCaptures := make([]Capture, 0)
+	for _, c := range c.captures {
+		if format.CaptureFallsWithin(c, beginning, end) {
+			slicedCaptures = append(slicedCaptures, c)
+		}
+	}
+	return NewCollectionWithOrder(c.Name(), c.order, slicedCaptures)
+}
+
+func (c Collection) Start() float64 {
+	return c.captures[0].Time()
+}
+
+func (c Collection) End() float64 {
+	return c.captures[len(c.captures)-1].Time()
+}
+
+func (c Collection) Length() int {
+	return len(c.captures)
+}
+
+func (c Collection) CaptureAt(index int) format.Capture {
+	return c.captures[index]
+}
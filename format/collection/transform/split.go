@@ -0,0 +1,63 @@
+package transform
+
+import (
+	"fmt"
+
+	"github.com/recolude/rap/format/collection/euler"
+	"github.com/recolude/rap/format/collection/position"
+)
+
+// Split breaks the collection apart into the separate position, euler and
+// scale collections transforms were recorded as before, with scale being
+// stored as a position collection.
+func (s Collection) Split(positionName, rotationName, scaleName string) (position.Collection, euler.Collection, position.Collection) {
+	positions := make([]position.Capture, len(s.captures))
+	rotations := make([]euler.Capture, len(s.captures))
+	scales := make([]position.Capture, len(s.captures))
+
+	for i, c := range s.captures {
+		positions[i] = position.NewCapture(c.time, c.position.X(), c.position.Y(), c.position.Z())
+		rotations[i] = euler.NewCapture(c.time, c.rotation.X(), c.rotation.Y(), c.rotation.Z())
+		scales[i] = position.NewCapture(c.time, c.scale.X(), c.scale.Y(), c.scale.Z())
+	}
+
+	return position.NewCollection(positionName, positions),
+		euler.NewCollectionWithOrder(rotationName, s.order, rotations),
+		position.NewCollection(scaleName, scales)
+}
+
+// Join builds a transform collection out of separate position, euler and
+// scale collections, which must have been captured at the same times.
+func Join(name string, positions position.Collection, rotations euler.Collection, scales position.Collection) (Collection, error) {
+	if positions.Length() != rotations.Length() || positions.Length() != scales.Length() {
+		return Collection{}, fmt.Errorf(
+			"can not join %d positions, %d rotations and %d scales into transform collection %s",
+			positions.Length(),
+			rotations.Length(),
+			scales.Length(),
+			name,
+		)
+	}
+
+	captures := make([]Capture, positions.Length())
+	for i := range captures {
+		p := positions.CaptureAt(i).(position.Capture)
+		r := rotations.CaptureAt(i).(euler.Capture)
+		s := scales.CaptureAt(i).(position.Capture)
+
+		if r.Time() != p.Time() || s.Time() != p.Time() {
+			return Collection{}, fmt.Errorf(
+				"capture %d of transform collection %s has a position at %g, rotation at %g and scale at %g",
+				i,
+				name,
+				p.Time(),
+				r.Time(),
+				s.Time(),
+			)
+		}
+
+		captures[i] = NewCapture(p.Time(), p.Position(), r.Angles(), s.Position())
+	}
+
+	return NewCollectionWithOrder(name, rotations.Order(), captures), nil
+}
@@ -0,0 +1,59 @@
+package transform_test
+
+import (
+	"testing"
+
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/recolude/rap/format/collection/euler"
+	"github.com/recolude/rap/format/collection/position"
+	"github.com/recolude/rap/format/collection/transform"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SplitJoin(t *testing.T) {
+	// ARRANGE ================================================================
+	collection := transform.NewCollectionWithOrder("Body", euler.YXZ, []transform.Capture{
+		transform.NewCapture(1, vector3.New(1., 2., 3.), vector3.New(4., 5., 6.), vector3.New(7., 8., 9.)),
+		transform.NewCapture(2, vector3.New(10., 11., 12.), vector3.New(13., 14., 15.), vector3.New(16., 17., 18.)),
+	})
+
+	// ACT ====================================================================
+	positions, rotations, scales := collection.Split("Position", "Rotation", "Scale")
+	joined, err := transform.Join("Body", positions, rotations, scales)
+
+	// ASSERT =================================================================
+	assert.Equal(t, "Position", positions.Name())
+	assert.Equal(t, "Rotation", rotations.Name())
+	assert.Equal(t, "Scale", scales.Name())
+	assert.Equal(t, euler.YXZ, rotations.Order())
+	assert.Equal(t, position.NewCapture(2, 10, 11, 12), positions.CaptureAt(1))
+	assert.Equal(t, euler.NewCapture(2, 13, 14, 15), rotations.CaptureAt(1))
+	assert.Equal(t, position.NewCapture(2, 16, 17, 18), scales.CaptureAt(1))
+
+	assert.NoError(t, err)
+	assert.Equal(t, collection, joined)
+}
+
+func Test_Join_Errors(t *testing.T) {
+	// ARRANGE ================================================================
+	positions := position.NewCollection("Position", []position.Capture{
+		position.NewCapture(1, 0, 0, 0),
+		position.NewCapture(2, 0, 0, 0),
+	})
+	rotations := euler.NewCollection("Rotation", []euler.Capture{
+		euler.NewCapture(1, 0, 0, 0),
+		euler.NewCapture(2, 0, 0, 0),
+	})
+	scales := position.NewCollection("Scale", []position.Capture{
+		position.NewCapture(1, 1, 1, 1),
+		position.NewCapture(2.5, 1, 1, 1),
+	})
+
+	// ACT ====================================================================
+	_, errLength := transform.Join("Body", positions, rotations, position.NewCollection("Scale", nil))
+	_, errTime := transform.Join("Body", positions, rotations, scales)
+
+	// ASSERT =================================================================
+	assert.EqualError(t, errLength, "can not join 2 positions, 2 rotations and 0 scales into transform collection Body")
+	assert.EqualError(t, errTime, "capture 1 of transform collection Body has a position at 2, rotation at 2 and scale at 2.5")
+}
@@ -0,0 +1,31 @@
+package vector2
+
+import (
+	"fmt"
+
+	"github.com/EliCDavis/vector/vector2"
+)
+
+type Capture struct {
+	time  float64
+	value vector2.Float64
+}
+
+func NewCapture(time, x, y float64) Capture {
+	return Capture{
+		time:  time,
+		value: vector2.New(x, y),
+	}
+}
+
+func (c Capture) Time() float64 {
+	return c.time
+}
+
+func (c Capture) Value() vector2.Float64 {
+	return c.value
+}
+
+func (c Capture) String() string {
+	return fmt.Sprintf("[%.2f] Vector2 - %.2f, %.2f", c.time, c.value.X(), c.value.Y())
+}
@@ -0,0 +1,26 @@
+package encoding
+
+import (
+	"github.com/recolude/rap/format/encoding/enum"
+	"github.com/recolude/rap/format/encoding/euler"
+	"github.com/recolude/rap/format/encoding/event"
+	"github.com/recolude/rap/format/encoding/float"
+	"github.com/recolude/rap/format/encoding/pose"
+	"github.com/recolude/rap/format/encoding/position"
+	"github.com/recolude/rap/format/encoding/quaternion"
+	"github.com/recolude/rap/format/encoding/transform"
+	"github.com/recolude/rap/format/encoding/vector2"
+)
+
+// Every built in encoder is registered by default
+func init() {
+	Register(event.NewEncoder())
+	Register(position.NewEncoder(position.Oct48))
+	Register(euler.NewEncoder(euler.Raw32))
+	Register(enum.NewEncoder())
+	Register(float.NewEncoder(float.Raw32))
+	Register(quaternion.NewEncoder(quaternion.SmallestThree48))
+	Register(vector2.NewEncoder(vector2.Quad32))
+	Register(transform.NewEncoder(position.Oct48, euler.Raw32, position.Oct48))
+	Register(pose.NewEncoder(pose.Quantized))
+}
@@ -9,3 +9,13 @@ type Encoder interface {
 	Version() uint
 	Signature() string
 }
+
+// CollectionEncoder is implemented by encoders that encode every collection
+// independently of one another without a header, allowing collections to be
+// encoded concurrently. EncodeCollection must require nothing from any other
+// collection being encoded, be safe to call from multiple goroutines at once,
+// and produce the same bytes Encode would have produced for the collection.
+type CollectionEncoder interface {
+	Encoder
+	EncodeCollection(format.CaptureCollection) ([]byte, error)
+}
@@ -0,0 +1,61 @@
+package euler
+
+import (
+	"math"
+
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/euler"
+	"github.com/recolude/rap/format/encoding/internal/auto"
+)
+
+// Selection describes the technique an auto encoder picked for a collection.
+type Selection struct {
+	Collection string
+	Technique  StorageTechnique
+
+	// MaxError is the largest difference in degrees between any of a
+	// capture's original angles and the angle decoded back out
+	MaxError float64
+}
+
+// autoSelector measures error as the largest difference between any of the
+// three angles, with Raw64 as the lossless fallback.
+var autoSelector = auto.Selector[StorageTechnique]{
+	Techniques: []StorageTechnique{Raw64, Raw32, Raw16},
+	Encode: func(technique StorageTechnique, collection format.CaptureCollection) ([]byte, error) {
+		return encodeWith(technique, collection), nil
+	},
+	Decode: decode,
+	Error: func(original, decoded format.Capture) float64 {
+		a := original.(euler.Capture).Angles()
+		b := decoded.(euler.Capture).Angles()
+		return math.Max(angleDifference(a.X(), b.X()), math.Max(angleDifference(a.Y(), b.Y()), angleDifference(a.Z(), b.Z())))
+	},
+}
+
+// NewAutoEncoder builds an encoder that picks the technique producing the
+// fewest bytes for every collection, while keeping every angle within
+// maxError degrees of it's original. When not nil, report is called with
+// what was picked for every collection, possibly from multiple goroutines at
+// once.
+func NewAutoEncoder(maxError float64, report func(Selection)) Encoder {
+	return Encoder{auto: true, maxError: maxError, report: auto.NewReporter(report)}
+}
+
+// Select picks the technique producing the fewest bytes for the collection
+// while keeping every angle within maxError degrees of it's original.
+func Select(collection format.CaptureCollection, maxError float64) (Selection, error) {
+	selection, _, err := selectTechnique(collection, maxError)
+	return selection, err
+}
+
+// angleDifference is the smallest difference between two angles in degrees,
+// as angles are wrapped when stored.
+func angleDifference(a, b float64) float64 {
+	return math.Abs(wrapEulerAngle(a-b+180) - 180)
+}
+
+func selectTechnique(collection format.CaptureCollection, maxError float64) (Selection, []byte, error) {
+	technique, encoded, measured, err := autoSelector.Select(collection, maxError)
+	return Selection{Collection: collection.Name(), Technique: technique, MaxError: measured}, encoded, err
+}
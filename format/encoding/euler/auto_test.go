@@ -0,0 +1,70 @@
+package euler_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/recolude/rap/format"
+	eulerCollection "github.com/recolude/rap/format/collection/euler"
+	"github.com/recolude/rap/format/encoding/euler"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Auto_PicksSmallestWithinBudget(t *testing.T) {
+	captures := make([]eulerCollection.Capture, 200)
+	times := make([]float64, len(captures))
+	for i := range captures {
+		times[i] = float64(i) / 10
+		captures[i] = eulerCollection.NewEulerZXYCapture(times[i], rand.Float64()*360, rand.Float64()*360, rand.Float64()*360)
+	}
+	collectionIn := eulerCollection.NewCollection("Head", captures)
+
+	tests := map[string]struct {
+		maxError float64
+		expected euler.StorageTechnique
+	}{
+		"lossless":        {maxError: 0, expected: euler.Raw64},
+		"thousandth":      {maxError: 0.001, expected: euler.Raw32},
+		"tenth of degree": {maxError: 0.1, expected: euler.Raw16},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// ARRANGE ========================================================
+			var reported euler.Selection
+			encoder := euler.NewAutoEncoder(tc.maxError, func(selection euler.Selection) {
+				reported = selection
+			})
+
+			// ACT ============================================================
+			selection, errSelect := euler.Select(collectionIn, tc.maxError)
+			header, streamsData, errEncode := encoder.Encode([]format.CaptureCollection{collectionIn})
+			collectionOut, errDecode := encoder.Decode("Head", header, streamsData[0], times)
+
+			// ASSERT =========================================================
+			assert.NoError(t, errSelect)
+			assert.NoError(t, errEncode)
+			assert.NoError(t, errDecode)
+			assert.Equal(t, tc.expected, selection.Technique)
+			assert.Equal(t, selection, reported)
+			assert.LessOrEqual(t, selection.MaxError, tc.maxError)
+			assert.Len(t, collectionOut.Captures(), len(captures))
+		})
+	}
+}
+
+func Test_Auto_MeasuresWrappedAngles(t *testing.T) {
+	// ARRANGE ================================================================
+	collectionIn := eulerCollection.NewCollection("Head", []eulerCollection.Capture{
+		eulerCollection.NewEulerZXYCapture(0, -90, 720, 359.99),
+		eulerCollection.NewEulerZXYCapture(1, 45, -0.01, 10),
+	})
+
+	// ACT ====================================================================
+	selection, err := euler.Select(collectionIn, 0.1)
+
+	// ASSERT =================================================================
+	assert.NoError(t, err)
+	assert.Equal(t, euler.Raw16, selection.Technique)
+	assert.Less(t, selection.MaxError, 0.1)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/recolude/rap/format"
 	"github.com/recolude/rap/format/collection/euler"
+	"github.com/recolude/rap/format/encoding/internal/auto"
 )
 
 type StorageTechnique int
@@ -24,15 +25,56 @@ const (
 	Raw16
 )
 
+// orderFlag is set on the technique byte of collections not using the ZXY
+// rotation order, which is then followed by a byte holding the order.
+// Collections using ZXY are written exactly as they were before rotation
+// orders existed.
+const orderFlag = 0x80
+
+// order is the rotation order of a collection, being ZXY for collections that
+// don't carry one.
+func order(stream format.CaptureCollection) euler.RotationOrder {
+	if ordered, ok := stream.(interface{ Order() euler.RotationOrder }); ok {
+		return ordered.Order()
+	}
+	return euler.ZXY
+}
+
 type Encoder struct {
 	technique StorageTechnique
+
+	// auto has the technique picked per collection instead, see
+	// NewAutoEncoder
+	auto     bool
+	maxError float64
+	report   auto.Reporter[Selection]
 }
 
 func NewEncoder(technique StorageTechnique) Encoder {
 	return Encoder{technique: technique}
 }
 
-func (p Encoder) encode(stream format.CaptureCollection) ([]byte, error) {
+// EncodeCollection refuses collections with an invalid rotation order.
+func (p Encoder) EncodeCollection(stream format.CaptureCollection) ([]byte, error) {
+	if !order(stream).Valid() {
+		return nil, fmt.Errorf("collection %s has an invalid rotation order: %s", stream.Name(), order(stream))
+	}
+
+	if !p.auto {
+		return encodeWith(p.technique, stream), nil
+	}
+
+	selection, encoded, err := selectTechnique(stream, p.maxError)
+	if err != nil {
+		return nil, err
+	}
+
+	p.report.Report(selection)
+	return encoded, nil
+}
+
+// encodeWith encodes a collection with the technique provided.
+func encodeWith(technique StorageTechnique, stream format.CaptureCollection) []byte {
 	streamData := new(bytes.Buffer)
 
 	castedCaptureData := make([]euler.Capture, len(stream.Captures()))
@@ -40,9 +82,14 @@ func (p Encoder) encode(stream format.CaptureCollection) ([]byte, error) {
 		castedCaptureData[i] = c.(euler.Capture)
 	}
 
-	streamData.WriteByte(byte(p.technique))
+	if rotationOrder := order(stream); rotationOrder != euler.ZXY {
+		streamData.WriteByte(byte(technique) | orderFlag)
+		streamData.WriteByte(byte(rotationOrder))
+	} else {
+		streamData.WriteByte(byte(technique))
+	}
 
-	switch p.technique {
+	switch technique {
 	case Raw64:
 		streamData.Write(encodeRaw64(castedCaptureData))
 		break
@@ -54,14 +101,14 @@ func (p Encoder) encode(stream format.CaptureCollection) ([]byte, error) {
 		break
 	}
 
-	return streamData.Bytes(), nil
+	return streamData.Bytes()
 }
 
 func (p Encoder) Encode(streams []format.CaptureCollection) ([]byte, [][]byte, error) {
 	allStreamData := make([][]byte, len(streams))
 
 	for i, stream := range streams {
-		s, err := p.encode(stream)
+		s, err := p.EncodeCollection(stream)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -79,32 +126,41 @@ func decode(name string, data []byte, times []float64) (format.CaptureCollection
 		return nil, err
 	}
 
+	rotationOrder := euler.ZXY
+	if typeByte&orderFlag != 0 {
+		orderByte, err := reader.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		rotationOrder = euler.RotationOrder(orderByte)
+		if !rotationOrder.Valid() {
+			return nil, fmt.Errorf("Unknown euler rotation order: %d", int(orderByte))
+		}
+		typeByte &^= orderFlag
+	}
+
 	encodingTechnique := StorageTechnique(typeByte)
 
+	var captures []euler.Capture
 	switch encodingTechnique {
 	case Raw64:
-		captures, err := decodeRaw64(reader, times)
-		if err != nil {
-			return nil, err
-		}
-		return euler.NewCollection(name, captures), nil
+		captures, err = decodeRaw64(reader, times)
 
 	case Raw32:
-		captures, err := decodeRaw32(reader, times)
-		if err != nil {
-			return nil, err
-		}
-		return euler.NewCollection(name, captures), nil
+		captures, err = decodeRaw32(reader, times)
 
 	case Raw16:
-		captures, err := decodeRaw16(reader, times)
-		if err != nil {
-			return nil, err
-		}
-		return euler.NewCollection(name, captures), nil
+		captures, err = decodeRaw16(reader, times)
+
+	default:
+		return nil, fmt.Errorf("Unknown euler encoding technique: %d", int(encodingTechnique))
 	}
 
-	return nil, fmt.Errorf("Unknown euler encoding technique: %d", int(encodingTechnique))
+	if err != nil {
+		return nil, err
+	}
+	return euler.NewCollectionWithOrder(name, rotationOrder, captures), nil
 }
 
 func (p Encoder) Decode(name string, header []byte, streamData []byte, times []float64) (format.CaptureCollection, error) {
@@ -119,6 +175,7 @@ func (p Encoder) Signature() string {
 	return "recolude.euler"
 }
 
+// Version 1 added rotation orders, flagged on the technique byte.
 func (p Encoder) Version() uint {
-	return 0
+	return 1
 }
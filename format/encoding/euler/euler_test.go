@@ -1,13 +1,17 @@
 package euler_test
 
 import (
+	"bytes"
 	"fmt"
 	"math/rand"
 	"testing"
 
 	"github.com/recolude/rap/format"
 	eulerCollection "github.com/recolude/rap/format/collection/euler"
+	"github.com/recolude/rap/format/encoding"
 	"github.com/recolude/rap/format/encoding/euler"
+	"github.com/recolude/rap/format/io"
+	"github.com/recolude/rap/format/metadata"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -224,3 +228,92 @@ func Test_EulerRaw16Wrapping(t *testing.T) {
 		}
 	}
 }
+
+func Test_EulerRotationOrders(t *testing.T) {
+	captures := []eulerCollection.Capture{
+		eulerCollection.NewCapture(1, 10, 20, 30),
+		eulerCollection.NewCapture(2, 40, 50, 60),
+	}
+
+	orders := []eulerCollection.RotationOrder{
+		eulerCollection.ZXY,
+		eulerCollection.XYZ,
+		eulerCollection.XZY,
+		eulerCollection.YXZ,
+		eulerCollection.YZX,
+		eulerCollection.ZYX,
+	}
+
+	for _, order := range orders {
+		for _, technique := range []euler.StorageTechnique{euler.Raw64, euler.Raw32, euler.Raw16} {
+			t.Run(fmt.Sprintf("%s/%d", order, technique), func(t *testing.T) {
+				// ARRANGE ========================================================
+				streamIn := eulerCollection.NewCollectionWithOrder("Rot", order, captures)
+				encoder := euler.NewEncoder(technique)
+
+				// ACT ============================================================
+				data, encodeErr := encoder.EncodeCollection(streamIn)
+				streamOut, decodeErr := encoder.Decode("Rot", nil, data, []float64{1, 2})
+
+				// ASSERT =========================================================
+				assert.NoError(t, encodeErr)
+				assert.NoError(t, decodeErr)
+				if assert.IsType(t, eulerCollection.Collection{}, streamOut) {
+					assert.Equal(t, order, streamOut.(eulerCollection.Collection).Order())
+				}
+				if assert.Len(t, streamOut.Captures(), 2) {
+					assert.InDelta(t, 40, streamOut.Captures()[1].(eulerCollection.Capture).Angles().X(), 0.005)
+				}
+
+				// ZXY collections are written as they were before orders existed
+				if order == eulerCollection.ZXY {
+					assert.Equal(t, byte(technique), data[0])
+				}
+			})
+		}
+	}
+}
+
+func Test_EulerRotationOrders_Invalid(t *testing.T) {
+	// ARRANGE ================================================================
+	encoder := euler.NewEncoder(euler.Raw32)
+
+	// ACT ====================================================================
+	_, errEncode := encoder.EncodeCollection(eulerCollection.NewCollectionWithOrder("Rot", eulerCollection.RotationOrder(42), nil))
+	_, errDecode := encoder.Decode("Rot", nil, []byte{0x81, 42}, nil)
+	_, errTruncated := encoder.Decode("Rot", nil, []byte{0x81}, nil)
+
+	// ASSERT =================================================================
+	assert.EqualError(t, errEncode, "collection Rot has an invalid rotation order: RotationOrder(42)")
+	assert.EqualError(t, errDecode, "Unknown euler rotation order: 42")
+	assert.Error(t, errTruncated)
+}
+
+// unorderedEncoder stands in for an euler encoder from before rotation orders
+// existed.
+type unorderedEncoder struct {
+	euler.Encoder
+}
+
+func (unorderedEncoder) Version() uint {
+	return 0
+}
+
+func Test_EulerRotationOrders_OlderReaders(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	collection := eulerCollection.NewCollectionWithOrder("Rot", eulerCollection.XYZ, []eulerCollection.Capture{
+		eulerCollection.NewCapture(1, 10, 20, 30),
+	})
+	_, errWrite := io.NewWriter([]encoding.Encoder{euler.NewEncoder(euler.Raw32)}, false, fileData, io.Raw64).Write(
+		format.NewRecording("", "Rotations", []format.CaptureCollection{collection}, nil, metadata.EmptyBlock(), nil, nil),
+	)
+
+	// ACT ====================================================================
+	rec, _, errRead := io.NewReader([]encoding.Encoder{unorderedEncoder{euler.NewEncoder(euler.Raw32)}}, fileData).Read()
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	assert.Nil(t, rec)
+	assert.ErrorIs(t, errRead, io.ErrEncoderTooOld)
+}
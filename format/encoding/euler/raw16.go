@@ -21,21 +21,21 @@ func encodeRaw16(captures []euler.Capture) []byte {
 	}
 
 	if len(captures) == 1 {
-		binary.Write(streamData, binary.LittleEndian, float32(wrapEulerAngle(captures[0].EulerZXY().X())))
-		binary.Write(streamData, binary.LittleEndian, float32(wrapEulerAngle(captures[0].EulerZXY().Y())))
-		binary.Write(streamData, binary.LittleEndian, float32(wrapEulerAngle(captures[0].EulerZXY().Z())))
+		binary.Write(streamData, binary.LittleEndian, float32(wrapEulerAngle(captures[0].Angles().X())))
+		binary.Write(streamData, binary.LittleEndian, float32(wrapEulerAngle(captures[0].Angles().Y())))
+		binary.Write(streamData, binary.LittleEndian, float32(wrapEulerAngle(captures[0].Angles().Z())))
 		return streamData.Bytes()
 	}
 
 	buffer2Byes := make([]byte, 2)
 	for _, capture := range captures {
-		binaryutil.UnsignedFloatBSTToBytes(wrapEulerAngle(capture.EulerZXY().X()), 0, 360, buffer2Byes)
+		binaryutil.UnsignedFloatBSTToBytes(wrapEulerAngle(capture.Angles().X()), 0, 360, buffer2Byes)
 		streamData.Write(buffer2Byes)
 
-		binaryutil.UnsignedFloatBSTToBytes(wrapEulerAngle(capture.EulerZXY().Y()), 0, 360, buffer2Byes)
+		binaryutil.UnsignedFloatBSTToBytes(wrapEulerAngle(capture.Angles().Y()), 0, 360, buffer2Byes)
 		streamData.Write(buffer2Byes)
 
-		binaryutil.UnsignedFloatBSTToBytes(wrapEulerAngle(capture.EulerZXY().Z()), 0, 360, buffer2Byes)
+		binaryutil.UnsignedFloatBSTToBytes(wrapEulerAngle(capture.Angles().Z()), 0, 360, buffer2Byes)
 		streamData.Write(buffer2Byes)
 	}
 	return streamData.Bytes()
@@ -51,7 +51,7 @@ func decodeRaw16(streamData *bytes.Reader, times []float64) ([]euler.Capture, er
 		err := binary.Read(streamData, binary.LittleEndian, &posX)
 		err = binary.Read(streamData, binary.LittleEndian, &posY)
 		err = binary.Read(streamData, binary.LittleEndian, &posZ)
-		return []euler.Capture{euler.NewEulerZXYCapture(times[0], float64(posX), float64(posY), float64(posZ))}, err
+		return []euler.Capture{euler.NewCapture(times[0], float64(posX), float64(posY), float64(posZ))}, err
 	}
 
 	captures := make([]euler.Capture, len(times))
@@ -64,7 +64,7 @@ func decodeRaw16(streamData *bytes.Reader, times []float64) ([]euler.Capture, er
 		y := binaryutil.BytesToUnisngedFloatBST(0, 360, buffer)
 		streamData.Read(buffer)
 		z := binaryutil.BytesToUnisngedFloatBST(0, 360, buffer)
-		captures[i] = euler.NewEulerZXYCapture(times[i], float64(x), float64(y), float64(z))
+		captures[i] = euler.NewCapture(times[i], float64(x), float64(y), float64(z))
 	}
 
 	return captures, nil
@@ -10,9 +10,9 @@ import (
 func encodeRaw32(captures []euler.Capture) []byte {
 	streamData := new(bytes.Buffer)
 	for _, capture := range captures {
-		binary.Write(streamData, binary.LittleEndian, float32(capture.EulerZXY().X()))
-		binary.Write(streamData, binary.LittleEndian, float32(capture.EulerZXY().Y()))
-		binary.Write(streamData, binary.LittleEndian, float32(capture.EulerZXY().Z()))
+		binary.Write(streamData, binary.LittleEndian, float32(capture.Angles().X()))
+		binary.Write(streamData, binary.LittleEndian, float32(capture.Angles().Y()))
+		binary.Write(streamData, binary.LittleEndian, float32(capture.Angles().Z()))
 	}
 	return streamData.Bytes()
 }
@@ -27,7 +27,7 @@ func decodeRaw32(streamData *bytes.Reader, times []float64) ([]euler.Capture, er
 		binary.Read(streamData, binary.LittleEndian, &x)
 		binary.Read(streamData, binary.LittleEndian, &y)
 		binary.Read(streamData, binary.LittleEndian, &z)
-		captures[i] = euler.NewEulerZXYCapture(times[i], float64(x), float64(y), float64(z))
+		captures[i] = euler.NewCapture(times[i], float64(x), float64(y), float64(z))
 	}
 
 	return captures, nil
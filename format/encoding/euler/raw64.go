@@ -14,11 +14,11 @@ func encodeRaw64(captures []euler.Capture) []byte {
 	buf := make([]byte, binary.MaxVarintLen64)
 
 	for _, capture := range captures {
-		binary.LittleEndian.PutUint64(buf, math.Float64bits(capture.EulerZXY().X()))
+		binary.LittleEndian.PutUint64(buf, math.Float64bits(capture.Angles().X()))
 		streamData.Write(buf)
-		binary.LittleEndian.PutUint64(buf, math.Float64bits(capture.EulerZXY().Y()))
+		binary.LittleEndian.PutUint64(buf, math.Float64bits(capture.Angles().Y()))
 		streamData.Write(buf)
-		binary.LittleEndian.PutUint64(buf, math.Float64bits(capture.EulerZXY().Z()))
+		binary.LittleEndian.PutUint64(buf, math.Float64bits(capture.Angles().Z()))
 		streamData.Write(buf)
 	}
 	return streamData.Bytes()
@@ -48,7 +48,7 @@ func decodeRaw64(streamData *bytes.Reader, times []float64) ([]euler.Capture, er
 		}
 		z := math.Float64frombits(binary.LittleEndian.Uint64(buf))
 
-		captures[i] = euler.NewEulerZXYCapture(times[i], x, y, z)
+		captures[i] = euler.NewCapture(times[i], x, y, z)
 	}
 
 	return captures, nil
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
+	"fmt"
 
 	"github.com/recolude/rap/format"
 	"github.com/recolude/rap/format/collection/event"
@@ -52,15 +53,14 @@ func (p Encoder) Encode(streams []format.CaptureCollection) ([]byte, [][]byte, e
 
 			allKeyIndxes := make([]uint, len(eventCapture.Metadata().Mapping()))
 			allValueDataBuffer := bytes.Buffer{}
-			keyCount := 0
-			for key, val := range eventCapture.Metadata().Mapping() {
+			for keyCount, key := range eventCapture.Metadata().Keys() {
 				if _, ok := eventKeysSet[key]; !ok {
 					eventKeysSet[key] = len(eventKeysSet)
 				}
+				val := eventCapture.Metadata().Mapping()[key]
 				allKeyIndxes[keyCount] = uint(eventKeysSet[key])
 				allValueDataBuffer.WriteByte(val.Code())
 				allValueDataBuffer.Write(val.Data())
-				keyCount++
 			}
 
 			streamDataBuffers[bufferIndex].Write(rapbinary.UvarintArrayToBytes(allKeyIndxes))
@@ -93,6 +93,9 @@ func (p Encoder) Encode(streams []format.CaptureCollection) ([]byte, [][]byte, e
 func readHeader(header []byte) (names []string, metadataKeys []string, err error) {
 	headerReader := bytes.NewBuffer(header)
 	names, _, err = rapbinary.ReadStringArray(headerReader)
+	if err != nil {
+		return
+	}
 	metadataKeys, _, err = rapbinary.ReadStringArray(headerReader)
 	return
 }
@@ -117,13 +120,22 @@ func (p Encoder) Decode(name string, header []byte, streamData []byte, times []f
 			return nil, err
 		}
 
+		if eventNameIndex >= uint64(len(eventNames)) {
+			return nil, fmt.Errorf("event name index %d is out of range of %d names", eventNameIndex, len(eventNames))
+		}
+
 		block := make(map[string]metadata.Property)
 		for metadataIndex := 0; metadataIndex < len(metadataIndeces); metadataIndex++ {
 			prop, err := metadata.ReadProperty(buf)
 			if err != nil {
 				return nil, err
 			}
-			block[metadataKeys[metadataIndeces[metadataIndex]]] = prop
+
+			keyIndex := metadataIndeces[metadataIndex]
+			if keyIndex >= uint(len(metadataKeys)) {
+				return nil, fmt.Errorf("event metadata key index %d is out of range of %d keys", keyIndex, len(metadataKeys))
+			}
+			block[metadataKeys[keyIndex]] = prop
 		}
 		captures[i] = event.NewCapture(times[i], eventNames[int(eventNameIndex)], metadata.NewBlock(block))
 	}
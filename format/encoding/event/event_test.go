@@ -97,3 +97,37 @@ func Test_SingleEvent(t *testing.T) {
 		})
 	}
 }
+
+func Test_EncodeIsDeterministic(t *testing.T) {
+	// ARRANGE ================================================================
+	build := func() []format.CaptureCollection {
+		captures := make([]eventStream.Capture, 0)
+		for i := 0; i < 10; i++ {
+			captures = append(captures, eventStream.NewCapture(float64(i), "Hit", metadata.NewBlock(map[string]metadata.Property{
+				"a": metadata.NewIntProperty(i),
+				"b": metadata.NewStringProperty("b"),
+				"c": metadata.NewBoolProperty(true),
+				"d": metadata.NewFloat32Property(1.5),
+				"e": metadata.NewMetadataProperty(metadata.NewBlock(map[string]metadata.Property{
+					"x": metadata.NewIntProperty(1),
+					"y": metadata.NewIntProperty(2),
+					"z": metadata.NewIntProperty(3),
+				})),
+			})))
+		}
+		return []format.CaptureCollection{eventStream.NewCollection("Events", captures)}
+	}
+	encoder := event.NewEncoder()
+
+	// ACT ====================================================================
+	header, streams, err := encoder.Encode(build())
+
+	// ASSERT =================================================================
+	assert.NoError(t, err)
+	for i := 0; i < 20; i++ {
+		otherHeader, otherStreams, err := encoder.Encode(build())
+		assert.NoError(t, err)
+		assert.Equal(t, header, otherHeader)
+		assert.Equal(t, streams, otherStreams)
+	}
+}
@@ -0,0 +1,52 @@
+package float
+
+import (
+	"math"
+
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/float"
+	"github.com/recolude/rap/format/encoding/internal/auto"
+)
+
+// Selection describes the technique an auto encoder picked for a collection.
+type Selection struct {
+	Collection string
+	Technique  StorageTechnique
+
+	// MaxError is the largest difference between a capture's original value
+	// and the value decoded back out
+	MaxError float64
+}
+
+// autoSelector measures error as the difference between values. Raw64 is
+// lossless, so is always within budget.
+var autoSelector = auto.Selector[StorageTechnique]{
+	Techniques: []StorageTechnique{Raw64, Raw32, BST16},
+	Encode:     encodeWith,
+	Decode: func(name string, encoded []byte, times []float64) (format.CaptureCollection, error) {
+		return Encoder{}.Decode(name, nil, encoded, times)
+	},
+	Error: func(original, decoded format.Capture) float64 {
+		return math.Abs(original.(float.Capture).Value() - decoded.(float.Capture).Value())
+	},
+}
+
+// NewAutoEncoder builds an encoder that picks the technique producing the
+// fewest bytes for every collection, while keeping every value within
+// maxError of it's original. When not nil, report is called with what was
+// picked for every collection, possibly from multiple goroutines at once.
+func NewAutoEncoder(maxError float64, report func(Selection)) Encoder {
+	return Encoder{auto: true, maxError: maxError, report: auto.NewReporter(report)}
+}
+
+// Select picks the technique producing the fewest bytes for the collection
+// while keeping every value within maxError of it's original.
+func Select(collection format.CaptureCollection, maxError float64) (Selection, error) {
+	selection, _, err := selectTechnique(collection, maxError)
+	return selection, err
+}
+
+func selectTechnique(collection format.CaptureCollection, maxError float64) (Selection, []byte, error) {
+	technique, encoded, measured, err := autoSelector.Select(collection, maxError)
+	return Selection{Collection: collection.Name(), Technique: technique, MaxError: measured}, encoded, err
+}
@@ -0,0 +1,57 @@
+package float_test
+
+import (
+	"testing"
+
+	"github.com/recolude/rap/format"
+	floatCollection "github.com/recolude/rap/format/collection/float"
+	"github.com/recolude/rap/format/encoding/float"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Auto_PicksSmallestWithinBudget(t *testing.T) {
+	captures := make([]floatCollection.Capture, 200)
+	times := make([]float64, len(captures))
+	for i := range captures {
+		times[i] = float64(i) / 10
+		captures[i] = floatCollection.NewCapture(times[i], 20+float64(i)*0.013)
+	}
+	collectionIn := floatCollection.NewCollection("Temperature", captures)
+
+	tests := map[string]struct {
+		maxError float64
+		expected float.StorageTechnique
+	}{
+		"lossless":  {maxError: 0, expected: float.Raw64},
+		"micro":     {maxError: 0.00001, expected: float.Raw32},
+		"hundredth": {maxError: 0.01, expected: float.BST16},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// ARRANGE ========================================================
+			var reported float.Selection
+			encoder := float.NewAutoEncoder(tc.maxError, func(selection float.Selection) {
+				reported = selection
+			})
+
+			// ACT ============================================================
+			selection, errSelect := float.Select(collectionIn, tc.maxError)
+			header, streamsData, errEncode := encoder.Encode([]format.CaptureCollection{collectionIn})
+			collectionOut, errDecode := encoder.Decode("Temperature", header, streamsData[0], times)
+
+			// ASSERT =========================================================
+			assert.NoError(t, errSelect)
+			assert.NoError(t, errEncode)
+			assert.NoError(t, errDecode)
+			assert.Equal(t, tc.expected, selection.Technique)
+			assert.Equal(t, selection, reported)
+			assert.LessOrEqual(t, selection.MaxError, tc.maxError)
+			if assert.Len(t, collectionOut.Captures(), len(captures)) {
+				for i, c := range collectionOut.Captures() {
+					assert.InDelta(t, captures[i].Value(), c.(floatCollection.Capture).Value(), selection.MaxError+1e-12)
+				}
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/recolude/rap/format"
 	"github.com/recolude/rap/format/collection/float"
+	"github.com/recolude/rap/format/encoding/internal/auto"
 	rapbinary "github.com/recolude/rap/internal/io/binary"
 )
 
@@ -27,10 +28,16 @@ const (
 
 type Encoder struct {
 	technique StorageTechnique
+
+	// auto has the technique picked per collection instead, see
+	// NewAutoEncoder
+	auto     bool
+	maxError float64
+	report   auto.Reporter[Selection]
 }
 
 func NewEncoder(technique StorageTechnique) Encoder {
-	return Encoder{technique}
+	return Encoder{technique: technique}
 }
 
 func (p Encoder) Accepts(stream format.CaptureCollection) bool {
@@ -98,40 +105,63 @@ func encodeBST16(out io.Writer, captures []format.Capture) error {
 	return nil
 }
 
-func (p Encoder) Encode(streams []format.CaptureCollection) ([]byte, [][]byte, error) {
-	streamDataBuffers := make([]bytes.Buffer, len(streams))
-	for bufferIndex, stream := range streams {
-
-		// Write technique
-		streamDataBuffers[bufferIndex].WriteByte(byte(p.technique))
-
-		switch p.technique {
-		case Raw64:
-			err := encode64(&streamDataBuffers[bufferIndex], stream.Captures())
-			if err != nil {
-				return nil, nil, err
-			}
-			break
-
-		case Raw32:
-			err := encode32(&streamDataBuffers[bufferIndex], stream.Captures())
-			if err != nil {
-				return nil, nil, err
-			}
-			break
-
-		case BST16:
-			err := encodeBST16(&streamDataBuffers[bufferIndex], stream.Captures())
-			if err != nil {
-				return nil, nil, err
-			}
-			break
+// EncodeCollection uses the encoder's technique, or the one picked for the
+// collection by an auto encoder.
+func (p Encoder) EncodeCollection(stream format.CaptureCollection) ([]byte, error) {
+	if !p.auto {
+		return encodeWith(p.technique, stream)
+	}
+
+	selection, encoded, err := selectTechnique(stream, p.maxError)
+	if err != nil {
+		return nil, err
+	}
+
+	p.report.Report(selection)
+	return encoded, nil
+}
+
+// encodeWith encodes a collection with the technique provided.
+func encodeWith(technique StorageTechnique, stream format.CaptureCollection) ([]byte, error) {
+	streamData := bytes.Buffer{}
+
+	// Write technique
+	streamData.WriteByte(byte(technique))
+
+	switch technique {
+	case Raw64:
+		err := encode64(&streamData, stream.Captures())
+		if err != nil {
+			return nil, err
+		}
+		break
+
+	case Raw32:
+		err := encode32(&streamData, stream.Captures())
+		if err != nil {
+			return nil, err
 		}
+		break
+
+	case BST16:
+		err := encodeBST16(&streamData, stream.Captures())
+		if err != nil {
+			return nil, err
+		}
+		break
 	}
 
+	return streamData.Bytes(), nil
+}
+
+func (p Encoder) Encode(streams []format.CaptureCollection) ([]byte, [][]byte, error) {
 	streamData := make([][]byte, len(streams))
-	for i, buffer := range streamDataBuffers {
-		streamData[i] = buffer.Bytes()
+	for i, stream := range streams {
+		s, err := p.EncodeCollection(stream)
+		if err != nil {
+			return nil, nil, err
+		}
+		streamData[i] = s
 	}
 
 	return nil, streamData, nil
@@ -0,0 +1,89 @@
+// Package auto holds what the auto encoders of every encoding package share
+// for picking a storage technique per collection.
+package auto
+
+import (
+	"math"
+
+	"github.com/recolude/rap/format"
+)
+
+// Selector picks between the storage techniques of an encoder, finding
+// the one producing the fewest bytes for a collection while keeping every
+// capture within an error budget.
+type Selector[T any] struct {
+	// Techniques are tried in order. The first is kept regardless of the
+	// error it introduces, so should be lossless.
+	Techniques []T
+
+	// Encode encodes a collection with the technique provided
+	Encode func(technique T, collection format.CaptureCollection) ([]byte, error)
+
+	// Decode decodes a collection encoded by Encode
+	Decode func(name string, encoded []byte, times []float64) (format.CaptureCollection, error)
+
+	// Error measures how far a decoded capture is from it's original
+	Error func(original, decoded format.Capture) float64
+}
+
+// Select returns the technique picked for the collection along with what it
+// encoded and the largest error measured across every capture.
+func (a Selector[T]) Select(collection format.CaptureCollection, maxError float64) (technique T, best []byte, measured float64, err error) {
+	times := make([]float64, len(collection.Captures()))
+	for i, capture := range collection.Captures() {
+		times[i] = capture.Time()
+	}
+
+	for _, candidate := range a.Techniques {
+		encoded, err := a.Encode(candidate, collection)
+		if err != nil {
+			return technique, nil, 0, err
+		}
+
+		if best != nil && len(encoded) >= len(best) {
+			continue
+		}
+
+		decoded, err := a.Decode(collection.Name(), encoded, times)
+		if err != nil {
+			return technique, nil, 0, err
+		}
+
+		candidateError := 0.0
+		for i, capture := range collection.Captures() {
+			candidateError = math.Max(candidateError, a.Error(capture, decoded.Captures()[i]))
+		}
+
+		if best != nil && !(candidateError <= maxError) {
+			continue
+		}
+
+		technique = candidate
+		best = encoded
+		measured = candidateError
+	}
+
+	return technique, best, measured, nil
+}
+
+// Reporter passes along what an auto encoder picked for every collection.
+// The callback is held by pointer so encoders holding a Reporter can still be
+// compared with ==.
+type Reporter[S any] struct {
+	report *func(S)
+}
+
+// NewReporter builds a Reporter calling report, which can be nil.
+func NewReporter[S any](report func(S)) Reporter[S] {
+	if report == nil {
+		return Reporter[S]{}
+	}
+	return Reporter[S]{report: &report}
+}
+
+// Report calls the callback, if there is one.
+func (r Reporter[S]) Report(selection S) {
+	if r.report != nil {
+		(*r.report)(selection)
+	}
+}
@@ -0,0 +1,287 @@
+package pose
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/euler"
+	"github.com/recolude/rap/format/collection/pose"
+	"github.com/recolude/rap/format/collection/position"
+	eulerEncoding "github.com/recolude/rap/format/encoding/euler"
+	positionEncoding "github.com/recolude/rap/format/encoding/position"
+	rapbinary "github.com/recolude/rap/internal/io/binary"
+)
+
+// Every skeleton used by the collections being encoded is written once to the
+// header:
+//
+//   [uvarint] number of skeletons
+//   [skeleton]... each being:
+//     [string]          name
+//     [string array]    joint names
+//     [uvarint array]   index of every joint's parent plus one
+//
+// Each collection then references it's skeleton and stores every joint with
+// the position and euler encoders, sharing the collection's single time
+// track:
+//
+//   [uvarint] index of the skeleton within the header
+//   [byte]    storage technique
+//   [joint]... each being:
+//     [byte array] position stream
+//     [byte array] rotation stream
+
+type StorageTechnique int
+
+const (
+	// Raw64 stores every joint with position Raw64 and euler Raw64
+	Raw64 StorageTechnique = iota
+
+	// Raw32 stores every joint with position Raw32 and euler Raw32
+	Raw32
+
+	// Quantized stores the root's position with position Oct48, every other
+	// joint's offset from it's parent with position Delta, which costs next
+	// to nothing for fixed length bones, and every rotation with euler Raw16
+	Quantized
+)
+
+type Encoder struct {
+	technique StorageTechnique
+}
+
+func NewEncoder(technique StorageTechnique) Encoder {
+	return Encoder{technique: technique}
+}
+
+// jointEncoders are the encoders used for a joint's position and rotation.
+func (p Encoder) jointEncoders(root bool) (positionEncoding.Encoder, eulerEncoding.Encoder) {
+	switch p.technique {
+	case Raw32:
+		return positionEncoding.NewEncoder(positionEncoding.Raw32), eulerEncoding.NewEncoder(eulerEncoding.Raw32)
+
+	case Quantized:
+		if root {
+			return positionEncoding.NewEncoder(positionEncoding.Oct48), eulerEncoding.NewEncoder(eulerEncoding.Raw16)
+		}
+		return positionEncoding.NewEncoder(positionEncoding.Delta), eulerEncoding.NewEncoder(eulerEncoding.Raw16)
+	}
+
+	return positionEncoding.NewEncoder(positionEncoding.Raw64), eulerEncoding.NewEncoder(eulerEncoding.Raw64)
+}
+
+func (p Encoder) encodeCollection(collection pose.Collection, skeletonIndex int) ([]byte, error) {
+	if err := collection.Validate(); err != nil {
+		return nil, err
+	}
+
+	streamData := new(bytes.Buffer)
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, uint64(skeletonIndex))
+	streamData.Write(buf[:n])
+	streamData.WriteByte(byte(p.technique))
+
+	for joint := 0; joint < collection.Skeleton().JointCount(); joint++ {
+		positions := make([]position.Capture, collection.Length())
+		rotations := make([]euler.Capture, collection.Length())
+		for i, c := range collection.Captures() {
+			local := c.(pose.Capture).Joint(joint)
+			positions[i] = position.NewCapture(c.Time(), local.Position().X(), local.Position().Y(), local.Position().Z())
+			rotations[i] = euler.NewCapture(c.Time(), local.Rotation().X(), local.Rotation().Y(), local.Rotation().Z())
+		}
+
+		positionEncoder, rotationEncoder := p.jointEncoders(joint == 0)
+
+		positionData, err := positionEncoder.EncodeCollection(position.NewCollection(collection.Name(), positions))
+		if err != nil {
+			return nil, err
+		}
+
+		rotationData, err := rotationEncoder.EncodeCollection(euler.NewCollectionWithOrder(collection.Name(), collection.Order(), rotations))
+		if err != nil {
+			return nil, err
+		}
+
+		streamData.Write(rapbinary.BytesArrayToBytes(positionData))
+		streamData.Write(rapbinary.BytesArrayToBytes(rotationData))
+	}
+
+	return streamData.Bytes(), nil
+}
+
+func (p Encoder) Encode(streams []format.CaptureCollection) ([]byte, [][]byte, error) {
+	skeletons := make([]pose.Skeleton, 0)
+	allStreamData := make([][]byte, len(streams))
+
+	for i, stream := range streams {
+		collection := stream.(pose.Collection)
+
+		skeletonIndex := -1
+		for j, skeleton := range skeletons {
+			if skeleton.Equal(collection.Skeleton()) {
+				skeletonIndex = j
+				break
+			}
+		}
+
+		if skeletonIndex == -1 {
+			skeletonIndex = len(skeletons)
+			skeletons = append(skeletons, collection.Skeleton())
+		}
+
+		s, err := p.encodeCollection(collection, skeletonIndex)
+		if err != nil {
+			return nil, nil, err
+		}
+		allStreamData[i] = s
+	}
+
+	header := new(bytes.Buffer)
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, uint64(len(skeletons)))
+	header.Write(buf[:n])
+
+	for _, skeleton := range skeletons {
+		parents := make([]uint, skeleton.JointCount())
+		for i, parent := range skeleton.Parents() {
+			parents[i] = uint(parent + 1)
+		}
+
+		header.Write(rapbinary.StringToBytes(skeleton.Name()))
+		header.Write(rapbinary.StringArrayToBytes(skeleton.Joints()))
+		header.Write(rapbinary.UvarintArrayToBytes(parents))
+	}
+
+	return header.Bytes(), allStreamData, nil
+}
+
+// decodeSkeletons reads the skeletons shared by every collection. Nothing
+// within the header can be longer than what remains of it, so lengths are
+// limited by that instead of being trusted.
+func decodeSkeletons(header []byte) ([]pose.Skeleton, error) {
+	reader := bytes.NewReader(header)
+
+	count, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	skeletons := make([]pose.Skeleton, 0, rapbinary.InitialCapacity(count))
+	for i := uint64(0); i < count; i++ {
+		name, _, err := rapbinary.ReadStringLimited(reader, uint64(reader.Len()))
+		if err != nil {
+			return nil, err
+		}
+
+		joints, _, err := rapbinary.ReadStringArrayLimited(reader, uint64(reader.Len()))
+		if err != nil {
+			return nil, err
+		}
+
+		encodedParents, _, err := rapbinary.ReadUvarIntArrayLimited(reader, uint64(reader.Len()))
+		if err != nil {
+			return nil, err
+		}
+
+		parents := make([]int, len(encodedParents))
+		for j, parent := range encodedParents {
+			parents[j] = int(parent) - 1
+		}
+
+		skeleton, err := pose.NewSkeleton(name, joints, parents)
+		if err != nil {
+			return nil, err
+		}
+		skeletons = append(skeletons, skeleton)
+	}
+
+	return skeletons, nil
+}
+
+func (p Encoder) Decode(name string, header []byte, streamData []byte, times []float64) (format.CaptureCollection, error) {
+	skeletons, err := decodeSkeletons(header)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := bytes.NewReader(streamData)
+
+	skeletonIndex, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if skeletonIndex >= uint64(len(skeletons)) {
+		return nil, fmt.Errorf("pose collection %s uses skeleton %d but only %d are defined", name, skeletonIndex, len(skeletons))
+	}
+	skeleton := skeletons[skeletonIndex]
+
+	typeByte, err := reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	encodingTechnique := StorageTechnique(typeByte)
+	if encodingTechnique != Raw64 && encodingTechnique != Raw32 && encodingTechnique != Quantized {
+		return nil, fmt.Errorf("Unknown pose encoding technique: %d", int(encodingTechnique))
+	}
+
+	order := euler.ZXY
+	joints := make([][]pose.Joint, len(times))
+	for i := range joints {
+		joints[i] = make([]pose.Joint, skeleton.JointCount())
+	}
+
+	for joint := 0; joint < skeleton.JointCount(); joint++ {
+		positionData, _, err := rapbinary.ReadBytesArrayLimited(reader, uint64(reader.Len()))
+		if err != nil {
+			return nil, err
+		}
+
+		rotationData, _, err := rapbinary.ReadBytesArrayLimited(reader, uint64(reader.Len()))
+		if err != nil {
+			return nil, err
+		}
+
+		positionEncoder, rotationEncoder := Encoder{technique: encodingTechnique}.jointEncoders(joint == 0)
+
+		positions, err := positionEncoder.Decode(name, nil, positionData, times)
+		if err != nil {
+			return nil, err
+		}
+
+		rotations, err := rotationEncoder.Decode(name, nil, rotationData, times)
+		if err != nil {
+			return nil, err
+		}
+
+		order = rotations.(euler.Collection).Order()
+		for i := range joints {
+			joints[i][joint] = pose.NewJoint(
+				positions.CaptureAt(i).(position.Capture).Position(),
+				rotations.CaptureAt(i).(euler.Capture).Angles(),
+			)
+		}
+	}
+
+	captures := make([]pose.Capture, len(times))
+	for i := range captures {
+		captures[i] = pose.NewCapture(times[i], joints[i])
+	}
+
+	return pose.NewCollectionWithOrder(name, skeleton, order, captures), nil
+}
+
+func (p Encoder) Accepts(stream format.CaptureCollection) bool {
+	return stream.Signature() == "recolude.pose"
+}
+
+func (p Encoder) Signature() string {
+	return "recolude.pose"
+}
+
+func (p Encoder) Version() uint {
+	return 0
+}
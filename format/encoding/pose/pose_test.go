@@ -0,0 +1,235 @@
+package pose_test
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/euler"
+	poseCollection "github.com/recolude/rap/format/collection/pose"
+	"github.com/recolude/rap/format/encoding"
+	"github.com/recolude/rap/format/encoding/pose"
+	"github.com/recolude/rap/format/io"
+	"github.com/recolude/rap/format/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildSkeleton(t *testing.T, name string, joints int) poseCollection.Skeleton {
+	names := make([]string, joints)
+	parents := make([]int, joints)
+	for i := range names {
+		names[i] = fmt.Sprintf("Joint %d", i)
+		parents[i] = (i+1)/2 - 1
+	}
+
+	skeleton, err := poseCollection.NewSkeleton(name, names, parents)
+	assert.NoError(t, err)
+	return skeleton
+}
+
+// buildWalk moves the root forward while every other joint sways on bones of
+// fixed length.
+func buildWalk(skeleton poseCollection.Skeleton, n int) ([]poseCollection.Capture, []float64) {
+	captures := make([]poseCollection.Capture, n)
+	times := make([]float64, n)
+	for i := range captures {
+		times[i] = float64(i) / 30
+		joints := make([]poseCollection.Joint, skeleton.JointCount())
+		for j := range joints {
+			offset := vector3.New(0.1, 0.2, float64(j)*0.01)
+			if j == 0 {
+				offset = vector3.New(times[i], 1, 0)
+			}
+			joints[j] = poseCollection.NewJoint(offset, vector3.New(
+				math.Sin(times[i]+float64(j))*30,
+				math.Cos(times[i]*2+float64(j))*45,
+				float64(j),
+			))
+		}
+		captures[i] = poseCollection.NewCapture(times[i], joints)
+	}
+	return captures, times
+}
+
+// angleError is the largest difference between the angles of two rotations,
+// ignoring whole turns.
+func angleError(a, b vector3.Float64) float64 {
+	largest := 0.0
+	for _, difference := range []float64{a.X() - b.X(), a.Y() - b.Y(), a.Z() - b.Z()} {
+		wrapped := math.Mod(math.Mod(difference, 360)+540, 360) - 180
+		largest = math.Max(largest, math.Abs(wrapped))
+	}
+	return largest
+}
+
+func Test_Pose(t *testing.T) {
+	skeleton := buildSkeleton(t, "Humanoid", 22)
+	walkCaptures, walkTimes := buildWalk(skeleton, 300)
+
+	tests := map[string]struct {
+		captures []poseCollection.Capture
+		times    []float64
+	}{
+		"empty":  {captures: []poseCollection.Capture{}},
+		"single": {captures: walkCaptures[:1], times: walkTimes[:1]},
+		"walk":   {captures: walkCaptures, times: walkTimes},
+	}
+
+	storageTechniques := []struct {
+		displayName string
+		technique   pose.StorageTechnique
+		tolerance   float64
+	}{
+		{displayName: "Raw64", technique: pose.Raw64, tolerance: 0},
+		{displayName: "Raw32", technique: pose.Raw32, tolerance: 0.0001},
+		{displayName: "Quantized", technique: pose.Quantized, tolerance: 0.01},
+	}
+
+	for name, tc := range tests {
+		for _, technique := range storageTechniques {
+			t.Run(fmt.Sprintf("%s/%s", name, technique.displayName), func(t *testing.T) {
+				// ARRANGE ====================================================
+				streamIn := poseCollection.NewCollectionWithOrder("Avatar", skeleton, euler.YXZ, tc.captures)
+				encoder := pose.NewEncoder(technique.technique)
+
+				// ACT ========================================================
+				header, streamsData, encodeErr := encoder.Encode([]format.CaptureCollection{streamIn})
+				streamOut, decodeErr := encoder.Decode("Avatar", header, streamsData[0], tc.times)
+
+				// ASSERT =====================================================
+				assert.NoError(t, encodeErr)
+				assert.NoError(t, decodeErr)
+				if assert.NotNil(t, streamOut) && assert.Len(t, streamOut.Captures(), len(tc.captures)) {
+					collectionOut := streamOut.(poseCollection.Collection)
+					assert.Equal(t, "Avatar", collectionOut.Name())
+					assert.Equal(t, "recolude.pose", collectionOut.Signature())
+					assert.Equal(t, euler.YXZ, collectionOut.Order())
+					assert.True(t, skeleton.Equal(collectionOut.Skeleton()))
+					for i, c := range streamOut.Captures() {
+						assert.Equal(t, tc.captures[i].Time(), c.Time())
+						for j, joint := range c.(poseCollection.Capture).Joints() {
+							expected := tc.captures[i].Joint(j)
+							if !assert.InDelta(t, 0, expected.Position().Distance(joint.Position()), technique.tolerance, "[%d] joint %d", i, j) ||
+								!assert.InDelta(t, 0, angleError(expected.Rotation(), joint.Rotation()), technique.tolerance, "[%d] joint %d", i, j) {
+								return
+							}
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+func Test_Pose_SharesSkeletons(t *testing.T) {
+	// ARRANGE ================================================================
+	humanoid := buildSkeleton(t, "Humanoid", 22)
+	hand := buildSkeleton(t, "Hand", 5)
+	humanoidCaptures, humanoidTimes := buildWalk(humanoid, 10)
+	handCaptures, handTimes := buildWalk(hand, 10)
+	encoder := pose.NewEncoder(pose.Raw32)
+
+	// ACT ====================================================================
+	header, streamsData, encodeErr := encoder.Encode([]format.CaptureCollection{
+		poseCollection.NewCollection("Player 1", humanoid, humanoidCaptures),
+		poseCollection.NewCollection("Left Hand", hand, handCaptures),
+		poseCollection.NewCollection("Player 2", humanoid, humanoidCaptures),
+	})
+	singleHeader, _, singleErr := encoder.Encode([]format.CaptureCollection{
+		poseCollection.NewCollection("Player 1", humanoid, humanoidCaptures),
+	})
+	player2, decodeErr := encoder.Decode("Player 2", header, streamsData[2], humanoidTimes)
+	leftHand, decodeHandErr := encoder.Decode("Left Hand", header, streamsData[1], handTimes)
+
+	// ASSERT =================================================================
+	assert.NoError(t, encodeErr)
+	assert.NoError(t, singleErr)
+	assert.NoError(t, decodeErr)
+	assert.NoError(t, decodeHandErr)
+	assert.Less(t, len(header), len(singleHeader)*2)
+	assert.True(t, humanoid.Equal(player2.(poseCollection.Collection).Skeleton()))
+	assert.True(t, hand.Equal(leftHand.(poseCollection.Collection).Skeleton()))
+}
+
+func Test_Pose_SmallerThanJointRecordings(t *testing.T) {
+	// ARRANGE ================================================================
+	skeleton := buildSkeleton(t, "Humanoid", 22)
+	captures, _ := buildWalk(skeleton, 300)
+	collection := poseCollection.NewCollection("Avatar", skeleton, captures)
+	jointRecordings, err := collection.ToRecordings()
+	assert.NoError(t, err)
+
+	write := func(collections []format.CaptureCollection, recordings []format.Recording) int {
+		out := new(bytes.Buffer)
+		writer := io.NewWriter(encoding.Registered(), false, out, io.Raw64)
+		written, err := writer.Write(format.NewRecording("", "Avatar", collections, recordings, metadata.EmptyBlock(), nil, nil))
+		assert.NoError(t, err)
+		return written
+	}
+
+	// ACT ====================================================================
+	poseSize := write([]format.CaptureCollection{collection}, nil)
+	jointsSize := write(nil, jointRecordings)
+
+	// ASSERT =================================================================
+	assert.Less(t, poseSize*2, jointsSize)
+}
+
+func Test_Pose_Errors(t *testing.T) {
+	// ARRANGE ================================================================
+	skeleton := buildSkeleton(t, "Humanoid", 3)
+	captures, times := buildWalk(skeleton, 10)
+	encoder := pose.NewEncoder(pose.Raw32)
+	header, streamsData, err := encoder.Encode([]format.CaptureCollection{poseCollection.NewCollection("Avatar", skeleton, captures)})
+	assert.NoError(t, err)
+
+	unknownSkeleton := append([]byte{1}, streamsData[0][1:]...)
+	unknownTechnique := append([]byte{0, 9}, streamsData[0][2:]...)
+
+	// ACT ====================================================================
+	_, _, errJoints := encoder.Encode([]format.CaptureCollection{
+		poseCollection.NewCollection("Avatar", skeleton, []poseCollection.Capture{poseCollection.NewCapture(0, nil)}),
+	})
+	_, errSkeleton := encoder.Decode("Avatar", header, unknownSkeleton, times)
+	_, errTechnique := encoder.Decode("Avatar", header, unknownTechnique, times)
+	_, errTruncated := encoder.Decode("Avatar", header, streamsData[0][:len(streamsData[0])-1], times)
+	_, errHeader := encoder.Decode("Avatar", header[:len(header)-1], streamsData[0], times)
+
+	// ASSERT =================================================================
+	assert.EqualError(t, errJoints, "capture 0 of pose collection Avatar has 0 joints but skeleton Humanoid has 3")
+	assert.EqualError(t, errSkeleton, "pose collection Avatar uses skeleton 1 but only 1 are defined")
+	assert.EqualError(t, errTechnique, "Unknown pose encoding technique: 9")
+	assert.Error(t, errTruncated)
+	assert.Error(t, errHeader)
+}
+
+func Test_Pose_HostileLengthPrefixes(t *testing.T) {
+	// ARRANGE ================================================================
+	skeleton := buildSkeleton(t, "Humanoid", 3)
+	captures, times := buildWalk(skeleton, 10)
+	encoder := pose.NewEncoder(pose.Raw32)
+	header, streamsData, err := encoder.Encode([]format.CaptureCollection{poseCollection.NewCollection("Avatar", skeleton, captures)})
+	assert.NoError(t, err)
+
+	// [skeleton count][name length][name]...
+	hostileName := []byte{1, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01}
+
+	// [skeleton index][technique][position length][positions]...
+	hostileJoint := []byte{0, streamsData[0][1], 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01}
+
+	// ACT ====================================================================
+	_, errName := encoder.Decode("Avatar", hostileName, streamsData[0], times)
+	_, errJoint := encoder.Decode("Avatar", header, hostileJoint, times)
+
+	// ASSERT =================================================================
+	var limitErr io.LimitError
+	if assert.ErrorAs(t, errName, &limitErr) {
+		assert.Equal(t, io.LimitError{Limit: "string length", Value: 1 << 42, Max: 7}, limitErr)
+	}
+	if assert.ErrorAs(t, errJoint, &limitErr) {
+		assert.Equal(t, io.LimitError{Limit: "byte array length", Value: 1 << 42, Max: 7}, limitErr)
+	}
+}
@@ -0,0 +1,50 @@
+package position
+
+import (
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/position"
+	"github.com/recolude/rap/format/encoding/internal/auto"
+)
+
+// Selection describes the technique an auto encoder picked for a collection.
+type Selection struct {
+	Collection string
+	Technique  StorageTechnique
+
+	// MaxError is the largest distance between a capture's original position
+	// and the position decoded back out
+	MaxError float64
+}
+
+// autoSelector measures error as the distance between positions, with Raw64
+// as the lossless fallback.
+var autoSelector = auto.Selector[StorageTechnique]{
+	Techniques: []StorageTechnique{Raw64, Raw32, Oct48, Oct24},
+	Encode: func(technique StorageTechnique, collection format.CaptureCollection) ([]byte, error) {
+		return encodeWith(technique, 0, 0, collection)
+	},
+	Decode: decode,
+	Error: func(original, decoded format.Capture) float64 {
+		return original.(position.Capture).Position().Distance(decoded.(position.Capture).Position())
+	},
+}
+
+// NewAutoEncoder builds an encoder that picks the technique producing the
+// fewest bytes for every collection, while keeping every position within
+// maxError of it's original. When not nil, report is called with what was
+// picked for every collection, possibly from multiple goroutines at once.
+func NewAutoEncoder(maxError float64, report func(Selection)) Encoder {
+	return Encoder{auto: true, maxError: maxError, report: auto.NewReporter(report)}
+}
+
+// Select picks the technique producing the fewest bytes for the collection
+// while keeping every position within maxError of it's original.
+func Select(collection format.CaptureCollection, maxError float64) (Selection, error) {
+	selection, _, err := selectTechnique(collection, maxError)
+	return selection, err
+}
+
+func selectTechnique(collection format.CaptureCollection, maxError float64) (Selection, []byte, error) {
+	technique, encoded, measured, err := autoSelector.Select(collection, maxError)
+	return Selection{Collection: collection.Name(), Technique: technique, MaxError: measured}, encoded, err
+}
@@ -0,0 +1,69 @@
+package position_test
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/recolude/rap/format"
+	positionCollection "github.com/recolude/rap/format/collection/position"
+	"github.com/recolude/rap/format/encoding/position"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Auto_PicksSmallestWithinBudget(t *testing.T) {
+	// Wander around within a one meter cube
+	captures := make([]positionCollection.Capture, 200)
+	times := make([]float64, len(captures))
+	for i := range captures {
+		times[i] = float64(i) / 10
+		captures[i] = positionCollection.NewCapture(times[i], rand.Float64(), rand.Float64(), rand.Float64())
+	}
+	collectionIn := positionCollection.NewCollection("Head", captures)
+
+	tests := map[string]struct {
+		maxError float64
+		expected position.StorageTechnique
+	}{
+		"lossless":     {maxError: 0, expected: position.Raw64},
+		"millimeter":   {maxError: 0.001, expected: position.Oct48},
+		"decimeter":    {maxError: 0.1, expected: position.Oct24},
+		"no tolerance": {maxError: -1, expected: position.Raw64},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// ARRANGE ========================================================
+			reported := make([]position.Selection, 0)
+			lock := sync.Mutex{}
+			encoder := position.NewAutoEncoder(tc.maxError, func(selection position.Selection) {
+				lock.Lock()
+				defer lock.Unlock()
+				reported = append(reported, selection)
+			})
+
+			// ACT ============================================================
+			selection, errSelect := position.Select(collectionIn, tc.maxError)
+			header, streamsData, errEncode := encoder.Encode([]format.CaptureCollection{collectionIn})
+			collectionOut, errDecode := encoder.Decode("Head", header, streamsData[0], times)
+
+			// ASSERT =========================================================
+			assert.NoError(t, errSelect)
+			assert.NoError(t, errEncode)
+			assert.NoError(t, errDecode)
+			assert.Equal(t, tc.expected, selection.Technique)
+			assert.Equal(t, []position.Selection{selection}, reported)
+			assert.Equal(t, "Head", selection.Collection)
+			if tc.maxError >= 0 {
+				assert.LessOrEqual(t, selection.MaxError, tc.maxError)
+			}
+
+			if assert.Len(t, collectionOut.Captures(), len(captures)) {
+				for i, c := range collectionOut.Captures() {
+					distance := captures[i].Position().Distance(c.(positionCollection.Capture).Position())
+					assert.LessOrEqual(t, distance, selection.MaxError)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package position
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/recolude/rap/format/collection/position"
+)
+
+// Chunked octree techniques split a collection into chunks that are each
+// encoded exactly as Oct24 or Oct48 would encode them on their own:
+//
+//   [uvarint] number of chunks
+//   [chunk]... each being:
+//     [uvarint] number of captures within the chunk
+//     [bytes]   the chunk's captures encoded with Oct24 or Oct48
+//
+// Every chunk starts back at an exact position with it's own bounds, so a
+// single large jump only costs precision within the chunk it happens in.
+
+const (
+	// maxAdaptiveChunkSize is the most captures an adaptive chunk grows to
+	maxAdaptiveChunkSize = 1024
+
+	// outlierWindow is how many movements on either side of a movement it's
+	// compared against when looking for outliers
+	outlierWindow = 8
+
+	// outlierFactor is how many times larger than the movements around it a
+	// single movement must be to start a new chunk
+	outlierFactor = 10
+)
+
+// NewChunkedOctEncoder builds an encoder using either ChunkedOct24 or
+// ChunkedOct48 that splits collections into chunks of chunkSize captures. A
+// chunkSize of 0 or less picks chunks adaptively instead, starting a new
+// chunk whenever a capture jumps far further than the ones around it do.
+func NewChunkedOctEncoder(technique StorageTechnique, chunkSize int) Encoder {
+	return Encoder{technique: technique, chunkSize: chunkSize, resolution: DefaultResolution}
+}
+
+// typicalMovement is the median of the movements surrounding the i-th one,
+// falling back to their mean when mostly standing still.
+func typicalMovement(movements []float64, i int) float64 {
+	first := i - outlierWindow
+	if first < 1 {
+		first = 1
+	}
+	last := i + outlierWindow
+	if last > len(movements)-1 {
+		last = len(movements) - 1
+	}
+
+	neighbors := make([]float64, 0, outlierWindow*2)
+	for j := first; j <= last; j++ {
+		if j != i {
+			neighbors = append(neighbors, movements[j])
+		}
+	}
+
+	if len(neighbors) == 0 {
+		return math.Inf(1)
+	}
+
+	sort.Float64s(neighbors)
+	median := neighbors[len(neighbors)/2]
+	if median > 0 {
+		return median
+	}
+
+	total := 0.0
+	for _, neighbor := range neighbors {
+		total += neighbor
+	}
+	return total / float64(len(neighbors))
+}
+
+// chunkCaptures determines the number of captures within each chunk.
+func chunkCaptures(captures []position.Capture, chunkSize int) []int {
+	chunks := make([]int, 0)
+
+	if chunkSize > 0 {
+		for start := 0; start < len(captures); start += chunkSize {
+			size := chunkSize
+			if remaining := len(captures) - start; remaining < size {
+				size = remaining
+			}
+			chunks = append(chunks, size)
+		}
+		return chunks
+	}
+
+	// movements[i] is the distance traveled from capture i-1 to capture i
+	movements := make([]float64, len(captures))
+	for i := 1; i < len(captures); i++ {
+		movements[i] = captures[i].Position().Distance(captures[i-1].Position())
+	}
+
+	start := 0
+	for i := 1; i < len(captures); i++ {
+		outlier := movements[i] > outlierFactor*typicalMovement(movements, i)
+		if outlier || i-start >= maxAdaptiveChunkSize {
+			chunks = append(chunks, i-start)
+			start = i
+		}
+	}
+
+	if start < len(captures) {
+		chunks = append(chunks, len(captures)-start)
+	}
+	return chunks
+}
+
+func encodeChunkedOct(captures []position.Capture, chunkSize int, encodeChunk func([]position.Capture) ([]byte, error)) ([]byte, error) {
+	streamData := new(bytes.Buffer)
+	buf := make([]byte, binary.MaxVarintLen64)
+
+	chunks := chunkCaptures(captures, chunkSize)
+	n := binary.PutUvarint(buf, uint64(len(chunks)))
+	streamData.Write(buf[:n])
+
+	start := 0
+	for _, count := range chunks {
+		n = binary.PutUvarint(buf, uint64(count))
+		streamData.Write(buf[:n])
+
+		data, err := encodeChunk(captures[start : start+count])
+		if err != nil {
+			return nil, err
+		}
+		streamData.Write(data)
+		start += count
+	}
+
+	return streamData.Bytes(), nil
+}
+
+func decodeChunkedOct(streamData *bytes.Reader, times []float64, decodeChunk func(*bytes.Reader, []float64) ([]position.Capture, error)) ([]position.Capture, error) {
+	numChunks, err := binary.ReadUvarint(streamData)
+	if err != nil {
+		return nil, err
+	}
+
+	captures := make([]position.Capture, 0, len(times))
+	for i := uint64(0); i < numChunks; i++ {
+		count, err := binary.ReadUvarint(streamData)
+		if err != nil {
+			return nil, err
+		}
+
+		if count > uint64(len(times)-len(captures)) {
+			return nil, fmt.Errorf("octree chunk of %d captures exceeds the %d captures remaining", count, len(times)-len(captures))
+		}
+
+		chunk, err := decodeChunk(streamData, times[len(captures):len(captures)+int(count)])
+		if err != nil {
+			return nil, err
+		}
+		captures = append(captures, chunk...)
+	}
+
+	if len(captures) != len(times) {
+		return nil, fmt.Errorf("octree chunks hold %d captures but there are %d times", len(captures), len(times))
+	}
+	return captures, nil
+}
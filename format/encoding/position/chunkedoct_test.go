@@ -0,0 +1,132 @@
+package position_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/recolude/rap/format"
+	positionCollection "github.com/recolude/rap/format/collection/position"
+	"github.com/recolude/rap/format/encoding/position"
+	"github.com/stretchr/testify/assert"
+)
+
+// buildTeleportCollection walks slowly around a room far from the origin,
+// teleporting to the origin and back halfway through.
+func buildTeleportCollection(n int) (format.CaptureCollection, []float64) {
+	captures := make([]positionCollection.Capture, n)
+	times := make([]float64, n)
+	for i := range captures {
+		times[i] = float64(i) / 30
+		captures[i] = positionCollection.NewCapture(
+			times[i],
+			5000+math.Cos(times[i]/4)*3,
+			1.7+math.Sin(times[i]*2)*0.05,
+			-2000+math.Sin(times[i]/4)*3,
+		)
+	}
+	captures[n/2] = positionCollection.NewCapture(times[n/2], 0, 0, 0)
+	return positionCollection.NewCollection("Walk", captures), times
+}
+
+func maxPositionError(t *testing.T, encoder position.Encoder, collectionIn format.CaptureCollection, times []float64) float64 {
+	header, streamsData, err := encoder.Encode([]format.CaptureCollection{collectionIn})
+	assert.NoError(t, err)
+
+	collectionOut, err := encoder.Decode(collectionIn.Name(), header, streamsData[0], times)
+	assert.NoError(t, err)
+	if !assert.Len(t, collectionOut.Captures(), len(times)) {
+		return math.Inf(1)
+	}
+
+	largest := 0.0
+	for i, c := range collectionOut.Captures() {
+		assert.Equal(t, times[i], c.Time())
+		expected := collectionIn.Captures()[i].(positionCollection.Capture).Position()
+		largest = math.Max(largest, expected.Distance(c.(positionCollection.Capture).Position()))
+	}
+	return largest
+}
+
+func Test_ChunkedOct_RoundTrip(t *testing.T) {
+	tests := map[string]struct {
+		encoder  position.Encoder
+		maxError float64
+	}{
+		"24 adaptive":     {encoder: position.NewEncoder(position.ChunkedOct24), maxError: 0.01},
+		"48 adaptive":     {encoder: position.NewEncoder(position.ChunkedOct48), maxError: 0.001},
+		"24 chunks of 1":  {encoder: position.NewChunkedOctEncoder(position.ChunkedOct24, 1), maxError: 0.001},
+		"24 chunks of 64": {encoder: position.NewChunkedOctEncoder(position.ChunkedOct24, 64), maxError: 0.01},
+		"48 chunks of 7":  {encoder: position.NewChunkedOctEncoder(position.ChunkedOct48, 7), maxError: 0.001},
+	}
+
+	collectionIn, times := buildSmoothCollection(1000)
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Less(t, maxPositionError(t, tc.encoder, collectionIn, times), tc.maxError)
+		})
+	}
+}
+
+func Test_ChunkedOct_IsolatesOutliers(t *testing.T) {
+	// ARRANGE ================================================================
+	collectionIn, times := buildTeleportCollection(1000)
+
+	// ACT ====================================================================
+	oct24 := maxPositionError(t, position.NewEncoder(position.Oct24), collectionIn, times)
+	chunked24 := maxPositionError(t, position.NewEncoder(position.ChunkedOct24), collectionIn, times)
+	oct48 := maxPositionError(t, position.NewEncoder(position.Oct48), collectionIn, times)
+	chunked48 := maxPositionError(t, position.NewEncoder(position.ChunkedOct48), collectionIn, times)
+
+	// ASSERT =================================================================
+	assert.Less(t, chunked24*100, oct24)
+	assert.Less(t, chunked48*100, oct48)
+	assert.Less(t, chunked24, 0.01)
+}
+
+func Test_ChunkedOct_Small(t *testing.T) {
+	tests := map[string][]positionCollection.Capture{
+		"empty": nil,
+		"single": {
+			positionCollection.NewCapture(1, 2, 3, 4),
+		},
+		"pair": {
+			positionCollection.NewCapture(1, 2, 3, 4),
+			positionCollection.NewCapture(2, 5, 6, 7),
+		},
+	}
+
+	for name, captures := range tests {
+		t.Run(name, func(t *testing.T) {
+			// ARRANGE ========================================================
+			times := make([]float64, len(captures))
+			for i, capture := range captures {
+				times[i] = capture.Time()
+			}
+
+			// ACT ============================================================
+			largest := maxPositionError(t, position.NewEncoder(position.ChunkedOct24), positionCollection.NewCollection("Small", captures), times)
+
+			// ASSERT =========================================================
+			assert.Less(t, largest, 0.001)
+		})
+	}
+}
+
+func Test_ChunkedOct_Errors(t *testing.T) {
+	// ARRANGE ================================================================
+	collectionIn, times := buildSmoothCollection(100)
+	encoder := position.NewChunkedOctEncoder(position.ChunkedOct24, 30)
+	valid, err := encoder.EncodeCollection(collectionIn)
+	assert.NoError(t, err)
+
+	// ACT ====================================================================
+	_, errTruncated := encoder.Decode("Walk", nil, valid[:5], times)
+	_, errTooFew := encoder.Decode("Walk", nil, valid, times[:99])
+	_, errTooMany := encoder.Decode("Walk", nil, valid, append(times, 100))
+
+	// ASSERT =================================================================
+	assert.Error(t, errTruncated)
+	assert.EqualError(t, errTooFew, "octree chunk of 10 captures exceeds the 9 captures remaining")
+	assert.EqualError(t, errTooMany, "octree chunks hold 100 captures but there are 101 times")
+}
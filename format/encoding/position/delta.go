@@ -0,0 +1,129 @@
+package position
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/recolude/rap/format/collection/position"
+)
+
+// DefaultResolution is the resolution delta techniques quantize to when
+// built with NewEncoder, being one millimeter when positions are in meters.
+const DefaultResolution = 0.001
+
+// maxQuantized is the largest magnitude a quantized value may have, leaving
+// room for predictions and their residuals to never overflow.
+const maxQuantized = 1 << 60
+
+// NewDeltaEncoder builds an encoder that quantizes every position to the
+// resolution provided and stores the difference between consecutive
+// captures, guaranteeing every decoded value is within half the resolution
+// of it's original. Predicting the velocity suits smooth motion, where each
+// capture is predicted to continue moving like the two before it did.
+func NewDeltaEncoder(resolution float64, predictVelocity bool) Encoder {
+	technique := Delta
+	if predictVelocity {
+		technique = DeltaVelocity
+	}
+	return Encoder{technique: technique, resolution: resolution}
+}
+
+func quantize(value, resolution float64) (int64, error) {
+	quantized := math.Round(value / resolution)
+	if !(math.Abs(quantized) <= maxQuantized) {
+		return 0, fmt.Errorf("position value %g can not be represented at a resolution of %g", value, resolution)
+	}
+	return int64(quantized), nil
+}
+
+// predict guesses the next quantized value from the ones before it.
+func predict(previous []int64, i int, predictVelocity bool) int64 {
+	if i == 0 {
+		return 0
+	}
+
+	if !predictVelocity || i == 1 {
+		return previous[i-1]
+	}
+	return 2*previous[i-1] - previous[i-2]
+}
+
+// encodeDelta writes the resolution followed by the zig-zag varint residual
+// of every value from it's prediction.
+func encodeDelta(captures []position.Capture, resolution float64, predictVelocity bool) ([]byte, error) {
+	if !(resolution > 0) || math.IsInf(resolution, 1) {
+		return nil, fmt.Errorf("delta resolution must be positive, found %g", resolution)
+	}
+
+	streamData := new(bytes.Buffer)
+	binary.Write(streamData, binary.LittleEndian, resolution)
+
+	axes := [3][]int64{
+		make([]int64, len(captures)),
+		make([]int64, len(captures)),
+		make([]int64, len(captures)),
+	}
+
+	buf := make([]byte, binary.MaxVarintLen64)
+	for i, capture := range captures {
+		values := [3]float64{capture.Position().X(), capture.Position().Y(), capture.Position().Z()}
+		for axis, value := range values {
+			quantized, err := quantize(value, resolution)
+			if err != nil {
+				return nil, err
+			}
+			axes[axis][i] = quantized
+
+			n := binary.PutVarint(buf, quantized-predict(axes[axis], i, predictVelocity))
+			streamData.Write(buf[:n])
+		}
+	}
+
+	return streamData.Bytes(), nil
+}
+
+func decodeDelta(streamData *bytes.Reader, times []float64, predictVelocity bool) ([]position.Capture, error) {
+	var resolution float64
+	err := binary.Read(streamData, binary.LittleEndian, &resolution)
+	if err != nil {
+		return nil, err
+	}
+
+	if !(resolution > 0) {
+		return nil, fmt.Errorf("delta resolution must be positive, found %g", resolution)
+	}
+
+	axes := [3][]int64{
+		make([]int64, len(times)),
+		make([]int64, len(times)),
+		make([]int64, len(times)),
+	}
+
+	captures := make([]position.Capture, len(times))
+	for i := range times {
+		for axis := range axes {
+			residual, err := binary.ReadVarint(streamData)
+			if err != nil {
+				return nil, err
+			}
+
+			value := predict(axes[axis], i, predictVelocity) + residual
+			if value > maxQuantized || value < -maxQuantized {
+				return nil, errors.New("delta encoded position out of range")
+			}
+			axes[axis][i] = value
+		}
+
+		captures[i] = position.NewCapture(
+			times[i],
+			float64(axes[0][i])*resolution,
+			float64(axes[1][i])*resolution,
+			float64(axes[2][i])*resolution,
+		)
+	}
+
+	return captures, nil
+}
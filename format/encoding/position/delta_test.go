@@ -0,0 +1,160 @@
+package position_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"math"
+	"testing"
+
+	"github.com/recolude/rap/format"
+	positionCollection "github.com/recolude/rap/format/collection/position"
+	"github.com/recolude/rap/format/encoding"
+	"github.com/recolude/rap/format/encoding/position"
+	"github.com/recolude/rap/format/io"
+	"github.com/recolude/rap/format/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+// buildSmoothCollection walks slowly in a circle far from the origin.
+func buildSmoothCollection(n int) (format.CaptureCollection, []float64) {
+	captures := make([]positionCollection.Capture, n)
+	times := make([]float64, n)
+	for i := range captures {
+		times[i] = float64(i) / 30
+		captures[i] = positionCollection.NewCapture(
+			times[i],
+			5000+math.Cos(times[i]/4)*3,
+			1.7+math.Sin(times[i]*2)*0.05,
+			-2000+math.Sin(times[i]/4)*3,
+		)
+	}
+	return positionCollection.NewCollection("Walk", captures), times
+}
+
+func flateSize(t *testing.T, data []byte) int {
+	compressed := new(bytes.Buffer)
+	writer, err := flate.NewWriter(compressed, flate.BestCompression)
+	assert.NoError(t, err)
+	writer.Write(data)
+	assert.NoError(t, writer.Close())
+	return compressed.Len()
+}
+
+func Test_Delta_RoundTrip(t *testing.T) {
+	tests := map[string]struct {
+		encoder    position.Encoder
+		resolution float64
+	}{
+		"default":          {encoder: position.NewEncoder(position.Delta), resolution: position.DefaultResolution},
+		"velocity":         {encoder: position.NewEncoder(position.DeltaVelocity), resolution: position.DefaultResolution},
+		"centimeter":       {encoder: position.NewDeltaEncoder(0.01, false), resolution: 0.01},
+		"tenth millimeter": {encoder: position.NewDeltaEncoder(0.0001, true), resolution: 0.0001},
+	}
+
+	collectionIn, times := buildSmoothCollection(1000)
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// ACT ============================================================
+			header, streamsData, errEncode := tc.encoder.Encode([]format.CaptureCollection{collectionIn})
+			collectionOut, errDecode := tc.encoder.Decode("Walk", header, streamsData[0], times)
+
+			// ASSERT =========================================================
+			assert.NoError(t, errEncode)
+			assert.NoError(t, errDecode)
+			if assert.Len(t, collectionOut.Captures(), len(times)) {
+				for i, c := range collectionOut.Captures() {
+					expected := collectionIn.Captures()[i].(positionCollection.Capture).Position()
+					actual := c.(positionCollection.Capture).Position()
+					assert.Equal(t, times[i], c.Time())
+					assert.InDelta(t, expected.X(), actual.X(), tc.resolution/2+1e-9)
+					assert.InDelta(t, expected.Y(), actual.Y(), tc.resolution/2+1e-9)
+					assert.InDelta(t, expected.Z(), actual.Z(), tc.resolution/2+1e-9)
+				}
+			}
+		})
+	}
+}
+
+func Test_Delta_CompressesSmoothMotion(t *testing.T) {
+	// ARRANGE ================================================================
+	collectionIn, _ := buildSmoothCollection(1000)
+	encode := func(encoder position.Encoder) []byte {
+		data, err := encoder.EncodeCollection(collectionIn)
+		assert.NoError(t, err)
+		return data
+	}
+
+	// ACT ====================================================================
+	oct48 := flateSize(t, encode(position.NewEncoder(position.Oct48)))
+	raw32 := flateSize(t, encode(position.NewEncoder(position.Raw32)))
+	delta := flateSize(t, encode(position.NewEncoder(position.Delta)))
+	velocity := flateSize(t, encode(position.NewEncoder(position.DeltaVelocity)))
+
+	// ASSERT =================================================================
+	assert.Less(t, delta, oct48)
+	assert.Less(t, delta, raw32)
+	assert.Less(t, velocity, delta)
+}
+
+func Test_Delta_Empty(t *testing.T) {
+	// ARRANGE ================================================================
+	encoder := position.NewEncoder(position.DeltaVelocity)
+
+	// ACT ====================================================================
+	data, errEncode := encoder.EncodeCollection(positionCollection.NewCollection("Empty", nil))
+	collectionOut, errDecode := encoder.Decode("Empty", nil, data, nil)
+
+	// ASSERT =================================================================
+	assert.NoError(t, errEncode)
+	assert.NoError(t, errDecode)
+	assert.Len(t, collectionOut.Captures(), 0)
+}
+
+func Test_Delta_Errors(t *testing.T) {
+	// ARRANGE ================================================================
+	collectionIn := positionCollection.NewCollection("Far", []positionCollection.Capture{
+		positionCollection.NewCapture(0, 1e30, 0, 0),
+	})
+	valid, err := position.NewEncoder(position.Delta).EncodeCollection(positionCollection.NewCollection("Near", []positionCollection.Capture{
+		positionCollection.NewCapture(0, 1, 2, 3),
+	}))
+	assert.NoError(t, err)
+
+	// ACT ====================================================================
+	_, errZero := position.NewDeltaEncoder(0, false).EncodeCollection(collectionIn)
+	_, errRange := position.NewEncoder(position.Delta).EncodeCollection(collectionIn)
+	_, errTruncated := position.NewEncoder(position.Delta).Decode("Near", nil, valid[:len(valid)-1], []float64{0})
+
+	// ASSERT =================================================================
+	assert.EqualError(t, errZero, "delta resolution must be positive, found 0")
+	assert.EqualError(t, errRange, "position value 1e+30 can not be represented at a resolution of 0.001")
+	assert.Error(t, errTruncated)
+}
+
+// octOnlyEncoder stands in for a position encoder from before the delta and
+// chunked octree techniques existed.
+type octOnlyEncoder struct {
+	position.Encoder
+}
+
+func (octOnlyEncoder) Version() uint {
+	return 0
+}
+
+func Test_Delta_OlderReaders(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	collectionIn, _ := buildSmoothCollection(10)
+	_, errWrite := io.NewWriter([]encoding.Encoder{position.NewEncoder(position.Delta)}, false, fileData, io.Raw64).Write(
+		format.NewRecording("", "Walk", []format.CaptureCollection{collectionIn}, nil, metadata.EmptyBlock(), nil, nil),
+	)
+
+	// ACT ====================================================================
+	rec, _, errRead := io.NewReader([]encoding.Encoder{octOnlyEncoder{position.NewEncoder(position.Oct24)}}, fileData).Read()
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	assert.Nil(t, rec)
+	assert.ErrorIs(t, errRead, io.ErrEncoderTooOld)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/recolude/rap/format"
 	"github.com/recolude/rap/format/collection/position"
+	"github.com/recolude/rap/format/encoding/internal/auto"
 )
 
 type StorageTechnique int
@@ -26,17 +27,67 @@ const (
 	// Oct24 stores all values in a oct tree of depth 8, costing 40 bits per
 	// capture (time is stored in 16 bits)
 	Oct24
+
+	// Delta quantizes all values to a fixed resolution and stores the
+	// difference from the previous capture as a varint, costing as little as
+	// 24 bits per capture for slow moving subjects and compressing well
+	Delta
+
+	// DeltaVelocity is Delta with every capture predicted to continue at the
+	// velocity of the two captures before it, costing less for smooth motion
+	DeltaVelocity
+
+	// ChunkedOct24 is Oct24 applied to chunks of the collection, each with
+	// their own bounds, so outliers only cost precision within their chunk
+	ChunkedOct24
+
+	// ChunkedOct48 is Oct48 applied to chunks of the collection, each with
+	// their own bounds, so outliers only cost precision within their chunk
+	ChunkedOct48
 )
 
 type Encoder struct {
 	technique StorageTechnique
+
+	// resolution is what delta techniques quantize values to
+	resolution float64
+
+	// chunkSize is the number of captures within each chunk of a chunked
+	// octree technique, 0 for chunks picked adaptively
+	chunkSize int
+
+	// auto has the technique picked per collection instead, see
+	// NewAutoEncoder
+	auto     bool
+	maxError float64
+	report   auto.Reporter[Selection]
 }
 
+// NewEncoder builds an encoder that stores every collection with the
+// technique provided. Delta techniques quantize to DefaultResolution, use
+// NewDeltaEncoder for anything else.
 func NewEncoder(technique StorageTechnique) Encoder {
-	return Encoder{technique: technique}
+	return Encoder{technique: technique, resolution: DefaultResolution}
+}
+
+// EncodeCollection uses the encoder's technique, resolution and chunk size,
+// unless it's an auto encoder picking a technique for the collection.
+func (p Encoder) EncodeCollection(stream format.CaptureCollection) ([]byte, error) {
+	if !p.auto {
+		return encodeWith(p.technique, p.resolution, p.chunkSize, stream)
+	}
+
+	selection, encoded, err := selectTechnique(stream, p.maxError)
+	if err != nil {
+		return nil, err
+	}
+
+	p.report.Report(selection)
+	return encoded, nil
 }
 
-func (p Encoder) encode(stream format.CaptureCollection) ([]byte, error) {
+// encodeWith encodes a collection with the technique provided.
+func encodeWith(technique StorageTechnique, resolution float64, chunkSize int, stream format.CaptureCollection) ([]byte, error) {
 	streamData := new(bytes.Buffer)
 
 	castedCaptureData := make([]position.Capture, len(stream.Captures()))
@@ -44,9 +95,9 @@ func (p Encoder) encode(stream format.CaptureCollection) ([]byte, error) {
 		castedCaptureData[i] = c.(position.Capture)
 	}
 
-	streamData.WriteByte(byte(p.technique))
+	streamData.WriteByte(byte(technique))
 
-	switch p.technique {
+	switch technique {
 	case Raw64:
 		streamData.Write(encodeRaw64(castedCaptureData))
 		break
@@ -69,6 +120,30 @@ func (p Encoder) encode(stream format.CaptureCollection) ([]byte, error) {
 		}
 		streamData.Write(d)
 		break
+
+	case Delta, DeltaVelocity:
+		d, err := encodeDelta(castedCaptureData, resolution, technique == DeltaVelocity)
+		if err != nil {
+			return nil, err
+		}
+		streamData.Write(d)
+		break
+
+	case ChunkedOct24:
+		d, err := encodeChunkedOct(castedCaptureData, chunkSize, encodeOct24)
+		if err != nil {
+			return nil, err
+		}
+		streamData.Write(d)
+		break
+
+	case ChunkedOct48:
+		d, err := encodeChunkedOct(castedCaptureData, chunkSize, encodeOct48)
+		if err != nil {
+			return nil, err
+		}
+		streamData.Write(d)
+		break
 	}
 
 	return streamData.Bytes(), nil
@@ -78,7 +153,7 @@ func (p Encoder) Encode(streams []format.CaptureCollection) ([]byte, [][]byte, e
 	allStreamData := make([][]byte, len(streams))
 
 	for i, stream := range streams {
-		s, err := p.encode(stream)
+		s, err := p.EncodeCollection(stream)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -126,6 +201,27 @@ func decode(streamName string, data []byte, times []float64) (format.CaptureColl
 			return nil, err
 		}
 		return position.NewCollection(streamName, captures), nil
+
+	case Delta, DeltaVelocity:
+		captures, err := decodeDelta(reader, times, encodingTechnique == DeltaVelocity)
+		if err != nil {
+			return nil, err
+		}
+		return position.NewCollection(streamName, captures), nil
+
+	case ChunkedOct24:
+		captures, err := decodeChunkedOct(reader, times, decodeOct24)
+		if err != nil {
+			return nil, err
+		}
+		return position.NewCollection(streamName, captures), nil
+
+	case ChunkedOct48:
+		captures, err := decodeChunkedOct(reader, times, decodeOct48)
+		if err != nil {
+			return nil, err
+		}
+		return position.NewCollection(streamName, captures), nil
 	}
 
 	return nil, fmt.Errorf("Unknown positional encoding technique: %d", int(encodingTechnique))
@@ -143,6 +239,7 @@ func (p Encoder) Signature() string {
 	return "recolude.position"
 }
 
+// Version 1 added the delta and chunked octree techniques.
 func (p Encoder) Version() uint {
-	return 0
+	return 1
 }
@@ -0,0 +1,147 @@
+package quaternion
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/quaternion"
+)
+
+type StorageTechnique int
+
+const (
+	// Raw64 encodes all values at fullest precision, costing 256 bits per
+	// capture
+	Raw64 StorageTechnique = iota
+
+	// Raw32 encodes all values at 32bit precision, costing 128 bits per
+	// capture
+	Raw32
+
+	// SmallestThree32 normalizes every rotation and stores which component
+	// is largest followed by the other three at 10 bits each, costing 32
+	// bits per capture and staying within a quarter of a degree
+	SmallestThree32
+
+	// SmallestThree48 normalizes every rotation and stores which component
+	// is largest followed by the other three at 15 bits each, costing 48
+	// bits per capture and staying within a few thousandths of a degree
+	SmallestThree48
+)
+
+type Encoder struct {
+	technique StorageTechnique
+}
+
+func NewEncoder(technique StorageTechnique) Encoder {
+	return Encoder{technique: technique}
+}
+
+func (p Encoder) EncodeCollection(stream format.CaptureCollection) ([]byte, error) {
+	streamData := new(bytes.Buffer)
+
+	castedCaptureData := make([]quaternion.Capture, len(stream.Captures()))
+	for i, c := range stream.Captures() {
+		castedCaptureData[i] = c.(quaternion.Capture)
+	}
+
+	streamData.WriteByte(byte(p.technique))
+
+	switch p.technique {
+	case Raw64:
+		streamData.Write(encodeRaw64(castedCaptureData))
+		break
+
+	case Raw32:
+		streamData.Write(encodeRaw32(castedCaptureData))
+		break
+
+	case SmallestThree32:
+		d, err := encodeSmallestThree(castedCaptureData, smallestThree32Bits)
+		if err != nil {
+			return nil, err
+		}
+		streamData.Write(d)
+		break
+
+	case SmallestThree48:
+		d, err := encodeSmallestThree(castedCaptureData, smallestThree48Bits)
+		if err != nil {
+			return nil, err
+		}
+		streamData.Write(d)
+		break
+	}
+
+	return streamData.Bytes(), nil
+}
+
+func (p Encoder) Encode(streams []format.CaptureCollection) ([]byte, [][]byte, error) {
+	allStreamData := make([][]byte, len(streams))
+
+	for i, stream := range streams {
+		s, err := p.EncodeCollection(stream)
+		if err != nil {
+			return nil, nil, err
+		}
+		allStreamData[i] = s
+	}
+
+	return nil, allStreamData, nil
+}
+
+func (p Encoder) Decode(name string, header []byte, streamData []byte, times []float64) (format.CaptureCollection, error) {
+	reader := bytes.NewReader(streamData)
+
+	typeByte, err := reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	encodingTechnique := StorageTechnique(typeByte)
+
+	switch encodingTechnique {
+	case Raw64:
+		captures, err := decodeRaw64(reader, times)
+		if err != nil {
+			return nil, err
+		}
+		return quaternion.NewCollection(name, captures), nil
+
+	case Raw32:
+		captures, err := decodeRaw32(reader, times)
+		if err != nil {
+			return nil, err
+		}
+		return quaternion.NewCollection(name, captures), nil
+
+	case SmallestThree32:
+		captures, err := decodeSmallestThree(reader, times, smallestThree32Bits)
+		if err != nil {
+			return nil, err
+		}
+		return quaternion.NewCollection(name, captures), nil
+
+	case SmallestThree48:
+		captures, err := decodeSmallestThree(reader, times, smallestThree48Bits)
+		if err != nil {
+			return nil, err
+		}
+		return quaternion.NewCollection(name, captures), nil
+	}
+
+	return nil, fmt.Errorf("Unknown quaternion encoding technique: %d", int(encodingTechnique))
+}
+
+func (p Encoder) Accepts(stream format.CaptureCollection) bool {
+	return stream.Signature() == "recolude.quaternion"
+}
+
+func (p Encoder) Signature() string {
+	return "recolude.quaternion"
+}
+
+func (p Encoder) Version() uint {
+	return 0
+}
@@ -0,0 +1,137 @@
+package quaternion_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/recolude/rap/format"
+	quaternionCollection "github.com/recolude/rap/format/collection/quaternion"
+	"github.com/recolude/rap/format/encoding/quaternion"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Quaternion(t *testing.T) {
+	continuousCaptures := make([]quaternionCollection.Capture, 1000)
+	continuousTimes := make([]float64, len(continuousCaptures))
+	curTime := 1.0
+	for i := 0; i < len(continuousCaptures); i++ {
+		continuousTimes[i] = curTime
+		continuousCaptures[i] = quaternionCollection.NewEulerZXYCapture(
+			curTime,
+			(rand.Float64() * 360),
+			(rand.Float64() * 360),
+			(rand.Float64() * 360),
+		)
+		curTime += rand.Float64() * 10.0
+	}
+
+	tests := map[string]struct {
+		captures []quaternionCollection.Capture
+		times    []float64
+	}{
+		"nil rotations": {captures: nil},
+		"0-rotations":   {captures: []quaternionCollection.Capture{}},
+		"1-rotations": {
+			captures: []quaternionCollection.Capture{quaternionCollection.NewCapture(1.2, 0, 0, 0, 1)},
+			times:    []float64{1.2},
+		},
+		"negative largest": {
+			captures: []quaternionCollection.Capture{
+				quaternionCollection.NewCapture(1.2, 0.1, -0.9, 0.3, 0.2),
+				quaternionCollection.NewCapture(1.3, -0.5, -0.5, -0.5, -0.5),
+			},
+			times: []float64{1.2, 1.3},
+		},
+		"1000-rotations": {captures: continuousCaptures, times: continuousTimes},
+	}
+
+	storageTechniques := []struct {
+		displayName    string
+		technique      quaternion.StorageTechnique
+		angleTolerance float64
+	}{
+		{displayName: "Raw64", technique: quaternion.Raw64, angleTolerance: 0.000001},
+		{displayName: "Raw32", technique: quaternion.Raw32, angleTolerance: 0.001},
+		{displayName: "SmallestThree32", technique: quaternion.SmallestThree32, angleTolerance: 0.3},
+		{displayName: "SmallestThree48", technique: quaternion.SmallestThree48, angleTolerance: 0.01},
+	}
+
+	for name, tc := range tests {
+		for _, technique := range storageTechniques {
+			t.Run(fmt.Sprintf("%s/%s", name, technique.displayName), func(t *testing.T) {
+				streamIn := quaternionCollection.NewCollection("Rot", tc.captures)
+				encoder := quaternion.NewEncoder(technique.technique)
+
+				// ACT ============================================================
+				header, streamsData, encodeErr := encoder.Encode([]format.CaptureCollection{streamIn})
+				streamOut, decodeErr := encoder.Decode("Rot", header, streamsData[0], tc.times)
+
+				// ASSERT =========================================================
+				assert.NoError(t, encodeErr)
+				assert.NoError(t, decodeErr)
+				assert.Len(t, header, 0)
+				if assert.NotNil(t, streamOut) && assert.Len(t, streamOut.Captures(), len(tc.captures)) {
+					assert.Equal(t, streamIn.Name(), streamOut.Name())
+					for i, c := range streamOut.Captures() {
+						capture := c.(quaternionCollection.Capture)
+						assert.Equal(t, tc.captures[i].Time(), capture.Time())
+						angle := quaternionCollection.Angle(tc.captures[i].Rotation(), capture.Rotation())
+						if !assert.Less(t, angle, technique.angleTolerance, "[%d] %s != %s", i, tc.captures[i], capture) {
+							break
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+func Test_SmallestThree_Sizes(t *testing.T) {
+	captures := []quaternionCollection.Capture{
+		quaternionCollection.NewCapture(1, 0, 0, 0, 1),
+		quaternionCollection.NewCapture(2, 0.5, 0.5, 0.5, 0.5),
+	}
+
+	tests := map[string]struct {
+		technique quaternion.StorageTechnique
+		size      int
+	}{
+		"Raw64":           {technique: quaternion.Raw64, size: 1 + 2*32},
+		"Raw32":           {technique: quaternion.Raw32, size: 1 + 2*16},
+		"SmallestThree32": {technique: quaternion.SmallestThree32, size: 1 + 2*4},
+		"SmallestThree48": {technique: quaternion.SmallestThree48, size: 1 + 2*6},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// ACT ================================================================
+			data, err := quaternion.NewEncoder(tc.technique).EncodeCollection(quaternionCollection.NewCollection("Rot", captures))
+
+			// ASSERT =============================================================
+			assert.NoError(t, err)
+			assert.Len(t, data, tc.size)
+		})
+	}
+}
+
+func Test_SmallestThree_Errors(t *testing.T) {
+	// ARRANGE ================================================================
+	encoder := quaternion.NewEncoder(quaternion.SmallestThree48)
+	valid, err := encoder.EncodeCollection(quaternionCollection.NewCollection("Rot", []quaternionCollection.Capture{
+		quaternionCollection.NewCapture(1, 0, 0, 0, 1),
+	}))
+	assert.NoError(t, err)
+
+	// ACT ====================================================================
+	_, errZero := encoder.EncodeCollection(quaternionCollection.NewCollection("Rot", []quaternionCollection.Capture{
+		quaternionCollection.NewCapture(1, 0, 0, 0, 0),
+	}))
+	_, errTruncated := encoder.Decode("Rot", nil, valid[:len(valid)-1], []float64{1})
+	_, errTechnique := encoder.Decode("Rot", nil, []byte{99}, nil)
+
+	// ASSERT =================================================================
+	assert.EqualError(t, errZero, "quaternion at 1 can not be normalized: [1.00] Quaternion - 0.00, 0.00, 0.00, 0.00")
+	assert.Error(t, errTruncated)
+	assert.EqualError(t, errTechnique, "Unknown quaternion encoding technique: 99")
+}
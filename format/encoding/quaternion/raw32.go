@@ -0,0 +1,43 @@
+package quaternion
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/recolude/rap/format/collection/quaternion"
+)
+
+func encodeRaw32(captures []quaternion.Capture) []byte {
+	streamData := new(bytes.Buffer)
+	for _, capture := range captures {
+		rotation := capture.Rotation()
+		binary.Write(streamData, binary.LittleEndian, [4]float32{
+			float32(rotation.X()),
+			float32(rotation.Y()),
+			float32(rotation.Z()),
+			float32(rotation.W()),
+		})
+	}
+	return streamData.Bytes()
+}
+
+func decodeRaw32(streamData *bytes.Reader, times []float64) ([]quaternion.Capture, error) {
+	var components [4]float32
+
+	captures := make([]quaternion.Capture, len(times))
+	for i := 0; i < len(times); i++ {
+		err := binary.Read(streamData, binary.LittleEndian, &components)
+		if err != nil {
+			return nil, err
+		}
+		captures[i] = quaternion.NewCapture(
+			times[i],
+			float64(components[0]),
+			float64(components[1]),
+			float64(components[2]),
+			float64(components[3]),
+		)
+	}
+
+	return captures, nil
+}
@@ -0,0 +1,32 @@
+package quaternion
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/recolude/rap/format/collection/quaternion"
+)
+
+func encodeRaw64(captures []quaternion.Capture) []byte {
+	streamData := new(bytes.Buffer)
+	for _, capture := range captures {
+		rotation := capture.Rotation()
+		binary.Write(streamData, binary.LittleEndian, [4]float64{rotation.X(), rotation.Y(), rotation.Z(), rotation.W()})
+	}
+	return streamData.Bytes()
+}
+
+func decodeRaw64(streamData *bytes.Reader, times []float64) ([]quaternion.Capture, error) {
+	var components [4]float64
+
+	captures := make([]quaternion.Capture, len(times))
+	for i := 0; i < len(times); i++ {
+		err := binary.Read(streamData, binary.LittleEndian, &components)
+		if err != nil {
+			return nil, err
+		}
+		captures[i] = quaternion.NewCapture(times[i], components[0], components[1], components[2], components[3])
+	}
+
+	return captures, nil
+}
@@ -0,0 +1,114 @@
+package quaternion
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/recolude/rap/format/collection/quaternion"
+)
+
+// Smallest three techniques rely on a normalized quaternion being recoverable
+// from any three of it's components, and on q and -q describing the same
+// rotation. The largest component is dropped after flipping the quaternion so
+// it's positive, leaving three components that must fall within ±1/√2. Each
+// capture is packed little endian into a single integer:
+//
+//   [2 bits]    index of the dropped component
+//   [n bits]... the three remaining components, first to last
+
+const (
+	smallestThree32Bits = 10
+	smallestThree48Bits = 15
+)
+
+// smallestThreeRange is the largest magnitude any of the smallest three
+// components of a normalized quaternion can have.
+var smallestThreeRange = 1 / math.Sqrt2
+
+// smallestThreeBytes is the number of bytes a capture packs into.
+func smallestThreeBytes(bits int) int {
+	return (2 + 3*bits + 7) / 8
+}
+
+func encodeSmallestThree(captures []quaternion.Capture, bits int) ([]byte, error) {
+	streamData := new(bytes.Buffer)
+
+	steps := float64(uint64(1)<<bits - 1)
+	buf := make([]byte, 8)
+	for _, capture := range captures {
+		rotation := capture.Rotation()
+		components := [4]float64{rotation.X(), rotation.Y(), rotation.Z(), rotation.W()}
+
+		length := math.Sqrt(rotation.Dot(rotation))
+		if !(length > 0) || math.IsInf(length, 1) {
+			return nil, fmt.Errorf("quaternion at %g can not be normalized: %s", capture.Time(), capture)
+		}
+
+		largest := 0
+		for i := range components {
+			if math.Abs(components[i]) > math.Abs(components[largest]) {
+				largest = i
+			}
+		}
+
+		// q and -q are the same rotation, so the dropped component is always
+		// kept positive
+		if components[largest] < 0 {
+			length = -length
+		}
+
+		packed := uint64(largest)
+		for i, component := range components {
+			if i == largest {
+				continue
+			}
+
+			normalized := (component/length + smallestThreeRange) / (2 * smallestThreeRange)
+			quantized := math.Round(math.Max(0, math.Min(1, normalized)) * steps)
+			packed = packed<<bits | uint64(quantized)
+		}
+
+		binary.LittleEndian.PutUint64(buf, packed)
+		streamData.Write(buf[:smallestThreeBytes(bits)])
+	}
+
+	return streamData.Bytes(), nil
+}
+
+func decodeSmallestThree(streamData *bytes.Reader, times []float64, bits int) ([]quaternion.Capture, error) {
+	steps := float64(uint64(1)<<bits - 1)
+	mask := uint64(1)<<bits - 1
+	buf := make([]byte, 8)
+
+	captures := make([]quaternion.Capture, len(times))
+	for i := range times {
+		_, err := io.ReadFull(streamData, buf[:smallestThreeBytes(bits)])
+		if err != nil {
+			return nil, err
+		}
+		packed := binary.LittleEndian.Uint64(buf)
+
+		var components [4]float64
+		largest := int(packed >> (3 * bits) & 3)
+		remaining := 1.0
+		shift := 3 * bits
+		for component := range components {
+			if component == largest {
+				continue
+			}
+
+			shift -= bits
+			quantized := float64(packed >> shift & mask)
+			components[component] = quantized/steps*2*smallestThreeRange - smallestThreeRange
+			remaining -= components[component] * components[component]
+		}
+		components[largest] = math.Sqrt(math.Max(0, remaining))
+
+		captures[i] = quaternion.NewCapture(times[i], components[0], components[1], components[2], components[3])
+	}
+
+	return captures, nil
+}
@@ -0,0 +1,121 @@
+package encoding
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	registryMutex sync.RWMutex
+
+	// registry holds every registered version of an encoder by signature,
+	// sorted from oldest to newest version
+	registry = make(map[string][]Encoder)
+
+	// registrationOrder keeps the order signatures were first registered in
+	// so resolving encoders is deterministic
+	registrationOrder []string
+)
+
+// Register makes an encoder available to everything that resolves encoders
+// through the registry, like io.Load and io.NewRecoludeWriter. Multiple
+// versions of an encoder with the same signature can be registered, with the
+// newest version used when writing. Registering an encoder with the same
+// signature and version as one already registered replaces it.
+func Register(encoder Encoder) {
+	if encoder == nil {
+		panic("can not register nil encoder")
+	}
+
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	signature := encoder.Signature()
+	versions, ok := registry[signature]
+	if !ok {
+		registrationOrder = append(registrationOrder, signature)
+	}
+
+	for i, registered := range versions {
+		if registered.Version() == encoder.Version() {
+			versions[i] = encoder
+			return
+		}
+	}
+
+	versions = append(versions, encoder)
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].Version() < versions[j].Version()
+	})
+	registry[signature] = versions
+}
+
+// Lookup finds the newest registered encoder with the signature provided,
+// so long as it's version is at least minVersion.
+func Lookup(signature string, minVersion uint) (Encoder, error) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	versions, ok := registry[signature]
+	if !ok {
+		return nil, fmt.Errorf("no registered encoder has signature %s", signature)
+	}
+
+	newest := versions[len(versions)-1]
+	if newest.Version() < minVersion {
+		return nil, fmt.Errorf("registered encoder (%s) version is behind what is requested: %d < %d", signature, newest.Version(), minVersion)
+	}
+
+	return newest, nil
+}
+
+// Registered returns the newest version of every registered encoder, in the
+// order they were first registered.
+func Registered() []Encoder {
+	return RegisteredWith()
+}
+
+// RegisteredWith returns the newest version of every registered encoder,
+// substituting in any of the overrides provided that share a signature with
+// a registered encoder. Overrides with signatures not found in the registry
+// are appended to the end.
+func RegisteredWith(overrides ...Encoder) []Encoder {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	encoders := make([]Encoder, 0, len(registrationOrder)+len(overrides))
+	used := make([]bool, len(overrides))
+	for _, signature := range registrationOrder {
+		versions := registry[signature]
+		encoder := versions[len(versions)-1]
+		for i, override := range overrides {
+			if override.Signature() == signature {
+				encoder = override
+				used[i] = true
+			}
+		}
+		encoders = append(encoders, encoder)
+	}
+
+	for i, override := range overrides {
+		if !used[i] {
+			encoders = append(encoders, override)
+		}
+	}
+
+	return encoders
+}
+
+// RegisteredVersions returns every registered version of every registered
+// encoder, for reading recordings written with older versions of an encoder.
+func RegisteredVersions() []Encoder {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	encoders := make([]Encoder, 0)
+	for _, signature := range registrationOrder {
+		encoders = append(encoders, registry[signature]...)
+	}
+	return encoders
+}
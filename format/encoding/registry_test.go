@@ -0,0 +1,88 @@
+package encoding_test
+
+import (
+	"testing"
+
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/encoding"
+	"github.com/recolude/rap/format/encoding/position"
+	"github.com/stretchr/testify/assert"
+)
+
+type versionedEncoder struct {
+	signature string
+	version   uint
+}
+
+func (v versionedEncoder) Accepts(format.CaptureCollection) bool { return false }
+
+func (v versionedEncoder) Decode(string, []byte, []byte, []float64) (format.CaptureCollection, error) {
+	return nil, nil
+}
+
+func (v versionedEncoder) Encode([]format.CaptureCollection) ([]byte, [][]byte, error) {
+	return nil, nil, nil
+}
+
+func (v versionedEncoder) Version() uint { return v.version }
+
+func (v versionedEncoder) Signature() string { return v.signature }
+
+func Test_Registry_BuiltInsRegisteredByDefault(t *testing.T) {
+	signatures := make([]string, 0)
+	for _, encoder := range encoding.Registered() {
+		signatures = append(signatures, encoder.Signature())
+	}
+
+	assert.Subset(t, signatures, []string{
+		"recolude.event",
+		"recolude.position",
+		"recolude.euler",
+		"recolude.enum",
+		"recolude.float",
+		"recolude.quaternion",
+		"recolude.vector2",
+		"recolude.transform",
+		"recolude.pose",
+	})
+}
+
+func Test_Registry_ResolvesNewestVersion(t *testing.T) {
+	// ARRANGE ================================================================
+	encoding.Register(versionedEncoder{signature: "test.registry.versions", version: 2})
+	encoding.Register(versionedEncoder{signature: "test.registry.versions", version: 1})
+
+	// ACT ====================================================================
+	newest, errNewest := encoding.Lookup("test.registry.versions", 0)
+	_, errTooOld := encoding.Lookup("test.registry.versions", 3)
+	_, errUnknown := encoding.Lookup("test.registry.missing", 0)
+
+	versions := make([]uint, 0)
+	for _, encoder := range encoding.RegisteredVersions() {
+		if encoder.Signature() == "test.registry.versions" {
+			versions = append(versions, encoder.Version())
+		}
+	}
+
+	// ASSERT =================================================================
+	assert.NoError(t, errNewest)
+	assert.Equal(t, uint(2), newest.Version())
+	assert.EqualError(t, errTooOld, "registered encoder (test.registry.versions) version is behind what is requested: 2 < 3")
+	assert.EqualError(t, errUnknown, "no registered encoder has signature test.registry.missing")
+	assert.Equal(t, []uint{1, 2}, versions)
+}
+
+func Test_Registry_RegisteredWithOverrides(t *testing.T) {
+	// ARRANGE ================================================================
+	override := position.NewEncoder(position.Raw64)
+	extra := versionedEncoder{signature: "test.registry.unregistered"}
+
+	// ACT ====================================================================
+	encoders := encoding.RegisteredWith(override, extra)
+
+	// ASSERT =================================================================
+	assert.Len(t, encoders, len(encoding.Registered())+1)
+	assert.Contains(t, encoders, override)
+	assert.NotContains(t, encoding.Registered(), override)
+	assert.Equal(t, extra, encoders[len(encoders)-1])
+}
@@ -0,0 +1,176 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/euler"
+	"github.com/recolude/rap/format/collection/position"
+	"github.com/recolude/rap/format/collection/transform"
+	eulerEncoding "github.com/recolude/rap/format/encoding/euler"
+	positionEncoding "github.com/recolude/rap/format/encoding/position"
+)
+
+// Every collection is split into it's position, rotation and scale, each
+// encoded exactly as the position and euler encoders would encode them on
+// their own, sharing the collection's single time track:
+//
+//   [uvarint] length of the position stream
+//   [bytes]   position stream
+//   [uvarint] length of the rotation stream
+//   [bytes]   rotation stream
+//   [bytes]   scale stream, encoded as positions
+
+type Encoder struct {
+	position positionEncoding.Encoder
+	rotation eulerEncoding.Encoder
+	scale    positionEncoding.Encoder
+}
+
+// NewEncoder builds an encoder that stores positions, rotations and scales
+// with the techniques provided.
+func NewEncoder(positionTechnique positionEncoding.StorageTechnique, rotationTechnique eulerEncoding.StorageTechnique, scaleTechnique positionEncoding.StorageTechnique) Encoder {
+	return NewEncoderWith(
+		positionEncoding.NewEncoder(positionTechnique),
+		eulerEncoding.NewEncoder(rotationTechnique),
+		positionEncoding.NewEncoder(scaleTechnique),
+	)
+}
+
+// NewEncoderWith builds an encoder that stores positions, rotations and
+// scales with the encoders provided, allowing for things like auto encoders
+// or delta resolutions to be used per component.
+func NewEncoderWith(positionEncoder positionEncoding.Encoder, rotationEncoder eulerEncoding.Encoder, scaleEncoder positionEncoding.Encoder) Encoder {
+	return Encoder{
+		position: positionEncoder,
+		rotation: rotationEncoder,
+		scale:    scaleEncoder,
+	}
+}
+
+// EncodeCollection splits the collection into it's positions, rotations and
+// scales, encoding each one after the other.
+func (p Encoder) EncodeCollection(stream format.CaptureCollection) ([]byte, error) {
+	castedCaptureData := make([]transform.Capture, len(stream.Captures()))
+	for i, c := range stream.Captures() {
+		castedCaptureData[i] = c.(transform.Capture)
+	}
+
+	order := euler.ZXY
+	if ordered, ok := stream.(interface{ Order() euler.RotationOrder }); ok {
+		order = ordered.Order()
+	}
+
+	positions, rotations, scales := transform.NewCollectionWithOrder(stream.Name(), order, castedCaptureData).Split(stream.Name(), stream.Name(), stream.Name())
+
+	positionData, err := p.position.EncodeCollection(positions)
+	if err != nil {
+		return nil, err
+	}
+
+	rotationData, err := p.rotation.EncodeCollection(rotations)
+	if err != nil {
+		return nil, err
+	}
+
+	scaleData, err := p.scale.EncodeCollection(scales)
+	if err != nil {
+		return nil, err
+	}
+
+	streamData := new(bytes.Buffer)
+	buf := make([]byte, binary.MaxVarintLen64)
+
+	n := binary.PutUvarint(buf, uint64(len(positionData)))
+	streamData.Write(buf[:n])
+	streamData.Write(positionData)
+
+	n = binary.PutUvarint(buf, uint64(len(rotationData)))
+	streamData.Write(buf[:n])
+	streamData.Write(rotationData)
+
+	streamData.Write(scaleData)
+
+	return streamData.Bytes(), nil
+}
+
+func (p Encoder) Encode(streams []format.CaptureCollection) ([]byte, [][]byte, error) {
+	allStreamData := make([][]byte, len(streams))
+
+	for i, stream := range streams {
+		s, err := p.EncodeCollection(stream)
+		if err != nil {
+			return nil, nil, err
+		}
+		allStreamData[i] = s
+	}
+
+	return nil, allStreamData, nil
+}
+
+// readSection reads a length prefixed section of the stream.
+func readSection(reader *bytes.Reader, section string) ([]byte, error) {
+	size, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if size > uint64(reader.Len()) {
+		return nil, fmt.Errorf("transform %s stream of %d bytes exceeds the %d bytes remaining", section, size, reader.Len())
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (p Encoder) Decode(name string, header []byte, streamData []byte, times []float64) (format.CaptureCollection, error) {
+	reader := bytes.NewReader(streamData)
+
+	positionData, err := readSection(reader, "position")
+	if err != nil {
+		return nil, err
+	}
+
+	rotationData, err := readSection(reader, "rotation")
+	if err != nil {
+		return nil, err
+	}
+
+	scaleData := make([]byte, reader.Len())
+	reader.Read(scaleData)
+
+	positions, err := p.position.Decode(name, nil, positionData, times)
+	if err != nil {
+		return nil, err
+	}
+
+	rotations, err := p.rotation.Decode(name, nil, rotationData, times)
+	if err != nil {
+		return nil, err
+	}
+
+	scales, err := p.scale.Decode(name, nil, scaleData, times)
+	if err != nil {
+		return nil, err
+	}
+
+	return transform.Join(name, positions.(position.Collection), rotations.(euler.Collection), scales.(position.Collection))
+}
+
+func (p Encoder) Accepts(stream format.CaptureCollection) bool {
+	return stream.Signature() == "recolude.transform"
+}
+
+func (p Encoder) Signature() string {
+	return "recolude.transform"
+}
+
+func (p Encoder) Version() uint {
+	return 0
+}
@@ -0,0 +1,143 @@
+package transform_test
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/euler"
+	transformCollection "github.com/recolude/rap/format/collection/transform"
+	"github.com/recolude/rap/format/encoding"
+	eulerEncoding "github.com/recolude/rap/format/encoding/euler"
+	"github.com/recolude/rap/format/encoding/position"
+	"github.com/recolude/rap/format/encoding/transform"
+	"github.com/recolude/rap/format/io"
+	"github.com/recolude/rap/format/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+// buildWalk walks an object in a circle, spinning and pulsing it's scale as
+// it goes.
+func buildWalk(n int) ([]transformCollection.Capture, []float64) {
+	captures := make([]transformCollection.Capture, n)
+	times := make([]float64, n)
+	for i := range captures {
+		times[i] = float64(i) / 30
+		captures[i] = transformCollection.NewCapture(
+			times[i],
+			vector3.New(math.Cos(times[i])*3, 1.7, math.Sin(times[i])*3),
+			vector3.New(10, math.Mod(times[i]*45, 360), 0),
+			vector3.New(1, 1+math.Sin(times[i])*0.5, 1),
+		)
+	}
+	return captures, times
+}
+
+func Test_Transform(t *testing.T) {
+	walkCaptures, walkTimes := buildWalk(500)
+
+	tests := map[string]struct {
+		captures []transformCollection.Capture
+		times    []float64
+	}{
+		"empty": {captures: []transformCollection.Capture{}},
+		"single": {
+			captures: []transformCollection.Capture{
+				transformCollection.NewCapture(1.2, vector3.New(1., 2., 3.), vector3.New(4., 5., 6.), vector3.New(7., 8., 9.)),
+			},
+			times: []float64{1.2},
+		},
+		"walk": {captures: walkCaptures, times: walkTimes},
+	}
+
+	encoders := []struct {
+		displayName string
+		encoder     transform.Encoder
+		tolerance   float64
+	}{
+		{displayName: "Raw64", encoder: transform.NewEncoder(position.Raw64, eulerEncoding.Raw64, position.Raw64), tolerance: 0},
+		{displayName: "Raw32", encoder: transform.NewEncoder(position.Raw32, eulerEncoding.Raw32, position.Raw32), tolerance: 0.0001},
+		{displayName: "Compact", encoder: transform.NewEncoder(position.Oct48, eulerEncoding.Raw16, position.Delta), tolerance: 0.01},
+		{displayName: "Auto", encoder: transform.NewEncoderWith(position.NewAutoEncoder(0.001, nil), eulerEncoding.NewAutoEncoder(0.01, nil), position.NewAutoEncoder(0.001, nil)), tolerance: 0.01},
+	}
+
+	for name, tc := range tests {
+		for _, e := range encoders {
+			t.Run(fmt.Sprintf("%s/%s", name, e.displayName), func(t *testing.T) {
+				// ARRANGE ====================================================
+				streamIn := transformCollection.NewCollectionWithOrder("Body", euler.XYZ, tc.captures)
+
+				// ACT ========================================================
+				header, streamsData, encodeErr := e.encoder.Encode([]format.CaptureCollection{streamIn})
+				streamOut, decodeErr := e.encoder.Decode("Body", header, streamsData[0], tc.times)
+
+				// ASSERT =====================================================
+				assert.NoError(t, encodeErr)
+				assert.NoError(t, decodeErr)
+				assert.Len(t, header, 0)
+				if assert.NotNil(t, streamOut) && assert.Len(t, streamOut.Captures(), len(tc.captures)) {
+					assert.Equal(t, "Body", streamOut.Name())
+					assert.Equal(t, "recolude.transform", streamOut.Signature())
+					assert.Equal(t, euler.XYZ, streamOut.(transformCollection.Collection).Order())
+					for i, c := range streamOut.Captures() {
+						actual := c.(transformCollection.Capture)
+						assert.Equal(t, tc.captures[i].Time(), c.Time())
+						if !assert.InDelta(t, 0, tc.captures[i].Position().Distance(actual.Position()), e.tolerance, "[%d] %s != %s", i, tc.captures[i], c) ||
+							!assert.InDelta(t, 0, tc.captures[i].Rotation().Distance(actual.Rotation()), e.tolerance, "[%d] %s != %s", i, tc.captures[i], c) ||
+							!assert.InDelta(t, 0, tc.captures[i].Scale().Distance(actual.Scale()), e.tolerance, "[%d] %s != %s", i, tc.captures[i], c) {
+							break
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+func Test_Transform_SharesTimeTrack(t *testing.T) {
+	// ARRANGE ================================================================
+	captures, _ := buildWalk(500)
+	joined := transformCollection.NewCollection("Body", captures)
+	positions, rotations, scales := joined.Split("Position", "Rotation", "Scale")
+
+	write := func(collections []format.CaptureCollection) int {
+		out := new(bytes.Buffer)
+		writer := io.NewWriter(encoding.Registered(), false, out, io.Raw64)
+		written, err := writer.Write(format.NewRecording("", "Walk", collections, nil, metadata.EmptyBlock(), nil, nil))
+		assert.NoError(t, err)
+		return written
+	}
+
+	// ACT ====================================================================
+	joinedSize := write([]format.CaptureCollection{joined})
+	separateSize := write([]format.CaptureCollection{positions, rotations, scales})
+
+	// ASSERT =================================================================
+	assert.Less(t, joinedSize, separateSize-2*500*8)
+}
+
+func Test_Transform_Errors(t *testing.T) {
+	// ARRANGE ================================================================
+	captures, times := buildWalk(10)
+	encoder := transform.NewEncoder(position.Raw32, eulerEncoding.Raw32, position.Raw32)
+	valid, err := encoder.EncodeCollection(transformCollection.NewCollection("Body", captures))
+	assert.NoError(t, err)
+
+	// ACT ====================================================================
+	_, errEmpty := encoder.Decode("Body", nil, nil, times)
+	_, errTruncated := encoder.Decode("Body", nil, valid[:50], times)
+
+	// 1 byte length and 121 bytes of positions, then 1 byte length before the
+	// rotation's technique
+	badRotation := append([]byte{}, valid...)
+	badRotation[123] = 0x7f
+	_, errBadRotation := encoder.Decode("Body", nil, badRotation, times)
+
+	// ASSERT =================================================================
+	assert.Error(t, errEmpty)
+	assert.EqualError(t, errTruncated, "transform position stream of 121 bytes exceeds the 49 bytes remaining")
+	assert.EqualError(t, errBadRotation, "Unknown euler encoding technique: 127")
+}
@@ -0,0 +1,89 @@
+package vector2
+
+import (
+	vec2 "github.com/EliCDavis/vector/vector2"
+)
+
+type QuadCell int
+
+// ORDER MATTERS: topBit << 1 | rightBit
+const (
+	TopRight QuadCell = iota
+	TopLeft
+	BottomRight
+	BottomLeft
+)
+
+func Vec2ToQuadCells(v, min, max vec2.Float64, cells []QuadCell) {
+	center := min.Add(max).DivByConstant(2.0)
+	crossSection := max.Sub(min)
+	incrementX := crossSection.X() / 4.0
+	incrementY := crossSection.Y() / 4.0
+
+	for cellIndex := 0; cellIndex < len(cells); cellIndex++ {
+		topBit := 0
+		newY := center.Y() + incrementY
+		if v.Y() < center.Y() {
+			topBit = 1
+			newY = center.Y() - incrementY
+		}
+
+		rightBit := 0
+		newX := center.X() + incrementX
+		if v.X() < center.X() {
+			rightBit = 1
+			newX = center.X() - incrementX
+		}
+
+		cells[cellIndex] = QuadCell(topBit<<1 | rightBit)
+		center = vec2.New(newX, newY)
+		incrementX /= 2.0
+		incrementY /= 2.0
+	}
+}
+
+func QuadCellsToVec2(min, max vec2.Float64, cells []QuadCell) vec2.Float64 {
+	center := min.Add(max).DivByConstant(2.0)
+	crossSection := max.Sub(min)
+	incrementX := crossSection.X() / 4.0
+	incrementY := crossSection.Y() / 4.0
+
+	for cellIndex := 0; cellIndex < len(cells); cellIndex++ {
+		newY := center.Y() - incrementY
+		if cells[cellIndex]&0b10 == 0 {
+			newY = center.Y() + incrementY
+		}
+
+		newX := center.X() - incrementX
+		if cells[cellIndex]&0b01 == 0 {
+			newX = center.X() + incrementX
+		}
+
+		center = vec2.New(newX, newY)
+		incrementX /= 2.0
+		incrementY /= 2.0
+	}
+	return center
+}
+
+// quadCellsToBytes packs every 8 cells into 2 bytes, the first holding each
+// cell's right bit and the second each cell's top bit, which compresses
+// better than packing cells one after another.
+func quadCellsToBytes(cells []QuadCell, buffer []byte) {
+	for i := range buffer {
+		buffer[i] = 0
+	}
+
+	for i, cell := range cells {
+		group := (i / 8) * 2
+		buffer[group] |= (byte(cell) & 0b1) << (i % 8)
+		buffer[group+1] |= ((byte(cell) & 0b10) >> 1) << (i % 8)
+	}
+}
+
+func bytesToQuadCells(cells []QuadCell, buffer []byte) {
+	for i := range cells {
+		group := (i / 8) * 2
+		cells[i] = QuadCell((buffer[group]>>(i%8))&0b1 | ((buffer[group+1]>>(i%8))&0b1)<<1)
+	}
+}
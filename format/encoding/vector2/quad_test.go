@@ -0,0 +1,53 @@
+package vector2_test
+
+import (
+	"testing"
+
+	vec2 "github.com/EliCDavis/vector/vector2"
+	"github.com/recolude/rap/format/encoding/vector2"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Vec2ToQuadCells(t *testing.T) {
+	cells := make([]vector2.QuadCell, 1)
+
+	start := vec2.Zero[float64]()
+	end := vec2.One[float64]()
+
+	tests := map[string]struct {
+		x    float64
+		y    float64
+		cell vector2.QuadCell
+	}{
+		"top right":    {x: 0.75, y: 0.75, cell: vector2.TopRight},
+		"top left":     {x: 0.25, y: 0.75, cell: vector2.TopLeft},
+		"bottom right": {x: 0.75, y: 0.25, cell: vector2.BottomRight},
+		"bottom left":  {x: 0.25, y: 0.25, cell: vector2.BottomLeft},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			vector2.Vec2ToQuadCells(vec2.New(tc.x, tc.y), start, end, cells)
+
+			v := vector2.QuadCellsToVec2(start, end, cells)
+
+			assert.Equal(t, tc.cell, cells[0])
+			assert.InDelta(t, tc.x, v.X(), 0.001)
+			assert.InDelta(t, tc.y, v.Y(), 0.001)
+		})
+	}
+}
+
+func Test_QuadCells_Precision(t *testing.T) {
+	cells := make([]vector2.QuadCell, 16)
+	min := vec2.New(-10., -5)
+	max := vec2.New(10., 5)
+
+	for _, v := range []vec2.Float64{vec2.New(3.3, -4.4), vec2.New(-9.9, 4.9), vec2.New(0., 0)} {
+		vector2.Vec2ToQuadCells(v, min, max, cells)
+		back := vector2.QuadCellsToVec2(min, max, cells)
+
+		assert.InDelta(t, v.X(), back.X(), 20./(1<<16))
+		assert.InDelta(t, v.Y(), back.Y(), 10./(1<<16))
+	}
+}
@@ -0,0 +1,111 @@
+package vector2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+
+	vec2 "github.com/EliCDavis/vector/vector2"
+	"github.com/recolude/rap/format/collection/vector2"
+)
+
+// Quad tree techniques mirror the oct tree techniques of positions, storing
+// the bounds of every change between consecutive captures followed by the
+// starting value, and then every change as a path through a quad tree of
+// those bounds:
+//
+//   [float32 x 2] minimum change
+//   [float32 x 2] maximum change
+//   [float32 x 2] starting value
+//   [bytes]...    quad cells of every capture after the first
+//
+// A single capture is stored as it's value alone.
+
+func encodeQuadTree(captures []vector2.Capture, depth int) []byte {
+	collectionData := new(bytes.Buffer)
+
+	if len(captures) == 0 {
+		return collectionData.Bytes()
+	}
+
+	if len(captures) == 1 {
+		binary.Write(collectionData, binary.LittleEndian, [2]float32{float32(captures[0].Value().X()), float32(captures[0].Value().Y())})
+		return collectionData.Bytes()
+	}
+
+	min := vec2.New(math.Inf(1), math.Inf(1))
+	max := vec2.New(math.Inf(-1), math.Inf(-1))
+	for i := 1; i < len(captures); i++ {
+		change := captures[i].Value().Sub(captures[i-1].Value())
+		min = vec2.Min(min, change)
+		max = vec2.Max(max, change)
+	}
+
+	binary.Write(collectionData, binary.LittleEndian, [6]float32{
+		float32(min.X()), float32(min.Y()),
+		float32(max.X()), float32(max.Y()),
+		float32(captures[0].Value().X()), float32(captures[0].Value().Y()),
+	})
+
+	// Decoding works from the bounds and start as they were written
+	min = vec2.New(float64(float32(min.X())), float64(float32(min.Y())))
+	max = vec2.New(float64(float32(max.X())), float64(float32(max.Y())))
+	quantized := vec2.New(float64(float32(captures[0].Value().X())), float64(float32(captures[0].Value().Y())))
+
+	cells := make([]QuadCell, depth)
+	cellBytes := make([]byte, depth/4)
+	for _, capture := range captures[1:] {
+		Vec2ToQuadCells(capture.Value().Sub(quantized), min, max, cells)
+		quadCellsToBytes(cells, cellBytes)
+		collectionData.Write(cellBytes)
+
+		// Read back quantized value to fix drifting
+		quantized = quantized.Add(QuadCellsToVec2(min, max, cells))
+	}
+
+	return collectionData.Bytes()
+}
+
+func decodeQuadTree(collectionData *bytes.Reader, times []float64, depth int) ([]vector2.Capture, error) {
+	if len(times) == 0 {
+		return make([]vector2.Capture, 0), nil
+	}
+
+	if len(times) == 1 {
+		var value [2]float32
+		err := binary.Read(collectionData, binary.LittleEndian, &value)
+		if err != nil {
+			return nil, err
+		}
+		return []vector2.Capture{vector2.NewCapture(times[0], float64(value[0]), float64(value[1]))}, nil
+	}
+
+	var header [6]float32
+	err := binary.Read(collectionData, binary.LittleEndian, &header)
+	if err != nil {
+		return nil, err
+	}
+
+	min := vec2.New(float64(header[0]), float64(header[1]))
+	max := vec2.New(float64(header[2]), float64(header[3]))
+	current := vec2.New(float64(header[4]), float64(header[5]))
+
+	captures := make([]vector2.Capture, len(times))
+	captures[0] = vector2.NewCapture(times[0], current.X(), current.Y())
+
+	cells := make([]QuadCell, depth)
+	cellBytes := make([]byte, depth/4)
+	for i := 1; i < len(times); i++ {
+		_, err := io.ReadFull(collectionData, cellBytes)
+		if err != nil {
+			return nil, err
+		}
+
+		bytesToQuadCells(cells, cellBytes)
+		current = current.Add(QuadCellsToVec2(min, max, cells))
+		captures[i] = vector2.NewCapture(times[i], current.X(), current.Y())
+	}
+
+	return captures, nil
+}
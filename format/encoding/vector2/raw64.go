@@ -0,0 +1,31 @@
+package vector2
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/recolude/rap/format/collection/vector2"
+)
+
+func encodeRaw64(captures []vector2.Capture) []byte {
+	streamData := new(bytes.Buffer)
+	for _, capture := range captures {
+		binary.Write(streamData, binary.LittleEndian, [2]float64{capture.Value().X(), capture.Value().Y()})
+	}
+	return streamData.Bytes()
+}
+
+func decodeRaw64(streamData *bytes.Reader, times []float64) ([]vector2.Capture, error) {
+	var components [2]float64
+
+	captures := make([]vector2.Capture, len(times))
+	for i := 0; i < len(times); i++ {
+		err := binary.Read(streamData, binary.LittleEndian, &components)
+		if err != nil {
+			return nil, err
+		}
+		captures[i] = vector2.NewCapture(times[i], components[0], components[1])
+	}
+
+	return captures, nil
+}
@@ -0,0 +1,128 @@
+package vector2
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/vector2"
+)
+
+type StorageTechnique int
+
+const (
+	// Raw64 encodes all values at fullest precision, costing 128 bits per
+	// capture
+	Raw64 StorageTechnique = iota
+
+	// Raw32 encodes all values at 32bit precision, costing 64 bits per
+	// capture
+	Raw32
+
+	// Quad32 stores all values in a quad tree of depth 16, costing 32 bits
+	// per capture
+	Quad32
+
+	// Quad16 stores all values in a quad tree of depth 8, costing 16 bits
+	// per capture
+	Quad16
+)
+
+type Encoder struct {
+	technique StorageTechnique
+}
+
+func NewEncoder(technique StorageTechnique) Encoder {
+	return Encoder{technique: technique}
+}
+
+func (p Encoder) EncodeCollection(stream format.CaptureCollection) ([]byte, error) {
+	streamData := new(bytes.Buffer)
+
+	castedCaptureData := make([]vector2.Capture, len(stream.Captures()))
+	for i, c := range stream.Captures() {
+		castedCaptureData[i] = c.(vector2.Capture)
+	}
+
+	streamData.WriteByte(byte(p.technique))
+
+	switch p.technique {
+	case Raw64:
+		streamData.Write(encodeRaw64(castedCaptureData))
+		break
+
+	case Raw32:
+		streamData.Write(encodeRaw32(castedCaptureData))
+		break
+
+	case Quad32:
+		streamData.Write(encodeQuadTree(castedCaptureData, 16))
+		break
+
+	case Quad16:
+		streamData.Write(encodeQuadTree(castedCaptureData, 8))
+		break
+	}
+
+	return streamData.Bytes(), nil
+}
+
+func (p Encoder) Encode(streams []format.CaptureCollection) ([]byte, [][]byte, error) {
+	allStreamData := make([][]byte, len(streams))
+
+	for i, stream := range streams {
+		s, err := p.EncodeCollection(stream)
+		if err != nil {
+			return nil, nil, err
+		}
+		allStreamData[i] = s
+	}
+
+	return nil, allStreamData, nil
+}
+
+func (p Encoder) Decode(name string, header []byte, streamData []byte, times []float64) (format.CaptureCollection, error) {
+	reader := bytes.NewReader(streamData)
+
+	typeByte, err := reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	encodingTechnique := StorageTechnique(typeByte)
+
+	var captures []vector2.Capture
+	switch encodingTechnique {
+	case Raw64:
+		captures, err = decodeRaw64(reader, times)
+
+	case Raw32:
+		captures, err = decodeRaw32(reader, times)
+
+	case Quad32:
+		captures, err = decodeQuadTree(reader, times, 16)
+
+	case Quad16:
+		captures, err = decodeQuadTree(reader, times, 8)
+
+	default:
+		return nil, fmt.Errorf("Unknown vector2 encoding technique: %d", int(encodingTechnique))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return vector2.NewCollection(name, captures), nil
+}
+
+func (p Encoder) Accepts(stream format.CaptureCollection) bool {
+	return stream.Signature() == "recolude.vector2"
+}
+
+func (p Encoder) Signature() string {
+	return "recolude.vector2"
+}
+
+func (p Encoder) Version() uint {
+	return 0
+}
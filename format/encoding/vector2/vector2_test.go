@@ -0,0 +1,131 @@
+package vector2_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/recolude/rap/format"
+	vector2Collection "github.com/recolude/rap/format/collection/vector2"
+	"github.com/recolude/rap/format/encoding/vector2"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Vector2(t *testing.T) {
+	gazeCaptures := make([]vector2Collection.Capture, 1000)
+	gazeTimes := make([]float64, len(gazeCaptures))
+	for i := range gazeCaptures {
+		gazeTimes[i] = float64(i) / 90
+		gazeCaptures[i] = vector2Collection.NewCapture(gazeTimes[i], 960+math.Sin(gazeTimes[i])*900, 540+math.Cos(gazeTimes[i]*3)*500)
+	}
+
+	tests := map[string]struct {
+		captures []vector2Collection.Capture
+		times    []float64
+	}{
+		"nil":   {captures: nil},
+		"empty": {captures: []vector2Collection.Capture{}},
+		"single": {
+			captures: []vector2Collection.Capture{vector2Collection.NewCapture(1.2, 3, 4)},
+			times:    []float64{1.2},
+		},
+		"pair": {
+			captures: []vector2Collection.Capture{vector2Collection.NewCapture(1.2, 3, 4), vector2Collection.NewCapture(1.3, -5, 6)},
+			times:    []float64{1.2, 1.3},
+		},
+		"gaze": {captures: gazeCaptures, times: gazeTimes},
+	}
+
+	storageTechniques := []struct {
+		displayName string
+		technique   vector2.StorageTechnique
+		tolerance   float64
+	}{
+		{displayName: "Raw64", technique: vector2.Raw64, tolerance: 0},
+		{displayName: "Raw32", technique: vector2.Raw32, tolerance: 0.0001},
+		{displayName: "Quad32", technique: vector2.Quad32, tolerance: 0.001},
+		{displayName: "Quad16", technique: vector2.Quad16, tolerance: 0.5},
+	}
+
+	for name, tc := range tests {
+		for _, technique := range storageTechniques {
+			t.Run(fmt.Sprintf("%s/%s", name, technique.displayName), func(t *testing.T) {
+				streamIn := vector2Collection.NewCollection("Gaze", tc.captures)
+				encoder := vector2.NewEncoder(technique.technique)
+
+				// ACT ============================================================
+				header, streamsData, encodeErr := encoder.Encode([]format.CaptureCollection{streamIn})
+				streamOut, decodeErr := encoder.Decode("Gaze", header, streamsData[0], tc.times)
+
+				// ASSERT =========================================================
+				assert.NoError(t, encodeErr)
+				assert.NoError(t, decodeErr)
+				assert.Len(t, header, 0)
+				if assert.NotNil(t, streamOut) && assert.Len(t, streamOut.Captures(), len(tc.captures)) {
+					assert.Equal(t, "Gaze", streamOut.Name())
+					assert.Equal(t, "recolude.vector2", streamOut.Signature())
+					for i, c := range streamOut.Captures() {
+						expected := tc.captures[i].Value()
+						actual := c.(vector2Collection.Capture).Value()
+						assert.Equal(t, tc.captures[i].Time(), c.Time())
+						if !assert.InDelta(t, expected.X(), actual.X(), technique.tolerance, "[%d] %s != %s", i, tc.captures[i], c) ||
+							!assert.InDelta(t, expected.Y(), actual.Y(), technique.tolerance, "[%d] %s != %s", i, tc.captures[i], c) {
+							break
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+func Test_Vector2_Sizes(t *testing.T) {
+	captures := make([]vector2Collection.Capture, 11)
+	for i := range captures {
+		captures[i] = vector2Collection.NewCapture(float64(i), float64(i), float64(-i))
+	}
+
+	tests := map[string]struct {
+		technique vector2.StorageTechnique
+		size      int
+	}{
+		"Raw64":  {technique: vector2.Raw64, size: 1 + 11*16},
+		"Raw32":  {technique: vector2.Raw32, size: 1 + 11*8},
+		"Quad32": {technique: vector2.Quad32, size: 1 + 24 + 10*4},
+		"Quad16": {technique: vector2.Quad16, size: 1 + 24 + 10*2},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// ACT ================================================================
+			data, err := vector2.NewEncoder(tc.technique).EncodeCollection(vector2Collection.NewCollection("Axis", captures))
+
+			// ASSERT =============================================================
+			assert.NoError(t, err)
+			assert.Len(t, data, tc.size)
+		})
+	}
+}
+
+func Test_Vector2_Errors(t *testing.T) {
+	// ARRANGE ================================================================
+	captures := []vector2Collection.Capture{vector2Collection.NewCapture(1, 2, 3), vector2Collection.NewCapture(2, 4, 5)}
+	times := []float64{1, 2}
+
+	for _, technique := range []vector2.StorageTechnique{vector2.Raw64, vector2.Raw32, vector2.Quad32, vector2.Quad16} {
+		t.Run(fmt.Sprint(technique), func(t *testing.T) {
+			encoder := vector2.NewEncoder(technique)
+			valid, err := encoder.EncodeCollection(vector2Collection.NewCollection("Axis", captures))
+			assert.NoError(t, err)
+
+			// ACT ============================================================
+			_, errTruncated := encoder.Decode("Axis", nil, valid[:len(valid)-1], times)
+
+			// ASSERT =========================================================
+			assert.Error(t, errTruncated)
+		})
+	}
+
+	_, errTechnique := vector2.NewEncoder(vector2.Raw64).Decode("Axis", nil, []byte{42}, nil)
+	assert.EqualError(t, errTechnique, "Unknown vector2 encoding technique: 42")
+}
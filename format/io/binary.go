@@ -2,8 +2,12 @@ package io
 
 import (
 	"bytes"
+	"errors"
 	"io"
+	"os"
+	"sync"
 
+	"github.com/recolude/rap/format"
 	"github.com/recolude/rap/format/metadata"
 )
 
@@ -36,3 +40,273 @@ func (br Binary) Metadata() metadata.Block {
 func (br Binary) Data() io.Reader {
 	return bytes.NewReader(br.data)
 }
+
+// ErrBinaryDataSkipped is returned when reading the data of a binary whose
+// contents were skipped while reading the recording.
+var ErrBinaryDataSkipped = errors.New("binary data was skipped while reading the recording")
+
+// lazyBinary is a binary read from a recording whose data is left where it
+// was found, and only read once requested.
+type lazyBinary struct {
+	name   string
+	size   uint64
+	block  metadata.Block
+	source io.ReaderAt
+	offset int64
+
+	// spill is the temp file backing the binary, nil when the data is read
+	// in place
+	spill *spillFile
+
+	// checksum is what the data is verified against once read, if the
+	// recording was written with checksums
+	checksum *uint32
+}
+
+func (lb lazyBinary) Name() string {
+	return lb.name
+}
+
+func (lb lazyBinary) Size() uint64 {
+	return lb.size
+}
+
+func (lb lazyBinary) Metadata() metadata.Block {
+	return lb.block
+}
+
+// Close removes the temp file backing the binary, if there is one.
+func (lb lazyBinary) Close() error {
+	if lb.spill == nil {
+		return nil
+	}
+	return lb.spill.Close()
+}
+
+func (lb lazyBinary) Data() io.Reader {
+	data := io.NewSectionReader(lb.source, lb.offset, int64(lb.size))
+	if lb.checksum == nil {
+		return data
+	}
+	return &verifyingReader{in: data, hash: newChecksum(), expected: *lb.checksum}
+}
+
+// skippedBinary is a binary whose data was never read.
+type skippedBinary struct {
+	name  string
+	size  uint64
+	block metadata.Block
+}
+
+func (sb skippedBinary) Name() string {
+	return sb.name
+}
+
+func (sb skippedBinary) Size() uint64 {
+	return sb.size
+}
+
+func (sb skippedBinary) Metadata() metadata.Block {
+	return sb.block
+}
+
+func (sb skippedBinary) Data() io.Reader {
+	return failingReader{err: ErrBinaryDataSkipped}
+}
+
+// failingReader errors on every read.
+type failingReader struct {
+	err error
+}
+
+func (fr failingReader) Read(p []byte) (int, error) {
+	return 0, fr.err
+}
+
+// spillFile is a temp file holding binary data that could not be referenced
+// in place. The file is removed once the binaries referring to it are closed.
+type spillFile struct {
+	file  *os.File
+	close sync.Once
+	err   error
+}
+
+func (sf *spillFile) ReadAt(p []byte, off int64) (int, error) {
+	return sf.file.ReadAt(p, off)
+}
+
+// Close removes the temp file, and is safe to call more than once.
+func (sf *spillFile) Close() error {
+	sf.close.Do(func() {
+		sf.err = sf.file.Close()
+		if err := os.Remove(sf.file.Name()); sf.err == nil {
+			sf.err = err
+		}
+	})
+	return sf.err
+}
+
+func newSpillFile(in io.Reader, size uint64) (*spillFile, error) {
+	file, err := os.CreateTemp("", "rap-binary-*")
+	if err != nil {
+		return nil, err
+	}
+
+	sf := &spillFile{file: file}
+	_, err = io.CopyN(file, in, int64(size))
+	if err != nil {
+		sf.Close()
+		return nil, err
+	}
+
+	return sf, nil
+}
+
+// spillFiles keeps track of every temp file spilled while reading a
+// recording, so they can be removed if reading fails part way through.
+type spillFiles struct {
+	mu    sync.Mutex
+	files []*spillFile
+}
+
+func (sf *spillFiles) add(file *spillFile) {
+	if sf == nil {
+		return
+	}
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	sf.files = append(sf.files, file)
+}
+
+func (sf *spillFiles) close() {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	for _, file := range sf.files {
+		file.Close()
+	}
+	sf.files = nil
+}
+
+// withSpillCleanup runs the read provided, removing every temp file spilled
+// along the way should it fail.
+func (r Reader) withSpillCleanup(read func(Reader) (format.Recording, int, error)) (format.Recording, int, error) {
+	if r.options.spills != nil {
+		return read(r)
+	}
+
+	r.options.spills = &spillFiles{}
+	rec, n, err := read(r)
+	if err != nil {
+		r.options.spills.close()
+	}
+	return rec, n, err
+}
+
+// CloseBinaries releases any resources held by the binaries of the recording
+// and all of it's sub-recordings, like the temp files large binaries from
+// compressed recordings are spilled to. The data of closed binaries can no
+// longer be read. Binaries read in place from seekable, uncompressed input
+// hold nothing, and instead need the source reader kept open for as long as
+// they're in use.
+func CloseBinaries(recording format.Recording) error {
+	var firstErr error
+	for _, binary := range recording.Binaries() {
+		if closer, ok := binary.(io.Closer); ok {
+			if err := closer.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	for _, child := range recording.Recordings() {
+		if err := CloseBinaries(child); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// maxInMemoryBinarySize is the largest binary that is held in memory when it
+// can't be referenced in place, rather than being spilled to disk.
+const maxInMemoryBinarySize = 1 << 20
+
+// binarySource describes where the data of binaries being read can be found
+// again later, so they can be read lazily instead of held in memory.
+type binarySource struct {
+	// at is where the data can be referenced in place, nil when the data has
+	// to be copied somewhere instead
+	at io.ReaderAt
+
+	// offset is the position within at the reader is currently at
+	offset func() int64
+}
+
+// readBinaryData reads a single binary's data, deciding where it should live
+// based on where it came from.
+func readBinaryData(in io.Reader, name string, size uint64, block metadata.Block, source binarySource, opts readerOptions) (format.Binary, error) {
+	if opts.skipBinaryData {
+		return skippedBinary{name: name, size: size, block: block}, skip(in, int64(size))
+	}
+
+	if source.at != nil {
+		binary := lazyBinary{name: name, size: size, block: block, source: source.at, offset: source.offset()}
+		return binary, skip(in, int64(size))
+	}
+
+	if size <= maxInMemoryBinarySize {
+		data := make([]byte, size)
+		_, err := io.ReadFull(in, data)
+		if err != nil {
+			return nil, err
+		}
+		return NewBinary(name, data, block), nil
+	}
+
+	spill, err := newSpillFile(in, size)
+	if err != nil {
+		return nil, err
+	}
+	opts.spills.add(spill)
+	return lazyBinary{name: name, size: size, block: block, source: spill, spill: spill}, nil
+}
+
+// skipper is implemented by readers that can move past data without
+// reading it.
+type skipper interface {
+	skip(n int64) error
+}
+
+// skip moves the reader past the next n bytes, avoiding reading them when
+// possible.
+func skip(in io.Reader, n int64) error {
+	if s, ok := in.(skipper); ok {
+		return s.skip(n)
+	}
+
+	read, err := io.CopyN(io.Discard, in, n)
+	if err == io.EOF && read < n {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// seekSkipper skips data by seeking past it.
+type seekSkipper struct {
+	io.ReadSeeker
+	size int64
+}
+
+func (s seekSkipper) skip(n int64) error {
+	current, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	if current+n > s.size {
+		s.Seek(0, io.SeekEnd)
+		return io.ErrUnexpectedEOF
+	}
+
+	_, err = s.Seek(n, io.SeekCurrent)
+	return err
+}
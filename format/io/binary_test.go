@@ -0,0 +1,189 @@
+package io_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/encoding"
+	eventEncoding "github.com/recolude/rap/format/encoding/event"
+	"github.com/recolude/rap/format/io"
+	"github.com/recolude/rap/format/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildBinaryTestRecording(data []byte) format.Recording {
+	return format.NewRecording(
+		"root",
+		"Root",
+		nil,
+		[]format.Recording{
+			format.NewRecording(
+				"child",
+				"Child",
+				nil,
+				nil,
+				metadata.EmptyBlock(),
+				[]format.Binary{
+					io.NewBinary("child voice", []byte("child data"), metadata.EmptyBlock()),
+				},
+				nil,
+			),
+		},
+		metadata.EmptyBlock(),
+		[]format.Binary{
+			io.NewBinary("voice", data, metadata.NewBlock(map[string]metadata.Property{
+				"codec": metadata.NewStringProperty("opus"),
+			})),
+		},
+		nil,
+	)
+}
+
+func readBinaryData(t *testing.T, binary format.Binary) []byte {
+	data, err := ioutil.ReadAll(binary.Data())
+	assert.NoError(t, err)
+	return data
+}
+
+func Test_Binary_ReadsLazilyFromSeekableInput(t *testing.T) {
+	for name, newWriter := range map[string]func([]encoding.Encoder, bool, *bytes.Buffer, io.TimeStorageTechnique) io.Writer{
+		"v2": func(e []encoding.Encoder, c bool, b *bytes.Buffer, t io.TimeStorageTechnique) io.Writer {
+			return io.NewWriter(e, c, b, t)
+		},
+		"v3": func(e []encoding.Encoder, c bool, b *bytes.Buffer, t io.TimeStorageTechnique) io.Writer {
+			return io.NewIndexedWriter(e, c, b, t)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// ARRANGE ========================================================
+			fileData := new(bytes.Buffer)
+			encoders := []encoding.Encoder{eventEncoding.NewEncoder()}
+			recIn := buildBinaryTestRecording([]byte("hello world"))
+
+			// ACT ============================================================
+			n, errWrite := newWriter(encoders, false, fileData, io.Raw64).Write(recIn)
+			recOut, nOut, errRead := io.NewReader(encoders, bytes.NewReader(fileData.Bytes())).Read()
+
+			// ASSERT =========================================================
+			assert.NoError(t, errWrite)
+			assert.NoError(t, errRead)
+			assert.Equal(t, n, nOut)
+			if assert.Len(t, recOut.Binaries(), 1) && assert.Len(t, recOut.Recordings(), 1) {
+				assert.Equal(t, []byte("hello world"), readBinaryData(t, recOut.Binaries()[0]))
+				assert.Equal(t, []byte("child data"), readBinaryData(t, recOut.Recordings()[0].Binaries()[0]))
+
+				// Binaries can be read more than once
+				assert.Equal(t, []byte("hello world"), readBinaryData(t, recOut.Binaries()[0]))
+			}
+		})
+	}
+}
+
+func Test_Binary_SpillsLargeBinariesFromCompressedInput(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	encoders := []encoding.Encoder{eventEncoding.NewEncoder()}
+	large := bytes.Repeat([]byte("0123456789"), 200000)
+	recIn := buildBinaryTestRecording(large)
+
+	// ACT ====================================================================
+	n, errWrite := io.NewWriter(encoders, true, fileData, io.Raw64).Write(recIn)
+	recOut, nOut, errRead := io.NewReader(encoders, fileData).Read()
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	assert.NoError(t, errRead)
+	assert.Equal(t, n, nOut)
+	if assert.Len(t, recOut.Binaries(), 1) {
+		assert.Equal(t, uint64(len(large)), recOut.Binaries()[0].Size())
+		assert.Equal(t, large, readBinaryData(t, recOut.Binaries()[0]))
+		assert.Equal(t, []byte("child data"), readBinaryData(t, recOut.Recordings()[0].Binaries()[0]))
+	}
+}
+
+func Test_Binary_CloseBinariesRemovesSpilledFiles(t *testing.T) {
+	// ARRANGE ================================================================
+	tempDir := t.TempDir()
+	t.Setenv("TMPDIR", tempDir)
+
+	fileData := new(bytes.Buffer)
+	encoders := []encoding.Encoder{eventEncoding.NewEncoder()}
+	large := bytes.Repeat([]byte("0123456789"), 200000)
+	_, errWrite := io.NewWriter(encoders, true, fileData, io.Raw64).Write(buildBinaryTestRecording(large))
+	recOut, _, errRead := io.NewReader(encoders, fileData).Read()
+	spilled, _ := os.ReadDir(tempDir)
+
+	// ACT ====================================================================
+	errClose := io.CloseBinaries(recOut)
+	errCloseAgain := io.CloseBinaries(recOut)
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	assert.NoError(t, errRead)
+	assert.NoError(t, errClose)
+	assert.NoError(t, errCloseAgain)
+	assert.Len(t, spilled, 1)
+
+	remaining, _ := os.ReadDir(tempDir)
+	assert.Empty(t, remaining)
+
+	_, err := recOut.Binaries()[0].Data().Read(make([]byte, 1))
+	assert.Error(t, err)
+}
+
+func Test_Binary_FailedReadRemovesSpilledFiles(t *testing.T) {
+	// ARRANGE ================================================================
+	tempDir := t.TempDir()
+	t.Setenv("TMPDIR", tempDir)
+
+	fileData := new(bytes.Buffer)
+	encoders := []encoding.Encoder{eventEncoding.NewEncoder()}
+	large := bytes.Repeat([]byte("0123456789"), 200000)
+	_, errWrite := io.NewWriter(encoders, true, fileData, io.Raw64).Write(buildBinaryTestRecording(large))
+	truncated := fileData.Bytes()[:fileData.Len()-8]
+
+	// ACT ====================================================================
+	recOut, _, errRead := io.NewReader(encoders, bytes.NewReader(truncated)).Read()
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	assert.Error(t, errRead)
+	assert.Nil(t, recOut)
+
+	remaining, _ := os.ReadDir(tempDir)
+	assert.Empty(t, remaining)
+}
+
+func Test_Binary_SkipBinaryData(t *testing.T) {
+	for _, compress := range []bool{true, false} {
+		// ARRANGE ============================================================
+		fileData := new(bytes.Buffer)
+		encoders := []encoding.Encoder{eventEncoding.NewEncoder()}
+		recIn := buildBinaryTestRecording([]byte("hello world"))
+
+		// ACT ================================================================
+		n, errWrite := io.NewWriter(encoders, compress, fileData, io.Raw64).Write(recIn)
+		recOut, nOut, errRead := io.NewReader(encoders, fileData, io.SkipBinaryData()).Read()
+
+		// ASSERT =============================================================
+		assert.NoError(t, errWrite)
+		assert.NoError(t, errRead)
+		assert.Equal(t, n, nOut)
+		if assert.Len(t, recOut.Binaries(), 1) == false {
+			continue
+		}
+
+		binary := recOut.Binaries()[0]
+		assert.Equal(t, "voice", binary.Name())
+		assert.Equal(t, uint64(11), binary.Size())
+		assert.Equal(t, "opus", binary.Metadata().Mapping()["codec"].String())
+
+		_, err := binary.Data().Read(make([]byte, 1))
+		assert.True(t, errors.Is(err, io.ErrBinaryDataSkipped))
+		assert.Equal(t, "child", recOut.Recordings()[0].ID())
+	}
+}
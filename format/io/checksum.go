@@ -0,0 +1,227 @@
+package io
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+
+	"github.com/recolude/rap/format"
+)
+
+// ErrChecksumMismatch is returned when a section of a recording does not
+// match the checksum written alongside it.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+var checksumTable = crc32.MakeTable(crc32.Castagnoli)
+
+func newChecksum() hash.Hash32 {
+	return crc32.New(checksumTable)
+}
+
+// Checksums has the writer include a CRC-32C checksum after the header, the
+// encoder headers, and every section of each recording and binary so damage
+// can be detected and located when reading. Only supported by the v2 layout.
+func Checksums() WriterOption {
+	return func(options *writerOptions) {
+		options.checksums = true
+	}
+}
+
+// checksumWriter keeps a running checksum of everything written through it,
+// which is written out and reset at the end of every section.
+type checksumWriter struct {
+	out  io.Writer
+	hash hash.Hash32
+}
+
+func newChecksumWriter(out io.Writer) *checksumWriter {
+	return &checksumWriter{out: out, hash: newChecksum()}
+}
+
+func (c *checksumWriter) Write(p []byte) (int, error) {
+	n, err := c.out.Write(p)
+	c.hash.Write(p[:n])
+	return n, err
+}
+
+// endSection writes the checksum of everything written since the previous
+// section ended. Does nothing when checksums aren't being written.
+func (c *checksumWriter) endSection(out io.Writer) {
+	if c == nil {
+		return
+	}
+	c.writeSum(out, c.hash.Sum32())
+}
+
+// endBinaryData writes the checksum of a binary's data, which is computed on
+// it's own so the data can be verified without being read in sequence.
+func (c *checksumWriter) endBinaryData(out io.Writer, sum uint32) {
+	if c == nil {
+		return
+	}
+	c.writeSum(out, sum)
+}
+
+func (c *checksumWriter) writeSum(out io.Writer, sum uint32) {
+	buf := make([]byte, crc32.Size)
+	binary.LittleEndian.PutUint32(buf, sum)
+	out.Write(buf)
+	c.hash.Reset()
+}
+
+// checksumError records which collection or binary a checksum mismatch was
+// found in so it can be surfaced through a DecodeError.
+type checksumError struct {
+	collection string
+	binary     string
+}
+
+func (e checksumError) Error() string {
+	return ErrChecksumMismatch.Error()
+}
+
+func (e checksumError) Is(target error) bool {
+	return target == ErrChecksumMismatch
+}
+
+// sectionChecksums verifies the checksums written between the sections of a
+// recording. A nil *sectionChecksums verifies nothing, for recordings written
+// without checksums.
+type sectionChecksums struct {
+	in *countingReader
+
+	// mismatch is called with every section that fails verification, and
+	// returns the error reading should stop with, if any
+	mismatch func(err checksumError) error
+
+	// eager verifies binary data as soon as the binary is read instead of
+	// when the data is eventually requested
+	eager bool
+}
+
+func (s *sectionChecksums) readSum() (uint32, error) {
+	hash := s.in.hash
+	s.in.hash = nil
+	defer func() {
+		hash.Reset()
+		s.in.hash = hash
+	}()
+
+	buf := make([]byte, crc32.Size)
+	_, err := io.ReadFull(s.in, buf)
+	return binary.LittleEndian.Uint32(buf), err
+}
+
+// verify checks the checksum ending the section just read, attributing any
+// damage to the collection or binary named.
+func (s *sectionChecksums) verify(collection, binaryName string) error {
+	if s == nil {
+		return nil
+	}
+
+	actual := s.in.hash.Sum32()
+	expected, err := s.readSum()
+	if err != nil {
+		return err
+	}
+
+	if actual != expected {
+		return s.mismatch(checksumError{collection: collection, binary: binaryName})
+	}
+	return nil
+}
+
+// verifyBinaryData checks the checksum following the data of the binary
+// just read. Binaries whose data hasn't been read yet check it once it is.
+func (s *sectionChecksums) verifyBinaryData(bin format.Binary) (format.Binary, error) {
+	if s == nil {
+		return bin, nil
+	}
+
+	expected, err := s.readSum()
+	if err != nil {
+		return nil, err
+	}
+
+	switch b := bin.(type) {
+	case Binary:
+		if crc32.Checksum(b.data, checksumTable) != expected {
+			return b, s.mismatch(checksumError{binary: b.name})
+		}
+
+	case lazyBinary:
+		b.checksum = &expected
+		if s.eager {
+			_, err := io.Copy(io.Discard, b.Data())
+			if errors.Is(err, ErrChecksumMismatch) {
+				return b, s.mismatch(checksumError{binary: b.name})
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+
+	return bin, nil
+}
+
+// verifyingReader checks the data read through it against a checksum once
+// all of it has been read.
+type verifyingReader struct {
+	in       io.Reader
+	hash     hash.Hash32
+	expected uint32
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	n, err := v.in.Read(p)
+	v.hash.Write(p[:n])
+	if err == io.EOF && v.hash.Sum32() != v.expected {
+		return n, fmt.Errorf("binary data: %w", ErrChecksumMismatch)
+	}
+	return n, err
+}
+
+// checksumMismatch reports a section that failed verification, either
+// collecting it when verifying the entire recording or stopping the read.
+func (d *decoder) checksumMismatch(err checksumError) error {
+	if d.opts.verify == nil {
+		return err
+	}
+
+	var decodeErr *DecodeError
+	if errors.As(d.fail(err), &decodeErr) {
+		d.opts.verify.Damaged = append(d.opts.verify.Damaged, decodeErr)
+	}
+	return nil
+}
+
+// VerifyReport describes the damage found within a recording.
+type VerifyReport struct {
+	// Checksummed is whether or not the recording was written with
+	// checksums. Recordings without them can only be checked for whether or
+	// not they can be read.
+	Checksummed bool
+
+	// Damaged has an error for every section whose checksum did not match.
+	Damaged []*DecodeError
+}
+
+// Verify reads the entire recording, including the data of every binary,
+// reporting all sections whose checksums don't match instead of stopping at
+// the first. An error is returned when the recording is too damaged to keep
+// reading.
+func (r Reader) Verify() (VerifyReport, error) {
+	report := VerifyReport{}
+	r.options.verify = &report
+	r.options.skipBinaryData = false
+	rec, _, err := r.Read()
+	if rec != nil {
+		CloseBinaries(rec)
+	}
+	return report, err
+}
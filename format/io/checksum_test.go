@@ -0,0 +1,131 @@
+package io_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	goio "io"
+	"math"
+	"testing"
+
+	"github.com/recolude/rap/format/io"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Checksums_RoundTrip(t *testing.T) {
+	for _, codec := range []io.CodecID{io.CodecNone, io.CodecFlate, io.CodecZstd} {
+		t.Run(fmt.Sprintf("codec %d", codec), func(t *testing.T) {
+			// ARRANGE ========================================================
+			fileData := new(bytes.Buffer)
+			recIn := buildIndexedTestRecording()
+
+			// ACT ============================================================
+			n, errWrite := io.NewWriter(codecTestEncoders(), false, fileData, io.Raw64, io.Compression(codec), io.Checksums()).Write(recIn)
+			recOut, nOut, errRead := io.NewReader(codecTestEncoders(), bytes.NewReader(fileData.Bytes())).Read()
+			report, errVerify := io.NewReader(codecTestEncoders(), bytes.NewReader(fileData.Bytes())).Verify()
+			manifest, errInspect := io.Inspect(bytes.NewReader(fileData.Bytes()))
+
+			// ASSERT =========================================================
+			assert.NoError(t, errWrite)
+			assert.NoError(t, errRead)
+			assert.NoError(t, errVerify)
+			assert.NoError(t, errInspect)
+			assert.Equal(t, n, nOut)
+			assertRecordingsMatch(t, recIn, recOut, 0)
+			assert.True(t, report.Checksummed)
+			assert.Empty(t, report.Damaged)
+			assert.True(t, manifest.Checksummed)
+			assert.Equal(t, codec, manifest.Codec)
+		})
+	}
+}
+
+func Test_Checksums_RecordingsWithoutChecksumsStillVerify(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	_, err := io.NewWriter(codecTestEncoders(), true, fileData, io.Raw64).Write(buildIndexedTestRecording())
+	assert.NoError(t, err)
+
+	// ACT ====================================================================
+	report, errVerify := io.NewReader(codecTestEncoders(), bytes.NewReader(fileData.Bytes())).Verify()
+	manifest, errInspect := io.Inspect(bytes.NewReader(fileData.Bytes()))
+
+	// ASSERT =================================================================
+	assert.NoError(t, errVerify)
+	assert.NoError(t, errInspect)
+	assert.False(t, report.Checksummed)
+	assert.Empty(t, report.Damaged)
+	assert.False(t, manifest.Checksummed)
+}
+
+// damage flips a byte within the last occurrence of target.
+func damage(t *testing.T, data []byte, target []byte) {
+	i := bytes.LastIndex(data, target)
+	if !assert.GreaterOrEqual(t, i, 0) {
+		return
+	}
+	data[i] ^= 0xFF
+}
+
+func Test_Checksums_LocatesDamage(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	_, err := io.NewWriter(codecTestEncoders(), false, fileData, io.Raw64, io.Checksums()).Write(buildIndexedTestRecording())
+	assert.NoError(t, err)
+
+	damaged := append([]byte{}, fileData.Bytes()...)
+	// Positions of player p1 are the only ones with an x of 10
+	x := make([]byte, 8)
+	binary.LittleEndian.PutUint64(x, math.Float64bits(10))
+	damage(t, damaged, x)
+	damage(t, damaged, []byte("hello p7"))
+
+	// ACT ====================================================================
+	_, _, errRead := io.NewReader(codecTestEncoders(), bytes.NewReader(damaged)).Read()
+	report, errVerify := io.NewReader(codecTestEncoders(), bytes.NewReader(damaged)).Verify()
+
+	// ASSERT =================================================================
+	assert.ErrorIs(t, errRead, io.ErrChecksumMismatch)
+	assert.NoError(t, errVerify)
+	assert.True(t, report.Checksummed)
+	if assert.Len(t, report.Damaged, 2) {
+		assert.Equal(t, []string{"root", "players", "p1"}, report.Damaged[0].Path)
+		assert.Equal(t, "Position", report.Damaged[0].Collection)
+		assert.Empty(t, report.Damaged[0].Binary)
+		assert.ErrorIs(t, report.Damaged[0], io.ErrChecksumMismatch)
+
+		assert.Equal(t, []string{"root", "players", "p7"}, report.Damaged[1].Path)
+		assert.Empty(t, report.Damaged[1].Collection)
+		assert.Equal(t, "voice", report.Damaged[1].Binary)
+		assert.ErrorIs(t, report.Damaged[1], io.ErrChecksumMismatch)
+	}
+}
+
+func Test_Checksums_LazyBinaryDataIsVerifiedWhenRead(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	_, err := io.NewWriter(codecTestEncoders(), false, fileData, io.Raw64, io.Checksums()).Write(buildIndexedTestRecording())
+	assert.NoError(t, err)
+
+	damaged := append([]byte{}, fileData.Bytes()...)
+	damage(t, damaged, []byte("hello p1"))
+
+	// ACT ====================================================================
+	rec, _, errRead := io.NewReader(codecTestEncoders(), bytes.NewReader(damaged)).Read()
+	_, errData := goio.ReadAll(rec.Recordings()[0].Recordings()[0].Binaries()[0].Data())
+
+	// ASSERT =================================================================
+	assert.NoError(t, errRead)
+	assert.ErrorIs(t, errData, io.ErrChecksumMismatch)
+}
+
+func Test_Checksums_RejectedByIndexedWriter(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+
+	// ACT ====================================================================
+	_, err := io.NewIndexedWriter(codecTestEncoders(), false, fileData, io.Raw64, io.Checksums()).Write(buildIndexedTestRecording())
+
+	// ASSERT =================================================================
+	assert.Error(t, err)
+}
@@ -0,0 +1,817 @@
+package io
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/enum"
+	"github.com/recolude/rap/format/collection/euler"
+	"github.com/recolude/rap/format/collection/event"
+	"github.com/recolude/rap/format/collection/float"
+	"github.com/recolude/rap/format/collection/pose"
+	"github.com/recolude/rap/format/collection/position"
+	"github.com/recolude/rap/format/collection/quaternion"
+	"github.com/recolude/rap/format/collection/transform"
+	"github.com/recolude/rap/format/collection/vector2"
+	"github.com/recolude/rap/format/encoding"
+	"github.com/recolude/rap/format/metadata"
+	rapbinary "github.com/recolude/rap/internal/io/binary"
+)
+
+// The chunked (v4) layout is an append only journal meant for live capture
+// sessions, where losing everything on a crash is unacceptable:
+//
+//   [1 byte] version (4)
+//   [chunk]...
+//   [1 byte] end marker, only present once the recording has been finalized
+//
+// Where every chunk looks like:
+//
+//   [1 byte]  chunk marker
+//   [varint]  length of chunk data
+//   [4 bytes] little endian CRC32 of chunk data
+//   [data]    a complete, self contained v2 recording
+//
+// Every chunk only contains the captures and binaries appended since the
+// previous chunk. Reading a chunked recording merges all chunks back
+// together, holding them to a single decompressed size limit.
+
+const (
+	chunkEndMarker byte = 0
+	chunkMarker    byte = 1
+)
+
+// ErrChunkedRecordingNotFinalized is returned when attempting to load a
+// chunked recording that never had Finalize called on it. Use
+// Reader.ReadUnfinalized to recover the data that did make it to disk.
+var ErrChunkedRecordingNotFinalized = errors.New("chunked recording was never finalized")
+
+type chunkNode struct {
+	id          string
+	name        string
+	nameChanged bool
+	metadata    map[string]metadata.Property
+	collections []*pendingCollection
+	binaries    []format.Binary
+	references  []format.BinaryReference
+	children    []*chunkNode
+
+	// Capture-less copy of the last collection seen by name, used to
+	// remember things like enum members across chunks.
+	prototypes map[string]format.CaptureCollection
+}
+
+func newChunkNode(id string) *chunkNode {
+	return &chunkNode{
+		id:         id,
+		name:       id,
+		metadata:   make(map[string]metadata.Property),
+		prototypes: make(map[string]format.CaptureCollection),
+	}
+}
+
+func (n *chunkNode) child(id string) *chunkNode {
+	for _, child := range n.children {
+		if child.id == id {
+			return child
+		}
+	}
+	child := newChunkNode(id)
+	n.children = append(n.children, child)
+	return child
+}
+
+func (n *chunkNode) collectionIndex(name string) int {
+	for i, collection := range n.collections {
+		if collection.first.Name() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// add appends the captures of the collection to the pending collection of
+// the same name, starting a new one if there is none.
+func (n *chunkNode) add(collection format.CaptureCollection) error {
+	index := n.collectionIndex(collection.Name())
+	if index == -1 {
+		n.collections = append(n.collections, newPendingCollection(collection))
+		n.prototypes[collection.Name()] = n.collections[len(n.collections)-1].prototype()
+		return nil
+	}
+
+	err := n.collections[index].add(collection)
+	if err != nil {
+		return err
+	}
+	n.prototypes[collection.Name()] = n.collections[index].prototype()
+	return nil
+}
+
+func (n *chunkNode) empty() bool {
+	if n.nameChanged || len(n.collections) > 0 || len(n.metadata) > 0 || len(n.binaries) > 0 || len(n.references) > 0 {
+		return false
+	}
+	for _, child := range n.children {
+		if !child.empty() {
+			return false
+		}
+	}
+	return true
+}
+
+// toRecording builds a recording out of everything pending in the node,
+// leaving out children that have nothing to contribute.
+func (n *chunkNode) toRecording() (format.Recording, error) {
+	collections := make([]format.CaptureCollection, len(n.collections))
+	for i, pending := range n.collections {
+		collection, err := pending.build()
+		if err != nil {
+			return nil, err
+		}
+		collections[i] = collection
+	}
+
+	children := make([]format.Recording, 0, len(n.children))
+	for _, child := range n.children {
+		if child.empty() {
+			continue
+		}
+
+		childRecording, err := child.toRecording()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, childRecording)
+	}
+
+	return format.NewRecording(
+		n.id,
+		n.name,
+		collections,
+		children,
+		metadata.NewBlock(n.metadata),
+		n.binaries,
+		n.references,
+	), nil
+}
+
+// merge adds everything found within the recording to the node, matching
+// sub-recordings by ID and collections by name.
+func (n *chunkNode) merge(recording format.Recording) error {
+	n.name = recording.Name()
+	for key, val := range recording.Metadata().Mapping() {
+		n.metadata[key] = val
+	}
+
+	for _, collection := range recording.CaptureCollections() {
+		if err := n.add(collection); err != nil {
+			return err
+		}
+	}
+
+	n.binaries = append(n.binaries, recording.Binaries()...)
+	n.references = append(n.references, recording.BinaryReferences()...)
+
+	for _, child := range recording.Recordings() {
+		if err := n.child(child.ID()).merge(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reset clears out everything that has been flushed while keeping the
+// structure of the tree around.
+func (n *chunkNode) reset() {
+	n.nameChanged = false
+	n.collections = nil
+	n.metadata = make(map[string]metadata.Property)
+	n.binaries = nil
+	n.references = nil
+	for _, child := range n.children {
+		child.reset()
+	}
+}
+
+// pendingCollection gathers the captures of every collection appended under
+// the same name, only building the combined collection once it's needed so
+// appending doesn't copy everything appended before it.
+type pendingCollection struct {
+	first format.CaptureCollection
+
+	// captures and members are only gathered once a second collection is
+	// added, with members holding every enum member seen so far
+	merged   bool
+	captures []format.Capture
+	members  []string
+}
+
+func newPendingCollection(collection format.CaptureCollection) *pendingCollection {
+	return &pendingCollection{first: collection}
+}
+
+func (p *pendingCollection) add(collection format.CaptureCollection) error {
+	err := checkMergeable(p.first, collection)
+	if err != nil {
+		return err
+	}
+
+	if !p.merged {
+		p.captures = append(p.captures, p.first.Captures()...)
+		if enumCollection, ok := p.first.(enum.Collection); ok {
+			p.members = append([]string{}, enumCollection.EnumMembers()...)
+		}
+		p.merged = true
+	}
+
+	enumCollection, ok := collection.(enum.Collection)
+	if !ok {
+		p.captures = append(p.captures, collection.Captures()...)
+		return nil
+	}
+
+	// Members of the collection might be ordered differently, remap them
+	remap := make([]int, len(enumCollection.EnumMembers()))
+	for i, member := range enumCollection.EnumMembers() {
+		remap[i] = -1
+		for existingIndex, existing := range p.members {
+			if existing == member {
+				remap[i] = existingIndex
+				break
+			}
+		}
+		if remap[i] == -1 {
+			remap[i] = len(p.members)
+			p.members = append(p.members, member)
+		}
+	}
+
+	for _, c := range collection.Captures() {
+		enumCapture := c.(enum.Capture)
+		value := enumCapture.Value()
+		if value >= 0 && value < len(remap) {
+			value = remap[value]
+		}
+		p.captures = append(p.captures, enum.NewCapture(enumCapture.Time(), value))
+	}
+	return nil
+}
+
+// build creates the collection containing every capture added.
+func (p *pendingCollection) build() (format.CaptureCollection, error) {
+	if !p.merged {
+		return p.first, nil
+	}
+	return buildCollection(p.first, p.members, p.captures)
+}
+
+// prototype is a collection without captures carrying everything needed to
+// build more collections like the pending one, nil if it's of a type that
+// can't be built.
+func (p *pendingCollection) prototype() format.CaptureCollection {
+	members := p.members
+	if enumCollection, ok := p.first.(enum.Collection); ok && !p.merged {
+		members = enumCollection.EnumMembers()
+	}
+
+	prototype, err := buildCollection(p.first, members, nil)
+	if err != nil {
+		return nil
+	}
+	return prototype
+}
+
+// newCollectionFromCapture builds a collection containing the single capture
+// provided, using the prototype for any information the capture itself does
+// not carry.
+func newCollectionFromCapture(name string, capture format.Capture, prototype format.CaptureCollection) (format.CaptureCollection, error) {
+	switch c := capture.(type) {
+	case position.Capture:
+		return position.NewCollection(name, []position.Capture{c}), nil
+
+	case euler.Capture:
+		order := euler.ZXY
+		if eulerPrototype, ok := prototype.(euler.Collection); ok {
+			order = eulerPrototype.Order()
+		}
+		return euler.NewCollectionWithOrder(name, order, []euler.Capture{c}), nil
+
+	case event.Capture:
+		return event.NewCollection(name, []event.Capture{c}), nil
+
+	case float.Capture:
+		return float.NewCollection(name, []float.Capture{c}), nil
+
+	case quaternion.Capture:
+		return quaternion.NewCollection(name, []quaternion.Capture{c}), nil
+
+	case vector2.Capture:
+		return vector2.NewCollection(name, []vector2.Capture{c}), nil
+
+	case transform.Capture:
+		order := euler.ZXY
+		if transformPrototype, ok := prototype.(transform.Collection); ok {
+			order = transformPrototype.Order()
+		}
+		return transform.NewCollectionWithOrder(name, order, []transform.Capture{c}), nil
+
+	case pose.Capture:
+		posePrototype, ok := prototype.(pose.Collection)
+		if !ok {
+			return nil, fmt.Errorf("pose collection %s must be appended with it's skeleton before individual captures", name)
+		}
+		return pose.NewCollectionWithOrder(name, posePrototype.Skeleton(), posePrototype.Order(), []pose.Capture{c}), nil
+
+	case enum.Capture:
+		enumPrototype, ok := prototype.(enum.Collection)
+		if !ok {
+			return nil, fmt.Errorf("enum collection %s must be appended with it's members before individual captures", name)
+		}
+		return enum.NewCollection(name, enumPrototype.EnumMembers(), []enum.Capture{c}), nil
+	}
+
+	return nil, fmt.Errorf("unable to build collection %s for capture type %T", name, capture)
+}
+
+// checkMergeable ensures the captures of b can be appended to those of a.
+func checkMergeable(a, b format.CaptureCollection) error {
+	if a.Signature() != b.Signature() {
+		return fmt.Errorf("can not merge collection %s of type %s with type %s", a.Name(), a.Signature(), b.Signature())
+	}
+
+	switch aCollection := a.(type) {
+	case position.Collection, event.Collection, float.Collection, quaternion.Collection, vector2.Collection:
+		return nil
+
+	case euler.Collection:
+		bCollection, ok := b.(euler.Collection)
+		if !ok || aCollection.Order() != bCollection.Order() {
+			return fmt.Errorf("can not merge euler collection %s with a different rotation order", a.Name())
+		}
+		return nil
+
+	case pose.Collection:
+		bCollection, ok := b.(pose.Collection)
+		if !ok || !aCollection.Skeleton().Equal(bCollection.Skeleton()) || aCollection.Order() != bCollection.Order() {
+			return fmt.Errorf("can not merge pose collection %s with a different skeleton or rotation order", a.Name())
+		}
+		return nil
+
+	case transform.Collection:
+		bCollection, ok := b.(transform.Collection)
+		if !ok || aCollection.Order() != bCollection.Order() {
+			return fmt.Errorf("can not merge transform collection %s with a different rotation order", a.Name())
+		}
+		return nil
+
+	case enum.Collection:
+		if _, ok := b.(enum.Collection); !ok {
+			return fmt.Errorf("can not merge enum collection %s with %T", a.Name(), b)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unable to merge collections of type %T", a)
+}
+
+// buildCollection builds a collection like the prototype containing the
+// captures provided, with members replacing the prototype's for enums.
+func buildCollection(prototype format.CaptureCollection, members []string, captures []format.Capture) (format.CaptureCollection, error) {
+	name := prototype.Name()
+
+	switch typed := prototype.(type) {
+	case position.Collection:
+		return position.NewCollection(name, castCaptures[position.Capture](captures)), nil
+
+	case euler.Collection:
+		return euler.NewCollectionWithOrder(name, typed.Order(), castCaptures[euler.Capture](captures)), nil
+
+	case event.Collection:
+		return event.NewCollection(name, castCaptures[event.Capture](captures)), nil
+
+	case float.Collection:
+		return float.NewCollection(name, castCaptures[float.Capture](captures)), nil
+
+	case quaternion.Collection:
+		return quaternion.NewCollection(name, castCaptures[quaternion.Capture](captures)), nil
+
+	case vector2.Collection:
+		return vector2.NewCollection(name, castCaptures[vector2.Capture](captures)), nil
+
+	case pose.Collection:
+		return pose.NewCollectionWithOrder(name, typed.Skeleton(), typed.Order(), castCaptures[pose.Capture](captures)), nil
+
+	case transform.Collection:
+		return transform.NewCollectionWithOrder(name, typed.Order(), castCaptures[transform.Capture](captures)), nil
+
+	case enum.Collection:
+		return enum.NewCollection(name, members, castCaptures[enum.Capture](captures)), nil
+	}
+
+	return nil, fmt.Errorf("unable to merge collections of type %T", prototype)
+}
+
+func castCaptures[T format.Capture](captures []format.Capture) []T {
+	casted := make([]T, len(captures))
+	for i, c := range captures {
+		casted[i] = c.(T)
+	}
+	return casted
+}
+
+// ChunkedWriter writes a recording incrementally, as captures arrive,
+// periodically flushing self contained chunks to the underlying stream so a
+// crash only loses the captures appended since the last flush.
+type ChunkedWriter struct {
+	writer        Writer
+	out           io.Writer
+	flushInterval time.Duration
+	lastFlush     time.Time
+	root          *chunkNode
+	started       bool
+	chunksWritten int
+	finalized     bool
+}
+
+// NewChunkedWriter builds a writer that journals captures to out. Pending
+// captures are flushed as a new chunk whenever the flush interval has elapsed
+// since the previous flush. A flush interval of 0 only flushes when Flush or
+// Finalize is called.
+func NewChunkedWriter(encoders []encoding.Encoder, compress bool, out io.Writer, timeStorageTechnique TimeStorageTechnique, rootID string, flushInterval time.Duration, options ...WriterOption) *ChunkedWriter {
+	return &ChunkedWriter{
+		writer:        NewWriter(encoders, compress, nil, timeStorageTechnique, options...),
+		out:           out,
+		flushInterval: flushInterval,
+		lastFlush:     time.Now(),
+		root:          newChunkNode(rootID),
+	}
+}
+
+// resolve finds the node at the path provided, building out any part of the
+// tree that does not exist yet.
+func (cw *ChunkedWriter) resolve(path string) (*chunkNode, error) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if segments[0] != cw.root.id {
+		return nil, fmt.Errorf("path must begin at root recording %s: %s", cw.root.id, path)
+	}
+
+	node := cw.root
+	for _, segment := range segments[1:] {
+		node = node.child(segment)
+	}
+	return node, nil
+}
+
+func (cw *ChunkedWriter) checkWritable() error {
+	if cw.finalized {
+		return errors.New("chunked writer has already been finalized")
+	}
+	return nil
+}
+
+func (cw *ChunkedWriter) flushIfDue() error {
+	if cw.flushInterval > 0 && time.Since(cw.lastFlush) >= cw.flushInterval {
+		return cw.Flush()
+	}
+	return nil
+}
+
+// AppendCapture adds a single capture to the named collection of the
+// recording found at the path provided, creating both if need be.
+func (cw *ChunkedWriter) AppendCapture(path, collectionName string, capture format.Capture) error {
+	if err := cw.checkWritable(); err != nil {
+		return err
+	}
+
+	if capture == nil {
+		return errors.New("can not append nil capture")
+	}
+
+	node, err := cw.resolve(path)
+	if err != nil {
+		return err
+	}
+
+	collection, err := newCollectionFromCapture(collectionName, capture, node.prototypes[collectionName])
+	if err != nil {
+		return err
+	}
+
+	err = node.add(collection)
+	if err != nil {
+		return err
+	}
+
+	return cw.flushIfDue()
+}
+
+// AppendCollection adds all captures found within the collection to the
+// collection of the same name in the recording found at the path provided.
+func (cw *ChunkedWriter) AppendCollection(path string, collection format.CaptureCollection) error {
+	if err := cw.checkWritable(); err != nil {
+		return err
+	}
+
+	if collection == nil {
+		return errors.New("can not append nil capture collection")
+	}
+
+	node, err := cw.resolve(path)
+	if err != nil {
+		return err
+	}
+
+	err = node.add(collection)
+	if err != nil {
+		return err
+	}
+
+	return cw.flushIfDue()
+}
+
+// SetName sets the name of the recording found at the path provided.
+func (cw *ChunkedWriter) SetName(path, name string) error {
+	if err := cw.checkWritable(); err != nil {
+		return err
+	}
+
+	node, err := cw.resolve(path)
+	if err != nil {
+		return err
+	}
+	node.name = name
+	node.nameChanged = true
+	return nil
+}
+
+// SetMetadata sets a metadata property on the recording found at the path
+// provided. Properties set in later chunks take precedence over earlier ones.
+func (cw *ChunkedWriter) SetMetadata(path, key string, property metadata.Property) error {
+	if err := cw.checkWritable(); err != nil {
+		return err
+	}
+
+	node, err := cw.resolve(path)
+	if err != nil {
+		return err
+	}
+	node.metadata[key] = property
+	return nil
+}
+
+// AppendBinary embeds a binary within the recording found at the path
+// provided. The binary is written out with the next chunk, so must remain
+// readable until then.
+func (cw *ChunkedWriter) AppendBinary(path string, binary format.Binary) error {
+	if err := cw.checkWritable(); err != nil {
+		return err
+	}
+
+	if binary == nil {
+		return errors.New("can not append nil binary")
+	}
+
+	node, err := cw.resolve(path)
+	if err != nil {
+		return err
+	}
+	node.binaries = append(node.binaries, binary)
+	return cw.flushIfDue()
+}
+
+// AppendBinaryReference adds a reference to a binary stored elsewhere to the
+// recording found at the path provided.
+func (cw *ChunkedWriter) AppendBinaryReference(path string, reference format.BinaryReference) error {
+	if err := cw.checkWritable(); err != nil {
+		return err
+	}
+
+	if reference == nil {
+		return errors.New("can not append nil binary reference")
+	}
+
+	node, err := cw.resolve(path)
+	if err != nil {
+		return err
+	}
+	node.references = append(node.references, reference)
+	return cw.flushIfDue()
+}
+
+func (cw *ChunkedWriter) sync() error {
+	if syncer, ok := cw.out.(interface{ Sync() error }); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+func (cw *ChunkedWriter) writeVersion() error {
+	if cw.started {
+		return nil
+	}
+	_, err := cw.out.Write([]byte{4})
+	if err != nil {
+		return err
+	}
+	cw.started = true
+	return nil
+}
+
+// Flush writes everything appended since the previous flush as a new chunk.
+func (cw *ChunkedWriter) Flush() error {
+	return cw.flush(false)
+}
+
+// flush writes out a new chunk, skipping it when there is nothing pending
+// unless forced to.
+func (cw *ChunkedWriter) flush(force bool) error {
+	if err := cw.checkWritable(); err != nil {
+		return err
+	}
+
+	cw.lastFlush = time.Now()
+
+	if err := cw.writeVersion(); err != nil {
+		return err
+	}
+
+	if cw.root.empty() && !force {
+		return nil
+	}
+
+	recording, err := cw.root.toRecording()
+	if err != nil {
+		return err
+	}
+
+	chunkData := bytes.Buffer{}
+	chunkWriter := cw.writer
+	chunkWriter.out = &chunkData
+	_, err = chunkWriter.Write(recording)
+	if err != nil {
+		return err
+	}
+
+	// Write the entire chunk at once to minimize the chance of tearing it
+	chunk := bytes.Buffer{}
+	chunk.WriteByte(chunkMarker)
+	writeUvarint(&chunk, uint64(chunkData.Len()))
+	checksum := make([]byte, 4)
+	binary.LittleEndian.PutUint32(checksum, crc32.ChecksumIEEE(chunkData.Bytes()))
+	chunk.Write(checksum)
+	chunk.Write(chunkData.Bytes())
+
+	_, err = cw.out.Write(chunk.Bytes())
+	if err != nil {
+		return err
+	}
+
+	cw.root.reset()
+	cw.chunksWritten++
+	return cw.sync()
+}
+
+// Finalize flushes any remaining captures and marks the recording as
+// complete. No more captures can be appended afterwards.
+func (cw *ChunkedWriter) Finalize() error {
+	// Always leave at least one chunk behind so the root recording exists
+	err := cw.flush(cw.chunksWritten == 0)
+	if err != nil {
+		return err
+	}
+
+	_, err = cw.out.Write([]byte{chunkEndMarker})
+	if err != nil {
+		return err
+	}
+	cw.finalized = true
+	return cw.sync()
+}
+
+// readChunkData reads the length, checksum, and data of a single chunk,
+// ensuring the data matches the checksum.
+func readChunkData(in io.Reader, opts readerOptions) ([]byte, error) {
+	length, _, err := rapbinary.ReadUvarint(in)
+	if err != nil {
+		return nil, err
+	}
+
+	err = rapbinary.CheckLimit("chunk size", length, opts.maxDecompressedSize)
+	if err != nil {
+		return nil, err
+	}
+
+	checksum := make([]byte, 4)
+	_, err = io.ReadFull(in, checksum)
+	if err != nil {
+		return nil, err
+	}
+
+	chunkData := bytes.Buffer{}
+	_, err = io.CopyN(&chunkData, in, int64(length))
+	if err != nil {
+		return nil, err
+	}
+
+	if crc32.ChecksumIEEE(chunkData.Bytes()) != binary.LittleEndian.Uint32(checksum) {
+		return nil, errors.New("chunk data does not match it's checksum")
+	}
+
+	return chunkData.Bytes(), nil
+}
+
+// readChunks reads and merges every intact chunk of a chunked recording. The
+// version byte is assumed to have already been consumed. When
+// allowUnfinalized is set, a missing end marker or a torn final chunk is not
+// treated as an error.
+func (r Reader) readChunks(allowUnfinalized bool) (format.Recording, int, error) {
+	er := rapbinary.NewErrReader(r.in)
+
+	// Chunks are gathered into a tree of pending collections, building every
+	// collection once at the end instead of copying it for every chunk
+	var root *chunkNode
+
+	// Every chunk counts towards the same decompressed size limit
+	chunkOptions := r.options
+	chunkOptions.decompressed = new(uint64)
+
+	for {
+		marker, err := er.ReadByte()
+		if err != nil {
+			if allowUnfinalized {
+				break
+			}
+			return nil, er.TotalRead(), ErrChunkedRecordingNotFinalized
+		}
+
+		if marker == chunkEndMarker {
+			break
+		}
+
+		if marker != chunkMarker {
+			return nil, er.TotalRead(), fmt.Errorf("unrecognized chunk marker: %d", marker)
+		}
+
+		chunkData, err := readChunkData(er, r.options)
+		if err != nil {
+			if allowUnfinalized {
+				break
+			}
+			return nil, er.TotalRead(), err
+		}
+
+		chunkReader := Reader{encoders: r.encoders, in: bytes.NewReader(chunkData), options: chunkOptions}
+		chunkRec, _, err := chunkReader.Read()
+		if err != nil {
+			return nil, er.TotalRead(), err
+		}
+
+		if root == nil {
+			root = newChunkNode(chunkRec.ID())
+		}
+
+		err = root.merge(chunkRec)
+		if err != nil {
+			return nil, er.TotalRead(), err
+		}
+	}
+
+	if root == nil {
+		return nil, er.TotalRead(), errors.New("chunked recording contains no chunks")
+	}
+
+	rec, err := root.toRecording()
+	return rec, er.TotalRead(), err
+}
+
+// ReadUnfinalized reads a chunked recording whether or not it was ever
+// finalized, recovering every chunk that was completely written to disk.
+func (r Reader) ReadUnfinalized() (format.Recording, int, error) {
+	return r.withSpillCleanup(Reader.readUnfinalized)
+}
+
+func (r Reader) readUnfinalized() (format.Recording, int, error) {
+	if r.in == nil {
+		panic("Attempting to load recording from nil reader")
+	}
+
+	version, bytesRead, err := GetRecoringVersion(r.in)
+	if err != nil {
+		return nil, bytesRead, err
+	}
+
+	if version != 4 {
+		return nil, bytesRead, fmt.Errorf("expected chunked recording, found version: %d", version)
+	}
+
+	rec, read, err := r.readChunks(true)
+	return rec, read + bytesRead, err
+}
@@ -0,0 +1,271 @@
+package io_test
+
+import (
+	"bytes"
+	goio "io"
+	"testing"
+
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/enum"
+	"github.com/recolude/rap/format/collection/event"
+	"github.com/recolude/rap/format/collection/position"
+	"github.com/recolude/rap/format/encoding"
+	enumEncoding "github.com/recolude/rap/format/encoding/enum"
+	eventEncoding "github.com/recolude/rap/format/encoding/event"
+	positionEncoding "github.com/recolude/rap/format/encoding/position"
+	"github.com/recolude/rap/format/io"
+	"github.com/recolude/rap/format/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func chunkedTestEncoders() []encoding.Encoder {
+	return []encoding.Encoder{
+		eventEncoding.NewEncoder(),
+		positionEncoding.NewEncoder(positionEncoding.Raw64),
+		enumEncoding.NewEncoder(),
+	}
+}
+
+func Test_Chunked_MergesChunksOnLoad(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	w := io.NewChunkedWriter(chunkedTestEncoders(), true, fileData, io.Raw64, "session", 0)
+
+	// ACT ====================================================================
+	assert.NoError(t, w.SetMetadata("session", "level", metadata.NewStringProperty("lobby")))
+	assert.NoError(t, w.AppendCapture("session/players/p7", "Position", position.NewCapture(1, 1, 2, 3)))
+	assert.NoError(t, w.AppendCapture("session/players/p7", "Position", position.NewCapture(2, 4, 5, 6)))
+	assert.NoError(t, w.AppendCollection("session/players/p7", enum.NewCollection("State", []string{"idle", "running"}, []enum.Capture{
+		enum.NewCapture(1, 0),
+	})))
+	assert.NoError(t, w.Flush())
+
+	assert.NoError(t, w.AppendCapture("session/players/p7", "Position", position.NewCapture(3, 7, 8, 9)))
+	assert.NoError(t, w.AppendCapture("session/players/p7", "State", enum.NewCapture(3, 1)))
+	assert.NoError(t, w.AppendCapture("session", "Events", event.NewCapture(3, "Goal", metadata.EmptyBlock())))
+	assert.NoError(t, w.SetName("session/players", "Players"))
+	assert.NoError(t, w.Finalize())
+
+	rec, n, err := io.Load(bytes.NewReader(fileData.Bytes()))
+
+	// ASSERT =================================================================
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, fileData.Len(), n)
+
+	expected := format.NewRecording(
+		"session",
+		"session",
+		[]format.CaptureCollection{
+			event.NewCollection("Events", []event.Capture{
+				event.NewCapture(3, "Goal", metadata.EmptyBlock()),
+			}),
+		},
+		[]format.Recording{
+			format.NewRecording(
+				"players",
+				"Players",
+				nil,
+				[]format.Recording{
+					format.NewRecording(
+						"p7",
+						"p7",
+						[]format.CaptureCollection{
+							position.NewCollection("Position", []position.Capture{
+								position.NewCapture(1, 1, 2, 3),
+								position.NewCapture(2, 4, 5, 6),
+								position.NewCapture(3, 7, 8, 9),
+							}),
+							enum.NewCollection("State", []string{"idle", "running"}, []enum.Capture{
+								enum.NewCapture(1, 0),
+								enum.NewCapture(3, 1),
+							}),
+						},
+						nil,
+						metadata.EmptyBlock(),
+						nil,
+						nil,
+					),
+				},
+				metadata.EmptyBlock(),
+				nil,
+				nil,
+			),
+		},
+		metadata.NewBlock(map[string]metadata.Property{
+			"level": metadata.NewStringProperty("lobby"),
+		}),
+		nil,
+		nil,
+	)
+	assertRecordingsMatch(t, expected, rec, 0)
+
+	state := rec.Recordings()[0].Recordings()[0].CaptureCollections()[1].(enum.Collection)
+	assert.Equal(t, []string{"idle", "running"}, state.EnumMembers())
+	assert.Equal(t, 1, state.CaptureAt(1).(enum.Capture).Value())
+}
+
+func Test_Chunked_KeepsNameChangeWithoutNewCaptures(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	w := io.NewChunkedWriter(chunkedTestEncoders(), true, fileData, io.Raw64, "session", 0)
+	assert.NoError(t, w.AppendCapture("session/p7", "Position", position.NewCapture(1, 1, 2, 3)))
+	assert.NoError(t, w.Flush())
+
+	// ACT ====================================================================
+	assert.NoError(t, w.SetName("session/p7", "Player Seven"))
+	assert.NoError(t, w.Finalize())
+
+	rec, _, err := io.Load(bytes.NewReader(fileData.Bytes()))
+
+	// ASSERT =================================================================
+	if !assert.NoError(t, err) || !assert.Len(t, rec.Recordings(), 1) {
+		return
+	}
+	assert.Equal(t, "Player Seven", rec.Recordings()[0].Name())
+	assert.Equal(t, 1, rec.Recordings()[0].CaptureCollections()[0].Length())
+}
+
+func Test_Chunked_LoadErrorsWhenNotFinalized(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	w := io.NewChunkedWriter(chunkedTestEncoders(), true, fileData, io.Raw64, "session", 0)
+	assert.NoError(t, w.AppendCapture("session", "Position", position.NewCapture(1, 1, 2, 3)))
+	assert.NoError(t, w.Flush())
+
+	// ACT ====================================================================
+	rec, _, err := io.Load(bytes.NewReader(fileData.Bytes()))
+
+	// ASSERT =================================================================
+	assert.Nil(t, rec)
+	assert.ErrorIs(t, err, io.ErrChunkedRecordingNotFinalized)
+}
+
+func Test_Chunked_RecoversUnfinalizedRecordingWithTornChunk(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	w := io.NewChunkedWriter(chunkedTestEncoders(), true, fileData, io.Raw64, "session", 0)
+	assert.NoError(t, w.AppendCapture("session", "Position", position.NewCapture(1, 1, 2, 3)))
+	assert.NoError(t, w.Flush())
+	assert.NoError(t, w.AppendCapture("session", "Position", position.NewCapture(2, 4, 5, 6)))
+	assert.NoError(t, w.Flush())
+	intactLength := fileData.Len()
+	assert.NoError(t, w.AppendCapture("session", "Position", position.NewCapture(3, 7, 8, 9)))
+	assert.NoError(t, w.Flush())
+
+	// Simulate crashing halfway through writing the last chunk
+	torn := fileData.Bytes()[:intactLength+(fileData.Len()-intactLength)/2]
+
+	// ACT ====================================================================
+	rec, _, err := io.NewReader(chunkedTestEncoders(), bytes.NewReader(torn)).ReadUnfinalized()
+
+	// ASSERT =================================================================
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.Len(t, rec.CaptureCollections(), 1) {
+		assert.Equal(t, 2, rec.CaptureCollections()[0].Length())
+	}
+}
+
+func Test_Chunked_EnumCaptureRequiresMembers(t *testing.T) {
+	// ARRANGE ================================================================
+	w := io.NewChunkedWriter(chunkedTestEncoders(), true, new(bytes.Buffer), io.Raw64, "session", 0)
+
+	// ACT ====================================================================
+	err := w.AppendCapture("session", "State", enum.NewCapture(1, 0))
+
+	// ASSERT =================================================================
+	assert.EqualError(t, err, "enum collection State must be appended with it's members before individual captures")
+}
+
+func Test_Chunked_ErrorsOnPathOutsideRoot(t *testing.T) {
+	// ARRANGE ================================================================
+	w := io.NewChunkedWriter(chunkedTestEncoders(), true, new(bytes.Buffer), io.Raw64, "session", 0)
+
+	// ACT ====================================================================
+	err := w.AppendCapture("other/p1", "Position", position.NewCapture(1, 1, 2, 3))
+
+	// ASSERT =================================================================
+	assert.EqualError(t, err, "path must begin at root recording session: other/p1")
+}
+
+func Test_Chunked_ErrorsAfterFinalize(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	w := io.NewChunkedWriter(chunkedTestEncoders(), true, fileData, io.Raw64, "session", 0)
+	assert.NoError(t, w.Finalize())
+
+	// ACT ====================================================================
+	err := w.AppendCapture("session", "Position", position.NewCapture(1, 1, 2, 3))
+	rec, _, loadErr := io.Load(bytes.NewReader(fileData.Bytes()))
+
+	// ASSERT =================================================================
+	assert.EqualError(t, err, "chunked writer has already been finalized")
+	assert.NoError(t, loadErr)
+	if assert.NotNil(t, rec) {
+		assert.Equal(t, "session", rec.ID())
+	}
+}
+
+func Test_Chunked_AppendsBinariesOnce(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	w := io.NewChunkedWriter(chunkedTestEncoders(), true, fileData, io.Raw64, "session", 0)
+
+	// ACT ====================================================================
+	assert.NoError(t, w.AppendBinary("session/p7", io.NewBinary("voice", []byte("hello"), metadata.EmptyBlock())))
+	assert.NoError(t, w.AppendBinaryReference("session", io.NewBinaryReference("replay", "https://example.com/replay", 10, metadata.EmptyBlock())))
+	assert.NoError(t, w.Flush())
+
+	assert.NoError(t, w.AppendBinary("session/p7", io.NewBinary("voice", []byte("world"), metadata.EmptyBlock())))
+	assert.NoError(t, w.AppendCapture("session", "Position", position.NewCapture(1, 1, 2, 3)))
+	errNil := w.AppendBinary("session", nil)
+	assert.NoError(t, w.Finalize())
+
+	rec, _, err := io.Load(bytes.NewReader(fileData.Bytes()))
+
+	// ASSERT =================================================================
+	assert.EqualError(t, errNil, "can not append nil binary")
+	if !assert.NoError(t, err) || !assert.Len(t, rec.Recordings(), 1) {
+		return
+	}
+
+	assert.Len(t, rec.BinaryReferences(), 1)
+	assert.Equal(t, "https://example.com/replay", rec.BinaryReferences()[0].URI())
+
+	binaries := rec.Recordings()[0].Binaries()
+	if assert.Len(t, binaries, 2) {
+		first, errFirst := goio.ReadAll(binaries[0].Data())
+		second, errSecond := goio.ReadAll(binaries[1].Data())
+		assert.NoError(t, errFirst)
+		assert.NoError(t, errSecond)
+		assert.Equal(t, "hello", string(first))
+		assert.Equal(t, "world", string(second))
+	}
+}
+
+func Test_Chunked_DecompressedSizeSpansChunks(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	w := io.NewChunkedWriter(chunkedTestEncoders(), false, fileData, io.Raw64, "session", 0)
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, w.AppendCapture("session", "Position", position.NewCapture(float64(i), 1, 2, 3)))
+		assert.NoError(t, w.Flush())
+	}
+	assert.NoError(t, w.Finalize())
+
+	// ACT ====================================================================
+	_, _, errWithin := io.Load(bytes.NewReader(fileData.Bytes()), io.MaxDecompressedSize(uint64(fileData.Len())))
+	rec, _, errExceeded := io.Load(bytes.NewReader(fileData.Bytes()), io.MaxDecompressedSize(uint64(fileData.Len()/2)))
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWithin)
+	assert.Nil(t, rec)
+
+	var limitErr io.LimitError
+	if assert.ErrorAs(t, errExceeded, &limitErr) {
+		assert.Equal(t, "decompressed size", limitErr.Limit)
+	}
+}
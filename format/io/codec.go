@@ -0,0 +1,225 @@
+package io
+
+import (
+	"compress/flate"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+// CodecID identifies the compression codec used on the contents of a
+// recording. It takes the place of what used to be a flag determining
+// whether or not the contents were compressed with flate, so the IDs of
+// those two options are unchanged. IDs must be below 128, as the high bit is
+// reserved for flagging the features a recording was written with.
+type CodecID byte
+
+const (
+	// CodecNone leaves the contents of the recording uncompressed.
+	CodecNone CodecID = 0
+
+	// CodecFlate compresses the contents with flate, at level 9 unless told
+	// otherwise.
+	CodecFlate CodecID = 1
+
+	// CodecZstd compresses the contents with zstandard.
+	CodecZstd CodecID = 2
+
+	// CodecLZ4 compresses the contents with lz4, trading size for speed.
+	CodecLZ4 CodecID = 3
+)
+
+// ErrUnknownCodec is returned when a recording was compressed with a codec
+// that has not been registered.
+var ErrUnknownCodec = errors.New("no registered compression codec has id")
+
+// Codec compresses and decompresses the contents of a recording.
+type Codec interface {
+	ID() CodecID
+
+	// DefaultLevel is the compression level used when none is specified.
+	DefaultLevel() int
+
+	NewWriter(out io.Writer, level int) (io.WriteCloser, error)
+	NewReader(in io.Reader) (io.Reader, error)
+}
+
+var (
+	codecMutex sync.RWMutex
+	codecs     = make(map[CodecID]Codec)
+)
+
+// RegisterCodec makes a codec available for both reading and writing
+// recordings, replacing any codec previously registered with the same ID.
+func RegisterCodec(codec Codec) {
+	if codec == nil {
+		panic("can not register nil codec")
+	}
+
+	if codec.ID()&featureFlag != 0 {
+		panic(fmt.Sprintf("codec id %d is too large, ids must be below %d", codec.ID(), featureFlag))
+	}
+
+	codecMutex.Lock()
+	defer codecMutex.Unlock()
+	codecs[codec.ID()] = codec
+}
+
+// LookupCodec finds the codec registered with the ID provided.
+func LookupCodec(id CodecID) (Codec, error) {
+	codecMutex.RLock()
+	defer codecMutex.RUnlock()
+
+	codec, ok := codecs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w %d", ErrUnknownCodec, id)
+	}
+	return codec, nil
+}
+
+func init() {
+	RegisterCodec(noneCodec{})
+	RegisterCodec(flateCodec{})
+	RegisterCodec(zstdCodec{})
+	RegisterCodec(lz4Codec{})
+}
+
+type noneCodec struct{}
+
+func (noneCodec) ID() CodecID {
+	return CodecNone
+}
+
+func (noneCodec) DefaultLevel() int {
+	return 0
+}
+
+func (noneCodec) NewWriter(out io.Writer, level int) (io.WriteCloser, error) {
+	return &errWriter{Writer: out}, nil
+}
+
+func (noneCodec) NewReader(in io.Reader) (io.Reader, error) {
+	return in, nil
+}
+
+type flateCodec struct{}
+
+func (flateCodec) ID() CodecID {
+	return CodecFlate
+}
+
+func (flateCodec) DefaultLevel() int {
+	return flate.BestCompression
+}
+
+func (flateCodec) NewWriter(out io.Writer, level int) (io.WriteCloser, error) {
+	return flate.NewWriter(out, level)
+}
+
+func (flateCodec) NewReader(in io.Reader) (io.Reader, error) {
+	return flate.NewReader(in), nil
+}
+
+type zstdCodec struct{}
+
+func (zstdCodec) ID() CodecID {
+	return CodecZstd
+}
+
+func (zstdCodec) DefaultLevel() int {
+	return 3
+}
+
+// NewWriter builds a zstandard encoder, with levels following the zstd
+// command line tool's levels.
+func (zstdCodec) NewWriter(out io.Writer, level int) (io.WriteCloser, error) {
+	return zstd.NewWriter(
+		out,
+		zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)),
+		zstd.WithEncoderConcurrency(1),
+	)
+}
+
+func (zstdCodec) NewReader(in io.Reader) (io.Reader, error) {
+	return zstd.NewReader(in, zstd.WithDecoderConcurrency(1))
+}
+
+type lz4Codec struct{}
+
+func (lz4Codec) ID() CodecID {
+	return CodecLZ4
+}
+
+func (lz4Codec) DefaultLevel() int {
+	return 0
+}
+
+// NewWriter builds an lz4 frame writer. Level 0 is lz4's fast mode, while
+// levels 1 through 9 use it's slower high compression mode.
+func (lz4Codec) NewWriter(out io.Writer, level int) (io.WriteCloser, error) {
+	if level < 0 || level > 9 {
+		return nil, fmt.Errorf("lz4 compression level must be between 0 and 9, found %d", level)
+	}
+
+	compressionLevel := lz4.Fast
+	if level > 0 {
+		compressionLevel = lz4.CompressionLevel(1 << (8 + level))
+	}
+
+	writer := lz4.NewWriter(out)
+	err := writer.Apply(lz4.CompressionLevelOption(compressionLevel), lz4.ConcurrencyOption(1))
+	if err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (lz4Codec) NewReader(in io.Reader) (io.Reader, error) {
+	return lz4.NewReader(in), nil
+}
+
+// Compression sets the codec used to compress the contents of recordings,
+// overriding the compress flag the writer was built with.
+func Compression(codec CodecID) WriterOption {
+	return func(options *writerOptions) {
+		options.codec = codec
+		options.codecSet = true
+	}
+}
+
+// CompressionLevel sets the level the codec compresses at, with it's meaning
+// depending on the codec. Codecs use their default level when this is not
+// set.
+func CompressionLevel(level int) WriterOption {
+	return func(options *writerOptions) {
+		options.level = level
+		options.levelSet = true
+	}
+}
+
+// codec resolves the codec the writer compresses with and the level it
+// compresses at.
+func (w Writer) codec() (Codec, int, error) {
+	id := CodecNone
+	if w.compress {
+		id = CodecFlate
+	}
+	if w.options.codecSet {
+		id = w.options.codec
+	}
+
+	codec, err := LookupCodec(id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	level := codec.DefaultLevel()
+	if w.options.levelSet {
+		level = w.options.level
+	}
+	return codec, level, nil
+}
@@ -0,0 +1,178 @@
+package io_test
+
+import (
+	"bytes"
+	"fmt"
+	goio "io"
+	"testing"
+
+	"github.com/recolude/rap/format/encoding"
+	eventEncoding "github.com/recolude/rap/format/encoding/event"
+	positionEncoding "github.com/recolude/rap/format/encoding/position"
+	"github.com/recolude/rap/format/io"
+	"github.com/stretchr/testify/assert"
+)
+
+func codecTestEncoders() []encoding.Encoder {
+	return []encoding.Encoder{
+		eventEncoding.NewEncoder(),
+		positionEncoding.NewEncoder(positionEncoding.Raw64),
+	}
+}
+
+func Test_Codecs_RoundTrip(t *testing.T) {
+	tests := map[string][]io.WriterOption{
+		"none":          {io.Compression(io.CodecNone)},
+		"flate":         {io.Compression(io.CodecFlate)},
+		"flate level 1": {io.Compression(io.CodecFlate), io.CompressionLevel(1)},
+		"zstd":          {io.Compression(io.CodecZstd)},
+		"zstd level 19": {io.Compression(io.CodecZstd), io.CompressionLevel(19)},
+		"lz4":           {io.Compression(io.CodecLZ4)},
+		"lz4 level 9":   {io.Compression(io.CodecLZ4), io.CompressionLevel(9)},
+	}
+
+	for name, options := range tests {
+		for _, indexed := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s indexed %t", name, indexed), func(t *testing.T) {
+				// ARRANGE ====================================================
+				newWriter := io.NewWriter
+				if indexed {
+					newWriter = io.NewIndexedWriter
+				}
+				fileData := new(bytes.Buffer)
+				recIn := buildIndexedTestRecording()
+
+				// ACT ========================================================
+				n, errWrite := newWriter(codecTestEncoders(), false, fileData, io.Raw64, options...).Write(recIn)
+				recOut, nOut, errRead := io.NewReader(codecTestEncoders(), bytes.NewReader(fileData.Bytes())).Read()
+
+				// ASSERT =====================================================
+				assert.NoError(t, errWrite)
+				assert.NoError(t, errRead)
+				assert.Equal(t, n, nOut)
+				assertRecordingsMatch(t, recIn, recOut, 0)
+			})
+		}
+	}
+}
+
+func Test_Codecs_CompressFlagMatchesLegacyOutput(t *testing.T) {
+	for _, compress := range []bool{true, false} {
+		// ARRANGE ============================================================
+		codec := io.CodecNone
+		if compress {
+			codec = io.CodecFlate
+		}
+		legacy := new(bytes.Buffer)
+		explicit := new(bytes.Buffer)
+		rec := buildIndexedTestRecording()
+
+		// ACT ================================================================
+		_, errLegacy := io.NewWriter(codecTestEncoders(), compress, legacy, io.Raw64).Write(rec)
+		_, errExplicit := io.NewWriter(codecTestEncoders(), !compress, explicit, io.Raw64, io.Compression(codec)).Write(rec)
+
+		// ASSERT =============================================================
+		assert.NoError(t, errLegacy)
+		assert.NoError(t, errExplicit)
+		assert.Equal(t, legacy.Bytes(), explicit.Bytes())
+	}
+}
+
+func Test_Codecs_ErrorsOnUnknownCodec(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	_, err := io.NewWriter(codecTestEncoders(), true, fileData, io.Raw64).Write(buildIndexedTestRecording())
+	assert.NoError(t, err)
+
+	// Codec id follows the version byte, the length prefixed array of
+	// encoder signatures, and a byte for each encoder's version
+	unknown := append([]byte{}, fileData.Bytes()...)
+	codecOffset := 1 + 1 + (1 + len("recolude.event")) + (1 + len("recolude.position")) + 2
+	assert.Equal(t, byte(io.CodecFlate), unknown[codecOffset])
+	unknown[codecOffset] = 120
+
+	// ACT ====================================================================
+	_, errWrite := io.NewWriter(codecTestEncoders(), true, new(bytes.Buffer), io.Raw64, io.Compression(120)).Write(buildIndexedTestRecording())
+	_, _, errRead := io.NewReader(codecTestEncoders(), bytes.NewReader(unknown)).Read()
+
+	// ASSERT =================================================================
+	assert.ErrorIs(t, errWrite, io.ErrUnknownCodec)
+	assert.ErrorIs(t, errRead, io.ErrUnknownCodec)
+}
+
+func Test_Codecs_LZ4RejectsUnknownLevels(t *testing.T) {
+	for _, level := range []int{-1, 10} {
+		// ACT ================================================================
+		_, err := io.NewWriter(codecTestEncoders(), false, new(bytes.Buffer), io.Raw64, io.Compression(io.CodecLZ4), io.CompressionLevel(level)).Write(buildIndexedTestRecording())
+
+		// ASSERT =============================================================
+		assert.EqualError(t, err, fmt.Sprintf("lz4 compression level must be between 0 and 9, found %d", level))
+	}
+}
+
+// reversingCodec is a stand in for a codec registered outside of the package,
+// storing the contents of a recording in reverse.
+type reversingCodec struct{}
+
+func (reversingCodec) ID() io.CodecID {
+	return 100
+}
+
+func (reversingCodec) DefaultLevel() int {
+	return 0
+}
+
+type reversingWriter struct {
+	out    goio.Writer
+	buffer bytes.Buffer
+}
+
+func (rw *reversingWriter) Write(p []byte) (int, error) {
+	return rw.buffer.Write(p)
+}
+
+func (rw *reversingWriter) Close() error {
+	_, err := rw.out.Write(reverse(rw.buffer.Bytes()))
+	return err
+}
+
+func reverse(data []byte) []byte {
+	reversed := make([]byte, len(data))
+	for i, b := range data {
+		reversed[len(data)-1-i] = b
+	}
+	return reversed
+}
+
+func (reversingCodec) NewWriter(out goio.Writer, level int) (goio.WriteCloser, error) {
+	return &reversingWriter{out: out}, nil
+}
+
+func (reversingCodec) NewReader(in goio.Reader) (goio.Reader, error) {
+	data, err := goio.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(reverse(data)), nil
+}
+
+func Test_Codecs_RegisteredCodec(t *testing.T) {
+	// ARRANGE ================================================================
+	io.RegisterCodec(reversingCodec{})
+	fileData := new(bytes.Buffer)
+	recIn := buildIndexedTestRecording()
+
+	// ACT ====================================================================
+	n, errWrite := io.NewWriter(codecTestEncoders(), false, fileData, io.Raw64, io.Compression(100)).Write(recIn)
+	manifest, errInspect := io.Inspect(bytes.NewReader(fileData.Bytes()))
+	recOut, nOut, errRead := io.NewReader(codecTestEncoders(), bytes.NewReader(fileData.Bytes())).Read()
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	assert.NoError(t, errInspect)
+	assert.NoError(t, errRead)
+	assert.Equal(t, n, nOut)
+	assert.Equal(t, io.CodecID(100), manifest.Codec)
+	assert.True(t, manifest.Compressed)
+	assertRecordingsMatch(t, recIn, recOut, 0)
+}
@@ -0,0 +1,173 @@
+package io
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/metadata"
+	rapbinary "github.com/recolude/rap/internal/io/binary"
+)
+
+// A deduplicated v2 recording stores every distinct capture collection and
+// binary once. Every collection is prefixed with a uvarint reference:
+//
+//   0  the collection follows in full
+//   n  the collection is the same as the n-th collection written in full,
+//      and only it's name follows
+//
+// Binaries keep their name, size and metadata, which are followed by a
+// reference of the same meaning, with their data only following when the
+// reference is 0. Collections are considered the same when their encoder,
+// times and encoded bodies are, and binaries when their data is.
+
+// Deduplicate has the writer store identical capture collections and
+// binaries once, with every other occurrence referring back to the first.
+// Reading the recording expands references back into independent copies.
+// Only supported by the v2 layout.
+func Deduplicate() WriterOption {
+	return func(options *writerOptions) {
+		options.deduplicate = true
+	}
+}
+
+// dedupWriter tracks the collections and binaries already written in full.
+type dedupWriter struct {
+	collections map[[sha256.Size]byte]uint64
+	binaries    map[[sha256.Size]byte]uint64
+}
+
+func newDedupWriter() *dedupWriter {
+	return &dedupWriter{
+		collections: make(map[[sha256.Size]byte]uint64),
+		binaries:    make(map[[sha256.Size]byte]uint64),
+	}
+}
+
+// reference returns the reference to write for the contents provided, which
+// is 0 the first time the contents are seen.
+func reference(written map[[sha256.Size]byte]uint64, contents ...[]byte) uint64 {
+	digest := sha256.New()
+	for _, content := range contents {
+		digest.Write(rapbinary.BytesArrayToBytes(content))
+	}
+
+	sum := [sha256.Size]byte{}
+	digest.Sum(sum[:0])
+
+	if ref, ok := written[sum]; ok {
+		return ref
+	}
+	written[sum] = uint64(len(written) + 1)
+	return 0
+}
+
+func (d *dedupWriter) collection(encoderIndex int, times, body []byte) uint64 {
+	return reference(d.collections, binary.AppendUvarint(nil, uint64(encoderIndex)), times, body)
+}
+
+func (d *dedupWriter) binary(data []byte) uint64 {
+	return reference(d.binaries, data)
+}
+
+// dedupReader keeps everything read in full so references can be expanded.
+type dedupReader struct {
+	collections []encodedCollection
+	manifests   []CollectionManifest
+	binaries    []format.Binary
+}
+
+func readReference(in io.Reader, kind string, available int) (uint64, error) {
+	ref, _, err := rapbinary.ReadUvarint(in)
+	if err != nil {
+		return 0, err
+	}
+
+	if ref > uint64(available) {
+		return 0, fmt.Errorf("%s reference out of range: %d", kind, ref)
+	}
+	return ref, nil
+}
+
+// readCollection reads a collection that may refer to one read previously,
+// copying the original when it does.
+func (d *dedupReader) readCollection(in io.Reader, opts readerOptions, readFull func() (encodedCollection, error)) (encodedCollection, error) {
+	ref, err := readReference(in, "collection", len(d.collections))
+	if err != nil {
+		return encodedCollection{}, err
+	}
+
+	if ref == 0 {
+		collection, err := readFull()
+		if err != nil {
+			return collection, err
+		}
+		d.collections = append(d.collections, collection)
+		return collection, nil
+	}
+
+	collection := d.collections[ref-1]
+	collection.name, _, err = rapbinary.ReadStringLimited(in, opts.maxLength)
+	if err != nil {
+		return encodedCollection{}, collectionError{encoder: collection.encoder.Signature(), err: err}
+	}
+
+	collection.body = append([]byte{}, collection.body...)
+	collection.times = append([]float64{}, collection.times...)
+	if collection.timeBlock != nil {
+		collection.timeBlock = append([]byte{}, collection.timeBlock...)
+	}
+	return collection, nil
+}
+
+// inspectCollection mirrors readCollection for manifests.
+func (d *dedupReader) inspectCollection(in io.Reader, opts readerOptions, inspectFull func() (CollectionManifest, error)) (CollectionManifest, error) {
+	ref, err := readReference(in, "collection", len(d.manifests))
+	if err != nil {
+		return CollectionManifest{}, err
+	}
+
+	if ref == 0 {
+		manifest, err := inspectFull()
+		if err != nil {
+			return manifest, err
+		}
+		d.manifests = append(d.manifests, manifest)
+		return manifest, nil
+	}
+
+	manifest := d.manifests[ref-1]
+	manifest.Name, _, err = rapbinary.ReadStringLimited(in, opts.maxLength)
+	if err != nil {
+		return manifest, collectionError{encoder: manifest.Signature, err: err}
+	}
+	return manifest, nil
+}
+
+// binary builds an independent copy of the binary referred to, under the
+// name and metadata provided.
+func (d *dedupReader) binary(ref uint64, name string, size uint64, block metadata.Block) (format.Binary, error) {
+	original := d.binaries[ref-1]
+	if original.Size() != size {
+		return nil, fmt.Errorf("%w: binary refers to one of %d bytes but size is %d", ErrBinarySizeMismatch, original.Size(), size)
+	}
+
+	switch b := original.(type) {
+	case Binary:
+		return NewBinary(name, append([]byte{}, b.data...), block), nil
+
+	case lazyBinary:
+		b.name = name
+		b.block = block
+		return b, nil
+
+	case skippedBinary:
+		b.name = name
+		b.block = block
+		return b, nil
+	}
+
+	return nil, fmt.Errorf("can not copy binary of type %T", original)
+}
@@ -0,0 +1,131 @@
+package io_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/position"
+	"github.com/recolude/rap/format/io"
+	"github.com/recolude/rap/format/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+// buildDedupTestRecording attaches the same calibration file and static prop
+// positions to every sub-recording.
+func buildDedupTestRecording(t *testing.T) format.Recording {
+	calibration := make([]byte, 4096)
+	_, err := rand.Read(calibration)
+	assert.NoError(t, err)
+
+	props := make([]position.Capture, 0)
+	for i := 0; i < 100; i++ {
+		props = append(props, position.NewCapture(float64(i), 1, 2, 3))
+	}
+
+	children := make([]format.Recording, 0)
+	for i := 0; i < 5; i++ {
+		children = append(children, format.NewRecording(
+			fmt.Sprintf("c%d", i),
+			fmt.Sprintf("Child %d", i),
+			[]format.CaptureCollection{
+				position.NewCollection(fmt.Sprintf("Prop %d", i), props),
+				position.NewCollection("Player", []position.Capture{
+					position.NewCapture(0, float64(i), 0, 0),
+				}),
+			},
+			nil,
+			metadata.EmptyBlock(),
+			[]format.Binary{
+				io.NewBinary("calibration", calibration, metadata.NewBlock(map[string]metadata.Property{
+					"child": metadata.NewIntProperty(i),
+				})),
+				io.NewBinary(fmt.Sprintf("notes %d", i), []byte(fmt.Sprintf("notes %d", i)), metadata.EmptyBlock()),
+			},
+			nil,
+		))
+	}
+
+	return format.NewRecording("root", "Session", nil, children, metadata.EmptyBlock(), nil, nil)
+}
+
+func Test_Deduplicate_RoundTrip(t *testing.T) {
+	tests := map[string][]io.WriterOption{
+		"none":      {io.Compression(io.CodecNone)},
+		"zstd":      {io.Compression(io.CodecZstd)},
+		"checksums": {io.Compression(io.CodecNone), io.Checksums()},
+	}
+
+	for name, options := range tests {
+		t.Run(name, func(t *testing.T) {
+			// ARRANGE ========================================================
+			recIn := buildDedupTestRecording(t)
+			deduplicated := new(bytes.Buffer)
+
+			// ACT ============================================================
+			n, errWrite := io.NewWriter(codecTestEncoders(), false, deduplicated, io.Raw64, append(options, io.Deduplicate())...).Write(recIn)
+			recOut, nOut, errRead := io.NewReader(codecTestEncoders(), bytes.NewReader(deduplicated.Bytes())).Read()
+			report, errVerify := io.NewReader(codecTestEncoders(), bytes.NewReader(deduplicated.Bytes())).Verify()
+			manifest, errInspect := io.Inspect(bytes.NewReader(deduplicated.Bytes()))
+
+			// ASSERT =========================================================
+			assert.NoError(t, errWrite)
+			assert.NoError(t, errRead)
+			assert.NoError(t, errVerify)
+			assert.NoError(t, errInspect)
+			assert.Equal(t, n, nOut)
+			assertRecordingsMatch(t, recIn, recOut, 0)
+			assert.Empty(t, report.Damaged)
+			assert.True(t, manifest.Deduplicated)
+			if assert.Len(t, manifest.Recording.Recordings, 5) {
+				for i, child := range manifest.Recording.Recordings {
+					if assert.Len(t, child.Collections, 2) {
+						assert.Equal(t, fmt.Sprintf("Prop %d", i), child.Collections[0].Name)
+						assert.Equal(t, 100, child.Collections[0].Captures)
+						assert.Equal(t, 99.0, child.Collections[0].End)
+					}
+				}
+			}
+		})
+	}
+}
+
+func Test_Deduplicate_StoresDuplicatesOnce(t *testing.T) {
+	// ARRANGE ================================================================
+	recIn := buildDedupTestRecording(t)
+	full := new(bytes.Buffer)
+	deduplicated := new(bytes.Buffer)
+
+	// ACT ====================================================================
+	_, errFull := io.NewWriter(codecTestEncoders(), false, full, io.Raw64).Write(recIn)
+	_, errWrite := io.NewWriter(codecTestEncoders(), false, deduplicated, io.Raw64, io.Deduplicate()).Write(recIn)
+
+	// ASSERT =================================================================
+	assert.NoError(t, errFull)
+	assert.NoError(t, errWrite)
+
+	// Four of the five copies of both the calibration file and the props'
+	// 24 byte positions are never written
+	assert.Less(t, deduplicated.Len(), full.Len()-4*4096-4*100*24)
+}
+
+func Test_Deduplicate_Combined(t *testing.T) {
+	// ARRANGE ================================================================
+	keys := io.StaticKey(newEncryptionKey(t))
+	public, private := newSigningKey(t)
+	recIn := buildDedupTestRecording(t)
+	fileData := new(bytes.Buffer)
+
+	// ACT ====================================================================
+	_, errWrite := io.NewWriter(codecTestEncoders(), true, fileData, io.Raw64, io.Deduplicate(), io.Checksums(), io.Encryption(keys, "key-1"), io.Sign(private)).Write(recIn)
+	recOut, _, errRead := io.NewReader(codecTestEncoders(), bytes.NewReader(fileData.Bytes()), io.Decryption(keys), io.TrustedKeys(public)).Read()
+	_, errIndexed := io.NewIndexedWriter(codecTestEncoders(), true, new(bytes.Buffer), io.Raw64, io.Deduplicate()).Write(recIn)
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	assert.NoError(t, errRead)
+	assert.Error(t, errIndexed)
+	assertRecordingsMatch(t, recIn, recOut, 0)
+}
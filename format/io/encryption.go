@@ -0,0 +1,306 @@
+package io
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	rapbinary "github.com/recolude/rap/internal/io/binary"
+)
+
+// The contents of an encrypted v2 recording are compressed as usual, and then
+// encrypted with AES-256-GCM in segments:
+//
+//   [12 bytes] base nonce
+//   [segment]... each being:
+//     [1 byte]  1 when this is the final segment, 0 otherwise
+//     [uvarint] length of the sealed segment
+//     [bytes]   sealed segment, at most 64 KiB before sealing
+//
+// Each segment's nonce is the base nonce with it's index xor'd into the final
+// 8 bytes, and the final flag is authenticated so segments can't be dropped,
+// reordered or truncated. The cleartext header preceding the contents is
+// authenticated along with the first segment, so it can't be altered without
+// being caught either. Only the signed feature flag and the header's checksum
+// are left out, as signing a recording changes both afterwards. The ID of the
+// key used is written in the clear within the header, alongside the version
+// and encoders, so recordings can be routed without the key.
+//
+// Binaries are not encrypted on their own. Their data is written within the
+// contents like in any other v2 recording, so it's encrypted along with
+// everything around it and streamed through the segments as it's read.
+// Sealing each binary separately on top of that would encrypt it twice and
+// require holding it in memory to open it. The trade off is that a binary
+// can't be decrypted without decrypting (and decompressing) everything
+// written before it, and can't be read in place from the source like binaries
+// of uncompressed, unencrypted recordings can.
+
+const encryptedSegmentSize = 64 * 1024
+
+var (
+	// ErrEncrypted is returned when reading an encrypted recording without
+	// a KeyProvider.
+	ErrEncrypted = errors.New("recording is encrypted and no key provider was given")
+
+	// ErrDecryption is returned when an encrypted recording can't be
+	// decrypted, either from the wrong key being provided or the recording
+	// having been altered.
+	ErrDecryption = errors.New("recording could not be decrypted")
+)
+
+// KeyProvider supplies the AES-256 keys recordings are encrypted with.
+type KeyProvider interface {
+	// Key returns the 32 byte key with the ID provided.
+	Key(id string) ([]byte, error)
+}
+
+// KeyProviderFunc allows a function to be used as a KeyProvider.
+type KeyProviderFunc func(id string) ([]byte, error)
+
+func (f KeyProviderFunc) Key(id string) ([]byte, error) {
+	return f(id)
+}
+
+// StaticKey provides the same key regardless of the ID asked for.
+func StaticKey(key []byte) KeyProvider {
+	return KeyProviderFunc(func(id string) ([]byte, error) {
+		return key, nil
+	})
+}
+
+// Encryption has the writer encrypt the contents of recordings with the key
+// the provider has for the ID given. The ID is written to the recording so
+// the key can be found again when reading. Binaries are encrypted as part of
+// the contents rather than each on their own, so reading one means
+// decrypting everything written before it. Only supported by the v2 layout.
+func Encryption(keys KeyProvider, keyID string) WriterOption {
+	return func(options *writerOptions) {
+		options.keys = keys
+		options.keyID = keyID
+	}
+}
+
+// Decryption provides the keys needed to read encrypted recordings.
+func Decryption(keys KeyProvider) ReaderOption {
+	return func(options *readerOptions) {
+		options.keys = keys
+	}
+}
+
+// newCipher builds the cipher for the key the provider has for the ID given.
+func newCipher(keys KeyProvider, keyID string) (cipher.AEAD, error) {
+	key, err := keys.Key(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption keys must be 32 bytes for AES-256, found %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// segmentNonce derives the nonce of a segment from the stream's base nonce.
+func segmentNonce(base []byte, index uint64) []byte {
+	nonce := append([]byte{}, base...)
+	counter := nonce[len(nonce)-8:]
+	binary.BigEndian.PutUint64(counter, binary.BigEndian.Uint64(counter)^index)
+	return nonce
+}
+
+// authenticatedHeader is the header the first segment of an encrypted
+// recording is authenticated with, leaving out what SignRecording changes.
+func authenticatedHeader(versionAndEncoders []byte, codec CodecID, features uint64, keyID string) []byte {
+	header := bytes.NewBuffer(append([]byte{}, versionAndEncoders...))
+	header.WriteByte(byte(codec) | featureFlag)
+	writeUvarint(header, features&^featureSigned)
+	header.Write(rapbinary.StringToBytes(keyID))
+	return header.Bytes()
+}
+
+// segmentAdditionalData is what's authenticated alongside a segment, being
+// it's final flag and, for the first segment, the cleartext header.
+func segmentAdditionalData(finalByte byte, index uint64, header []byte) []byte {
+	if index != 0 {
+		return []byte{finalByte}
+	}
+	return append([]byte{finalByte}, header...)
+}
+
+// encryptingWriter seals everything written through it in segments.
+type encryptingWriter struct {
+	out     io.Writer
+	aead    cipher.AEAD
+	nonce   []byte
+	header  []byte
+	segment uint64
+	buffer  []byte
+}
+
+func newEncryptingWriter(out io.Writer, aead cipher.AEAD, header []byte) (*encryptingWriter, error) {
+	nonce := make([]byte, aead.NonceSize())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = out.Write(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptingWriter{out: out, aead: aead, nonce: nonce, header: header}, nil
+}
+
+func (e *encryptingWriter) Write(p []byte) (int, error) {
+	e.buffer = append(e.buffer, p...)
+
+	// Always hold onto something so the final segment is never empty
+	// unless the entire stream is
+	for len(e.buffer) > encryptedSegmentSize {
+		err := e.seal(e.buffer[:encryptedSegmentSize], false)
+		if err != nil {
+			return 0, err
+		}
+		e.buffer = e.buffer[encryptedSegmentSize:]
+	}
+	return len(p), nil
+}
+
+func (e *encryptingWriter) seal(plaintext []byte, final bool) error {
+	finalByte := byte(0)
+	if final {
+		finalByte = 1
+	}
+
+	additionalData := segmentAdditionalData(finalByte, e.segment, e.header)
+	sealed := e.aead.Seal(nil, segmentNonce(e.nonce, e.segment), plaintext, additionalData)
+	e.segment++
+
+	ew := &errWriter{Writer: e.out}
+	ew.Write([]byte{finalByte})
+	writeUvarint(ew, uint64(len(sealed)))
+	ew.Write(sealed)
+	return ew.err
+}
+
+// Close seals whatever remains as the final segment. Does nothing when the
+// recording isn't being encrypted.
+func (e *encryptingWriter) Close() error {
+	if e == nil {
+		return nil
+	}
+	return e.seal(e.buffer, true)
+}
+
+// decryptingReader opens the segments of an encrypted stream.
+type decryptingReader struct {
+	in        io.Reader
+	aead      cipher.AEAD
+	nonce     []byte
+	header    []byte
+	segment   uint64
+	plaintext []byte
+	done      bool
+}
+
+func newDecryptingReader(in io.Reader, aead cipher.AEAD, header []byte) (*decryptingReader, error) {
+	nonce := make([]byte, aead.NonceSize())
+	_, err := io.ReadFull(in, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	// The first segment is opened right away, so the wrong key or an altered
+	// header is reported before anything is decoded
+	d := &decryptingReader{in: in, aead: aead, nonce: nonce, header: header}
+	err = d.open()
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *decryptingReader) Read(p []byte) (int, error) {
+	for len(d.plaintext) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+
+		err := d.open()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, d.plaintext)
+	d.plaintext = d.plaintext[n:]
+	return n, nil
+}
+
+func (d *decryptingReader) open() error {
+	finalByte := []byte{0}
+	_, err := io.ReadFull(d.in, finalByte)
+	if err != nil {
+		return noEOF(err)
+	}
+
+	length, _, err := rapbinary.ReadUvarint(d.in)
+	if err != nil {
+		return noEOF(err)
+	}
+
+	if length > uint64(encryptedSegmentSize+d.aead.Overhead()) {
+		return fmt.Errorf("%w: segment of %d bytes is too large", ErrDecryption, length)
+	}
+
+	sealed := make([]byte, length)
+	_, err = io.ReadFull(d.in, sealed)
+	if err != nil {
+		return noEOF(err)
+	}
+
+	additionalData := segmentAdditionalData(finalByte[0], d.segment, d.header)
+	d.plaintext, err = d.aead.Open(sealed[:0], segmentNonce(d.nonce, d.segment), sealed, additionalData)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrDecryption, err.Error())
+	}
+	d.segment++
+	d.done = finalByte[0] == 1
+	return nil
+}
+
+// noEOF reports running out of segments before the final one as the stream
+// being truncated rather than ending normally.
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// decrypt wraps the encrypted contents of a recording, bound to the cleartext
+// header that came before them.
+func (r Reader) decrypt(in io.Reader, versionAndEncoders []byte, features recordingFeatures) (io.Reader, error) {
+	if r.options.keys == nil {
+		return nil, ErrEncrypted
+	}
+
+	aead, err := newCipher(r.options.keys, features.keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	header := authenticatedHeader(versionAndEncoders, features.codec.ID(), features.features, features.keyID)
+	return newDecryptingReader(in, aead, header)
+}
@@ -0,0 +1,288 @@
+package io_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/event"
+	"github.com/recolude/rap/format/io"
+	"github.com/recolude/rap/format/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func newEncryptionKey(t *testing.T) []byte {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+	return key
+}
+
+// buildEncryptionTestRecording includes a binary large enough to span several
+// encrypted segments.
+func buildEncryptionTestRecording(t *testing.T) format.Recording {
+	audio := make([]byte, 200*1024)
+	_, err := rand.Read(audio)
+	assert.NoError(t, err)
+
+	return format.NewRecording(
+		"root",
+		"Session",
+		[]format.CaptureCollection{
+			event.NewCollection("Session Events", []event.Capture{
+				event.NewCapture(0, "Start", metadata.EmptyBlock()),
+			}),
+		},
+		[]format.Recording{buildIndexedTestRecording()},
+		metadata.EmptyBlock(),
+		[]format.Binary{
+			io.NewBinary("microphone", audio, metadata.EmptyBlock()),
+		},
+		nil,
+	)
+}
+
+func Test_Encryption_RoundTrip(t *testing.T) {
+	tests := map[string][]io.WriterOption{
+		"none":      {io.Compression(io.CodecNone)},
+		"flate":     {io.Compression(io.CodecFlate)},
+		"zstd":      {io.Compression(io.CodecZstd)},
+		"checksums": {io.Compression(io.CodecZstd), io.Checksums()},
+	}
+
+	for name, options := range tests {
+		t.Run(name, func(t *testing.T) {
+			// ARRANGE ========================================================
+			keys := io.StaticKey(newEncryptionKey(t))
+			fileData := new(bytes.Buffer)
+			recIn := buildEncryptionTestRecording(t)
+
+			// ACT ============================================================
+			n, errWrite := io.NewWriter(codecTestEncoders(), false, fileData, io.Raw64, append(options, io.Encryption(keys, "key-1"))...).Write(recIn)
+			recOut, nOut, errRead := io.NewReader(codecTestEncoders(), bytes.NewReader(fileData.Bytes()), io.Decryption(keys)).Read()
+			report, errVerify := io.NewReader(codecTestEncoders(), bytes.NewReader(fileData.Bytes()), io.Decryption(keys)).Verify()
+			manifest, errInspect := io.Inspect(bytes.NewReader(fileData.Bytes()), io.Decryption(keys))
+
+			// ASSERT =========================================================
+			assert.NoError(t, errWrite)
+			assert.NoError(t, errRead)
+			assert.NoError(t, errVerify)
+			assert.NoError(t, errInspect)
+			assert.Equal(t, n, nOut)
+			assertRecordingsMatch(t, recIn, recOut, 0)
+			assert.Empty(t, report.Damaged)
+			assert.True(t, manifest.Encrypted)
+			assert.Equal(t, "key-1", manifest.KeyID)
+			assert.Len(t, manifest.Recording.Recordings, 1)
+		})
+	}
+}
+
+func Test_Encryption_SignedRoundTrip(t *testing.T) {
+	// ARRANGE ================================================================
+	keys := io.StaticKey(newEncryptionKey(t))
+	public, private := newSigningKey(t)
+	fileData := new(bytes.Buffer)
+	recIn := buildEncryptionTestRecording(t)
+
+	// ACT ====================================================================
+	_, errWrite := io.NewWriter(codecTestEncoders(), true, fileData, io.Raw64, io.Encryption(keys, "key-1"), io.Sign(private)).Write(recIn)
+	recOut, _, errRead := io.NewReader(codecTestEncoders(), bytes.NewReader(fileData.Bytes()), io.Decryption(keys), io.TrustedKeys(public)).Read()
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	assert.NoError(t, errRead)
+	assertRecordingsMatch(t, recIn, recOut, 0)
+}
+
+func Test_Encryption_StreamsLargeBinaries(t *testing.T) {
+	// ARRANGE ================================================================
+	tempDir := t.TempDir()
+	t.Setenv("TMPDIR", tempDir)
+
+	keys := io.StaticKey(newEncryptionKey(t))
+	fileData := new(bytes.Buffer)
+	large := bytes.Repeat([]byte("0123456789"), 200000)
+	recIn := buildBinaryTestRecording(large)
+
+	// ACT ====================================================================
+	_, errWrite := io.NewWriter(codecTestEncoders(), true, fileData, io.Raw64, io.Encryption(keys, "key-1")).Write(recIn)
+	recOut, _, errRead := io.NewReader(codecTestEncoders(), bytes.NewReader(fileData.Bytes()), io.Decryption(keys)).Read()
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	if !assert.NoError(t, errRead) {
+		return
+	}
+	assertRecordingsMatch(t, recIn, recOut, 0)
+
+	// Large binaries are spilled to disk as they're decrypted rather than
+	// held in memory
+	spilled, _ := os.ReadDir(tempDir)
+	assert.Len(t, spilled, 1)
+	assert.NoError(t, io.CloseBinaries(recOut))
+}
+
+func Test_Encryption_HeaderStaysReadable(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	_, err := io.NewWriter(codecTestEncoders(), false, fileData, io.Raw64, io.Encryption(io.StaticKey(newEncryptionKey(t)), "key-1")).Write(buildIndexedTestRecording())
+	assert.NoError(t, err)
+
+	// ACT ====================================================================
+	manifest, errInspect := io.Inspect(bytes.NewReader(fileData.Bytes()))
+	_, _, errRead := io.NewReader(codecTestEncoders(), bytes.NewReader(fileData.Bytes())).Read()
+
+	// ASSERT =================================================================
+	assert.ErrorIs(t, errInspect, io.ErrEncrypted)
+	assert.ErrorIs(t, errRead, io.ErrEncrypted)
+	assert.Equal(t, 2, manifest.Version)
+	assert.Len(t, manifest.Encoders, 2)
+	assert.True(t, manifest.Encrypted)
+	assert.Equal(t, "key-1", manifest.KeyID)
+	assert.NotContains(t, fileData.String(), "hello p1")
+	assert.NotContains(t, fileData.String(), "Session")
+}
+
+func Test_Encryption_KeysAreFoundByID(t *testing.T) {
+	// ARRANGE ================================================================
+	stored := map[string][]byte{
+		"old": newEncryptionKey(t),
+		"new": newEncryptionKey(t),
+	}
+	requested := make([]string, 0)
+	keys := io.KeyProviderFunc(func(id string) ([]byte, error) {
+		requested = append(requested, id)
+		key, ok := stored[id]
+		if !ok {
+			return nil, errors.New("no such key")
+		}
+		return key, nil
+	})
+
+	fileData := new(bytes.Buffer)
+	recIn := buildIndexedTestRecording()
+
+	// ACT ====================================================================
+	_, errWrite := io.NewWriter(codecTestEncoders(), true, fileData, io.Raw64, io.Encryption(keys, "new")).Write(recIn)
+	recOut, _, errRead := io.NewReader(codecTestEncoders(), bytes.NewReader(fileData.Bytes()), io.Decryption(keys)).Read()
+	_, errMissing := io.NewWriter(codecTestEncoders(), true, new(bytes.Buffer), io.Raw64, io.Encryption(keys, "missing")).Write(recIn)
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	assert.NoError(t, errRead)
+	assert.Error(t, errMissing)
+	assert.Equal(t, []string{"new", "new", "missing"}, requested)
+	assertRecordingsMatch(t, recIn, recOut, 0)
+}
+
+func Test_Encryption_Rejections(t *testing.T) {
+	// ARRANGE ================================================================
+	keys := io.StaticKey(newEncryptionKey(t))
+	fileData := new(bytes.Buffer)
+	_, err := io.NewWriter(codecTestEncoders(), false, fileData, io.Raw64, io.Encryption(keys, "")).Write(buildEncryptionTestRecording(t))
+	assert.NoError(t, err)
+
+	tampered := append([]byte{}, fileData.Bytes()...)
+	tampered[len(tampered)-1] ^= 0xFF
+
+	truncated := fileData.Bytes()[:fileData.Len()/2]
+
+	// ACT ====================================================================
+	_, _, errWrongKey := io.NewReader(codecTestEncoders(), bytes.NewReader(fileData.Bytes()), io.Decryption(io.StaticKey(newEncryptionKey(t)))).Read()
+	_, _, errTampered := io.NewReader(codecTestEncoders(), bytes.NewReader(tampered), io.Decryption(keys)).Read()
+	_, _, errTruncated := io.NewReader(codecTestEncoders(), bytes.NewReader(truncated), io.Decryption(keys)).Read()
+	_, errShortKey := io.NewWriter(codecTestEncoders(), false, new(bytes.Buffer), io.Raw64, io.Encryption(io.StaticKey([]byte("short")), "")).Write(buildIndexedTestRecording())
+	_, errIndexed := io.NewIndexedWriter(codecTestEncoders(), false, new(bytes.Buffer), io.Raw64, io.Encryption(keys, "")).Write(buildIndexedTestRecording())
+
+	// ASSERT =================================================================
+	assert.ErrorIs(t, errWrongKey, io.ErrDecryption)
+	assert.ErrorIs(t, errTampered, io.ErrDecryption)
+	assert.Error(t, errTruncated)
+	assert.Error(t, errShortKey)
+	assert.Error(t, errIndexed)
+}
+
+func Test_Encryption_HeaderIsAuthenticated(t *testing.T) {
+	// ARRANGE ================================================================
+	keys := io.StaticKey(newEncryptionKey(t))
+	fileData := new(bytes.Buffer)
+	_, errWrite := io.NewWriter(codecTestEncoders(), false, fileData, io.Raw64, io.Encryption(keys, "key-1")).Write(buildEncryptionTestRecording(t))
+
+	// The key provider hands back the same key for any ID, so only the
+	// header being authenticated can catch it changing
+	tampered := bytes.Replace(fileData.Bytes(), []byte("key-1"), []byte("key-2"), 1)
+
+	// ACT ====================================================================
+	_, _, errRead := io.NewReader(codecTestEncoders(), bytes.NewReader(tampered), io.Decryption(keys)).Read()
+	_, errInspect := io.Inspect(bytes.NewReader(tampered), io.Decryption(keys))
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	assert.NotEqual(t, fileData.Bytes(), tampered)
+	assert.ErrorIs(t, errRead, io.ErrDecryption)
+	assert.ErrorIs(t, errInspect, io.ErrDecryption)
+}
+
+func Test_Encryption_SignRecordingAfterEncrypting(t *testing.T) {
+	for _, checksums := range []bool{false, true} {
+		t.Run(fmt.Sprintf("checksums %t", checksums), func(t *testing.T) {
+			// ARRANGE ========================================================
+			keys := io.StaticKey(newEncryptionKey(t))
+			public, private := newSigningKey(t)
+			options := []io.WriterOption{io.Encryption(keys, "key-1")}
+			if checksums {
+				options = append(options, io.Checksums())
+			}
+
+			encrypted := new(bytes.Buffer)
+			recIn := buildEncryptionTestRecording(t)
+			_, errWrite := io.NewWriter(codecTestEncoders(), true, encrypted, io.Raw64, options...).Write(recIn)
+
+			// ACT ============================================================
+			signed := new(bytes.Buffer)
+			_, errSign := io.SignRecording(bytes.NewReader(encrypted.Bytes()), signed, private)
+			report, errVerify := io.NewReader(codecTestEncoders(), bytes.NewReader(signed.Bytes()), io.Decryption(keys), io.TrustedKeys(public)).Verify()
+			recOut, _, errRead := io.NewReader(codecTestEncoders(), bytes.NewReader(signed.Bytes()), io.Decryption(keys), io.TrustedKeys(public)).Read()
+
+			// ASSERT =========================================================
+			assert.NoError(t, errWrite)
+			assert.NoError(t, errSign)
+			assert.NoError(t, errVerify)
+			assert.Empty(t, report.Damaged)
+			if assert.NoError(t, errRead) {
+				assertRecordingsMatch(t, recIn, recOut, 0)
+			}
+		})
+	}
+}
+
+func Test_Encryption_FailuresAreNotBlamedOnEncoders(t *testing.T) {
+	// ARRANGE ================================================================
+	keys := io.StaticKey(newEncryptionKey(t))
+	fileData := new(bytes.Buffer)
+	_, errWrite := io.NewWriter(codecTestEncoders(), false, fileData, io.Raw64, io.Encryption(keys, "key-1")).Write(buildEncryptionTestRecording(t))
+
+	tampered := append([]byte{}, fileData.Bytes()...)
+	tampered[len(tampered)-1] ^= 0xFF
+
+	// ACT ====================================================================
+	_, _, errWrongKey := io.NewReader(codecTestEncoders(), bytes.NewReader(fileData.Bytes()), io.Decryption(io.StaticKey(newEncryptionKey(t)))).Read()
+	_, _, errTampered := io.NewReader(codecTestEncoders(), bytes.NewReader(tampered), io.Decryption(keys)).Read()
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	for _, err := range []error{errWrongKey, errTampered} {
+		assert.ErrorIs(t, err, io.ErrDecryption)
+
+		var decodeErr *io.DecodeError
+		if assert.ErrorAs(t, err, &decodeErr) {
+			assert.Empty(t, decodeErr.Encoder)
+			assert.Empty(t, decodeErr.Collection)
+		}
+	}
+}
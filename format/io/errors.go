@@ -0,0 +1,165 @@
+package io
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+var (
+	// ErrUnknownEncoder is returned when a recording contains a collection
+	// encoded with an encoder the reader does not have registered.
+	ErrUnknownEncoder = errors.New("no registered encoder has signature")
+
+	// ErrEncoderTooOld is returned when the registered encoder's version is
+	// behind the version found within the recording.
+	ErrEncoderTooOld = errors.New("registered encoder version is behind what is found in recording")
+
+	// ErrUnsupportedVersion is returned when the recording's file version is
+	// not one the reader understands.
+	ErrUnsupportedVersion = errors.New("Unrecognized file version")
+
+	// ErrTruncated is returned when the recording ends before all of it's
+	// contents could be read.
+	ErrTruncated = errors.New("recording is truncated")
+
+	// ErrBinarySizeMismatch is returned when the data of a binary being
+	// written does not match the size it reports.
+	ErrBinarySizeMismatch = errors.New("binary data does not match its size")
+)
+
+// pathSegment is how a recording is referred to within an error's path.
+func pathSegment(id, name string) string {
+	if id == "" {
+		return name
+	}
+	return id
+}
+
+// DecodeError describes where within a recording decoding failed.
+type DecodeError struct {
+	// Offset is the number of bytes into the recording at which decoding
+	// failed. Within compressed sections, bytes are counted in their
+	// decompressed form.
+	Offset int64
+
+	// Path is the ID (or name when no ID is present) of every recording from
+	// the root down to the one being decoded.
+	Path []string
+
+	// Collection is the name of the capture collection being decoded, if any.
+	Collection string
+
+	// Encoder is the signature of the encoder involved, if any.
+	Encoder string
+
+	// Binary is the name of the embedded binary being decoded, if any.
+	Binary string
+
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	builder := strings.Builder{}
+	fmt.Fprintf(&builder, "decoding recording at byte %d", e.Offset)
+
+	if len(e.Path) > 0 {
+		fmt.Fprintf(&builder, ", recording %s", strings.Join(e.Path, "/"))
+	}
+
+	if e.Collection != "" {
+		fmt.Fprintf(&builder, ", collection %s", e.Collection)
+	}
+
+	if e.Encoder != "" {
+		fmt.Fprintf(&builder, ", encoder %s", e.Encoder)
+	}
+
+	if e.Binary != "" {
+		fmt.Fprintf(&builder, ", binary %s", e.Binary)
+	}
+
+	fmt.Fprintf(&builder, ": %s", e.Err.Error())
+	return builder.String()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// truncatedError marks an unexpected end of data as ErrTruncated while still
+// exposing the original error.
+type truncatedError struct {
+	err error
+}
+
+func (e truncatedError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrTruncated.Error(), e.err.Error())
+}
+
+func (e truncatedError) Is(target error) bool {
+	return target == ErrTruncated
+}
+
+func (e truncatedError) Unwrap() error {
+	return e.err
+}
+
+func classifyDecodeError(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return truncatedError{err: err}
+	}
+	return err
+}
+
+// countingReader keeps track of how many bytes have been read through it so
+// errors can report where they occurred. An io.EOF accompanying data is held
+// back until the next read, as the binary helpers treat any error as fatal.
+// When a hash is set, everything read is also written to it.
+type countingReader struct {
+	io.Reader
+	n    int64
+	hash hash.Hash32
+}
+
+func (c *countingReader) skip(n int64) error {
+	err := skip(c.Reader, n)
+	if err == nil {
+		c.n += n
+	}
+	return err
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.Reader.Read(p)
+	c.n += int64(n)
+	if c.hash != nil {
+		c.hash.Write(p[:n])
+	}
+	if n > 0 && err == io.EOF {
+		return n, nil
+	}
+	return n, err
+}
+
+// BinaryError describes which embedded binary could not be written.
+type BinaryError struct {
+	// Path is the ID (or name when no ID is present) of every recording from
+	// the root down to the one containing the binary.
+	Path []string
+
+	// Binary is the name of the binary being written.
+	Binary string
+
+	Err error
+}
+
+func (e *BinaryError) Error() string {
+	return fmt.Sprintf("writing recording %s, binary %s: %s", strings.Join(e.Path, "/"), e.Binary, e.Err.Error())
+}
+
+func (e *BinaryError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,100 @@
+package io
+
+import (
+	"fmt"
+	"io"
+
+	rapbinary "github.com/recolude/rap/internal/io/binary"
+)
+
+// featureFlag marks the codec byte of a recording's header as being followed
+// by a uvarint of the features the recording was written with.
+const featureFlag = 0x80
+
+// Features a v2 recording can be written with.
+const (
+	featureChecksums uint64 = 1 << iota
+	featureSigned
+	featureEncrypted
+	featureDeduplicated
+)
+
+// supportedFeatures are all features this package knows how to read.
+const supportedFeatures = featureChecksums | featureSigned | featureEncrypted | featureDeduplicated
+
+// readFeatures reads the features a recording was written with, which are
+// only present when flagged within the codec byte.
+func readFeatures(in io.Reader, codecByte byte) (CodecID, uint64, error) {
+	if codecByte&featureFlag == 0 {
+		return CodecID(codecByte), 0, nil
+	}
+
+	features, _, err := rapbinary.ReadUvarint(in)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if unsupported := features &^ supportedFeatures; unsupported != 0 {
+		return 0, 0, fmt.Errorf("recording uses unsupported features: %d", unsupported)
+	}
+
+	return CodecID(codecByte &^ featureFlag), features, nil
+}
+
+// newHeaderReader reads a recording's header, hashing it in case the
+// recording has checksums. The version byte has already been read.
+func newHeaderReader(in io.Reader, version byte) *countingReader {
+	header := &countingReader{Reader: in, n: 1, hash: newChecksum()}
+	header.hash.Write([]byte{version})
+	return header
+}
+
+// recordingFeatures is everything following the encoders within a v2
+// header.
+type recordingFeatures struct {
+	codec    Codec
+	features uint64
+
+	// keyID identifies the key an encrypted recording was encrypted with
+	keyID string
+}
+
+func (f recordingFeatures) has(feature uint64) bool {
+	return f.features&feature != 0
+}
+
+// readCodecAndFeatures reads the codec and features ending a v2 header,
+// verifying the header against it's checksum when the recording has them.
+func readCodecAndFeatures(header *countingReader, maxLength uint64, mismatch func(checksumError) error) (recordingFeatures, error) {
+	result := recordingFeatures{}
+
+	codecByte := []byte{0}
+	_, err := io.ReadFull(header, codecByte)
+	if err != nil {
+		return result, err
+	}
+
+	codecID, features, err := readFeatures(header, codecByte[0])
+	if err != nil {
+		return result, err
+	}
+	result.features = features
+
+	if result.has(featureEncrypted) {
+		result.keyID, _, err = rapbinary.ReadStringLimited(header, maxLength)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if result.has(featureChecksums) {
+		sums := &sectionChecksums{in: header, mismatch: mismatch}
+		err = sums.verify("", "")
+		if err != nil {
+			return result, err
+		}
+	}
+
+	result.codec, err = LookupCodec(codecID)
+	return result, err
+}
@@ -0,0 +1,686 @@
+package io
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/encoding"
+	rapbinary "github.com/recolude/rap/internal/io/binary"
+)
+
+// The indexed (v3) layout looks like:
+//
+//   [1 byte]   version (3)
+//   [encoders] signatures and versions, same as v2
+//   [1 byte]   compression codec id
+//   [block]    encoder headers and metadata keys
+//   [block]... one per recording node, followed by one per collection
+//   [index]    table of contents describing where every block lives
+//   [8 bytes]  little endian offset of the index from the start of the file
+//
+// Every block is compressed independently so any sub-recording can be decoded
+// without touching the rest of the file.
+
+const indexedFooterSize = 8
+
+type indexedCollection struct {
+	name   string
+	offset uint64
+	length uint64
+}
+
+type indexEntry struct {
+	id          string
+	name        string
+	offset      uint64
+	length      uint64
+	collections []indexedCollection
+	children    []uint64
+}
+
+type recordingIndex struct {
+	encoders     []encoding.Encoder
+	codec        Codec
+	headerOffset uint64
+	headerLength uint64
+	entries      []indexEntry
+}
+
+// writeBlock writes the contents produced by fn to the output as a single
+// block, compressed with the writer's codec. The number of bytes written is
+// returned.
+func (w Writer) writeBlock(out io.Writer, fn func(io.Writer) error) (int, error) {
+	codec, level, err := w.codec()
+	if err != nil {
+		return 0, err
+	}
+
+	buffer := bytes.Buffer{}
+	compressWriter, err := codec.NewWriter(&buffer, level)
+	if err != nil {
+		return 0, err
+	}
+
+	err = fn(compressWriter)
+	if err != nil {
+		return 0, err
+	}
+
+	err = compressWriter.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	return out.Write(buffer.Bytes())
+}
+
+func writeIndex(out io.Writer, index recordingIndex) (int, error) {
+	ew := &errWriter{Writer: out}
+
+	writeUvarint(ew, index.headerOffset)
+	writeUvarint(ew, index.headerLength)
+
+	writeUvarint(ew, uint64(len(index.entries)))
+	for _, entry := range index.entries {
+		ew.Write(rapbinary.StringToBytes(entry.id))
+		ew.Write(rapbinary.StringToBytes(entry.name))
+		writeUvarint(ew, entry.offset)
+		writeUvarint(ew, entry.length)
+
+		writeUvarint(ew, uint64(len(entry.collections)))
+		for _, collection := range entry.collections {
+			ew.Write(rapbinary.StringToBytes(collection.name))
+			writeUvarint(ew, collection.offset)
+			writeUvarint(ew, collection.length)
+		}
+
+		writeUvarint(ew, uint64(len(entry.children)))
+		for _, child := range entry.children {
+			writeUvarint(ew, child)
+		}
+	}
+
+	return ew.TotalWritten(), ew.err
+}
+
+func (w Writer) writeIndexedNode(out *errWriter, recording format.Recording, encoded *encodedRecording, index *recordingIndex, path []string, streamOffset int) (int, error) {
+	path = append(path, pathSegment(recording.ID(), recording.Name()))
+	entryIndex := len(index.entries)
+	index.entries = append(index.entries, indexEntry{
+		id:     recording.ID(),
+		name:   recording.Name(),
+		offset: uint64(out.TotalWritten()),
+	})
+
+	written, err := w.writeBlock(out, func(blockOut io.Writer) error {
+		writeRecordingHeader(blockOut, recording, encoded.keyMappingToIndex)
+		return writeBinaries(blockOut, recording, encoded.keyMappingToIndex, path, w.options, nil, nil)
+	})
+	if err != nil {
+		return streamOffset, err
+	}
+	index.entries[entryIndex].length = uint64(written)
+
+	for i, collection := range recording.CaptureCollections() {
+		collectionOffset := out.TotalWritten()
+		written, err := w.writeBlock(out, func(blockOut io.Writer) error {
+			times, err := w.encodeTimes(collection)
+			if err != nil {
+				return err
+			}
+
+			return writeCollection(
+				blockOut,
+				collection,
+				encoded.streamIndexToEncoderUsedIndex[streamOffset+i],
+				encoded.encodingBlocks[streamOffset+i],
+				times,
+				nil,
+			)
+		})
+		if err != nil {
+			return streamOffset, err
+		}
+		index.entries[entryIndex].collections = append(index.entries[entryIndex].collections, indexedCollection{
+			name:   collection.Name(),
+			offset: uint64(collectionOffset),
+			length: uint64(written),
+		})
+	}
+
+	newOffset := streamOffset + len(recording.CaptureCollections())
+	for _, child := range recording.Recordings() {
+		index.entries[entryIndex].children = append(index.entries[entryIndex].children, uint64(len(index.entries)))
+		newOffset, err = w.writeIndexedNode(out, child, encoded, index, path, newOffset)
+		if err != nil {
+			return newOffset, err
+		}
+	}
+
+	return newOffset, nil
+}
+
+func (w Writer) writeIndexed(recording format.Recording) (int, error) {
+	if w.options.checksums {
+		return 0, errors.New("checksums are only supported by the v2 layout")
+	}
+
+	if w.options.signingKey != nil {
+		return 0, errors.New("signing is only supported by the v2 layout")
+	}
+
+	if w.options.keys != nil {
+		return 0, errors.New("encryption is only supported by the v2 layout")
+	}
+
+	if w.options.deduplicate {
+		return 0, errors.New("deduplication is only supported by the v2 layout")
+	}
+
+	codec, _, err := w.codec()
+	if err != nil {
+		return 0, err
+	}
+
+	encoded, err := w.encode(recording)
+	if err != nil {
+		return 0, err
+	}
+
+	out := &errWriter{Writer: w.out}
+
+	// Write version number
+	out.Write([]byte{3})
+
+	// Write encoders used
+	writeEncoders(out, encoded.encoderMappings)
+
+	// Write compression codec
+	out.Write([]byte{byte(codec.ID())})
+	if out.err != nil {
+		return out.TotalWritten(), out.err
+	}
+
+	index := recordingIndex{headerOffset: uint64(out.TotalWritten())}
+	written, err := w.writeBlock(out, func(blockOut io.Writer) error {
+		ew := &errWriter{Writer: blockOut}
+		for _, header := range encoded.encoderHeaders {
+			ew.Write(rapbinary.BytesArrayToBytes(header))
+		}
+		ew.Write(rapbinary.StringArrayToBytes(encoded.metadataKeys))
+		return ew.err
+	})
+	if err != nil {
+		return out.TotalWritten(), err
+	}
+	index.headerLength = uint64(written)
+
+	_, err = w.writeIndexedNode(out, recording, encoded, &index, nil, 0)
+	if err != nil {
+		return out.TotalWritten(), err
+	}
+
+	indexOffset := out.TotalWritten()
+	_, err = writeIndex(out, index)
+	if err != nil {
+		return out.TotalWritten(), err
+	}
+
+	footer := make([]byte, indexedFooterSize)
+	binary.LittleEndian.PutUint64(footer, uint64(indexOffset))
+	out.Write(footer)
+
+	return out.TotalWritten(), out.err
+}
+
+// readerAtAndSize determines whether or not the reader supports random access,
+// and if so, how large the underlying data is.
+func readerAtAndSize(in io.Reader) (io.ReaderAt, int64, error) {
+	readerAt, ok := in.(io.ReaderAt)
+	if !ok {
+		return nil, 0, errors.New("random access requires the underlying reader to implement io.ReaderAt")
+	}
+
+	seeker, ok := in.(io.Seeker)
+	if !ok {
+		return nil, 0, errors.New("random access requires the underlying reader to implement io.Seeker")
+	}
+
+	current, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	size, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	_, err = seeker.Seek(current, io.SeekStart)
+	return readerAt, size, err
+}
+
+// indexedSection narrows a reader supporting random access down to the
+// recording being read, which begins consumed bytes before the reader's
+// current position. Every offset within the recording is relative to it's
+// start, which isn't necessarily the start of the underlying data.
+func indexedSection(in io.Reader, consumed int64) (io.ReaderAt, int64, error) {
+	readerAt, size, err := readerAtAndSize(in)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	current, err := in.(io.Seeker).Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	start := current - consumed
+	if start < 0 {
+		return nil, 0, fmt.Errorf("recording can't begin before the start of the reader: %d", start)
+	}
+	return io.NewSectionReader(readerAt, start, size-start), size - start, nil
+}
+
+func (r Reader) readIndex(in io.ReaderAt, size int64) (recordingIndex, error) {
+	index := recordingIndex{}
+
+	if size < indexedFooterSize+1 {
+		return index, errors.New("file too small to contain recording index")
+	}
+
+	footer := make([]byte, indexedFooterSize)
+	_, err := in.ReadAt(footer, size-indexedFooterSize)
+	if err != nil {
+		return index, err
+	}
+	indexOffset := int64(binary.LittleEndian.Uint64(footer))
+	if indexOffset <= 0 || indexOffset > size-indexedFooterSize {
+		return index, fmt.Errorf("invalid recording index offset: %d", indexOffset)
+	}
+
+	preamble := rapbinary.NewErrReader(io.NewSectionReader(in, 0, indexOffset))
+	version, _ := preamble.ReadByte()
+	if preamble.Error() != nil {
+		return index, preamble.Error()
+	}
+	if version != 3 {
+		return index, fmt.Errorf("random access requires a v3 recording, found version: %d", version)
+	}
+
+	index.encoders, _, err = r.readEncoders(preamble)
+	if err != nil {
+		return index, err
+	}
+
+	codecByte, err := preamble.ReadByte()
+	if err != nil {
+		return index, err
+	}
+
+	codecID, features, err := readFeatures(preamble, codecByte)
+	if err != nil {
+		return index, err
+	}
+
+	if features != 0 {
+		return index, fmt.Errorf("random access recordings do not support features: %d", features)
+	}
+
+	index.codec, err = LookupCodec(codecID)
+	if err != nil {
+		return index, err
+	}
+
+	indexIn := io.NewSectionReader(in, indexOffset, size-indexedFooterSize-indexOffset)
+	index.headerOffset, _, err = rapbinary.ReadUvarint(indexIn)
+	if err != nil {
+		return index, err
+	}
+
+	index.headerLength, _, err = rapbinary.ReadUvarint(indexIn)
+	if err != nil {
+		return index, err
+	}
+
+	numEntries, _, err := rapbinary.ReadUvarint(indexIn)
+	if err != nil {
+		return index, err
+	}
+
+	err = rapbinary.CheckLimit("array length", numEntries, r.options.maxLength)
+	if err != nil {
+		return index, err
+	}
+
+	index.entries = make([]indexEntry, 0, rapbinary.InitialCapacity(numEntries))
+	for i := uint64(0); i < numEntries; i++ {
+		entry, err := r.readIndexEntry(indexIn, i, numEntries)
+		if err != nil {
+			return index, err
+		}
+		index.entries = append(index.entries, entry)
+	}
+
+	return index, nil
+}
+
+// readIndexEntry reads the i-th entry of a recording index containing
+// numEntries entries.
+func (r Reader) readIndexEntry(in io.Reader, i, numEntries uint64) (indexEntry, error) {
+	entry := indexEntry{}
+
+	var err error
+	entry.id, _, err = rapbinary.ReadStringLimited(in, r.options.maxLength)
+	if err != nil {
+		return entry, err
+	}
+
+	entry.name, _, err = rapbinary.ReadStringLimited(in, r.options.maxLength)
+	if err != nil {
+		return entry, err
+	}
+
+	entry.offset, _, err = rapbinary.ReadUvarint(in)
+	if err != nil {
+		return entry, err
+	}
+
+	entry.length, _, err = rapbinary.ReadUvarint(in)
+	if err != nil {
+		return entry, err
+	}
+
+	numCollections, _, err := rapbinary.ReadUvarint(in)
+	if err != nil {
+		return entry, err
+	}
+
+	err = rapbinary.CheckLimit("array length", numCollections, r.options.maxLength)
+	if err != nil {
+		return entry, err
+	}
+
+	entry.collections = make([]indexedCollection, 0, rapbinary.InitialCapacity(numCollections))
+	for c := uint64(0); c < numCollections; c++ {
+		collection := indexedCollection{}
+		collection.name, _, err = rapbinary.ReadStringLimited(in, r.options.maxLength)
+		if err != nil {
+			return entry, err
+		}
+
+		collection.offset, _, err = rapbinary.ReadUvarint(in)
+		if err != nil {
+			return entry, err
+		}
+
+		collection.length, _, err = rapbinary.ReadUvarint(in)
+		if err != nil {
+			return entry, err
+		}
+		entry.collections = append(entry.collections, collection)
+	}
+
+	numChildren, _, err := rapbinary.ReadUvarint(in)
+	if err != nil {
+		return entry, err
+	}
+
+	err = rapbinary.CheckLimit("array length", numChildren, r.options.maxLength)
+	if err != nil {
+		return entry, err
+	}
+
+	entry.children = make([]uint64, 0, rapbinary.InitialCapacity(numChildren))
+	for c := uint64(0); c < numChildren; c++ {
+		child, _, err := rapbinary.ReadUvarint(in)
+		if err != nil {
+			return entry, err
+		}
+
+		// Entries are written in pre-order, so children always come after
+		// their parent, which also prevents cycles within the tree
+		if child >= numEntries || child <= i {
+			return entry, fmt.Errorf("recording index references out of range entry: %d", child)
+		}
+		entry.children = append(entry.children, child)
+	}
+
+	return entry, nil
+}
+
+type indexedRecordingReader struct {
+	in             io.ReaderAt
+	index          recordingIndex
+	encoderHeaders [][]byte
+	metadataKeys   []string
+	options        readerOptions
+	decompressed   *uint64
+}
+
+// openBlock returns a reader over a single block, counting what it inflates
+// to against the recording's decompressed size limit.
+func (ir indexedRecordingReader) openBlock(offset, length uint64) *rapbinary.ErrReader {
+	block, err := ir.index.codec.NewReader(io.NewSectionReader(ir.in, int64(offset), int64(length)))
+	if err != nil {
+		block = failingReader{err: err}
+	}
+	return rapbinary.NewErrReader(newSizeLimitedReader(block, ir.decompressed, ir.options.maxDecompressedSize))
+}
+
+func (r Reader) openIndexed(in io.ReaderAt, size int64) (*indexedRecordingReader, error) {
+	index, err := r.readIndex(in, size)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(index.entries) == 0 {
+		return nil, errors.New("recording index contains no recordings")
+	}
+
+	ir := &indexedRecordingReader{
+		in:           in,
+		index:        index,
+		options:      r.options,
+		decompressed: new(uint64),
+	}
+
+	headerBlock := ir.openBlock(index.headerOffset, index.headerLength)
+	ir.encoderHeaders = make([][]byte, len(index.encoders))
+	for i := range ir.encoderHeaders {
+		ir.encoderHeaders[i], _, err = rapbinary.ReadBytesArrayLimited(headerBlock, r.options.maxDecompressedSize)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ir.metadataKeys, _, err = rapbinary.ReadStringArrayLimited(headerBlock, r.options.maxLength)
+	if err != nil {
+		return nil, err
+	}
+
+	return ir, nil
+}
+
+// readNode decodes the recording found at the entry provided along with all
+// of it's children, depth being how many recordings deep the entry is.
+func (ir indexedRecordingReader) readNode(entryIndex uint64, depth int) (format.Recording, error) {
+	entry := ir.index.entries[entryIndex]
+
+	err := rapbinary.CheckLimit("recording nesting depth", uint64(depth), uint64(ir.options.maxDepth))
+	if err != nil {
+		return nil, err
+	}
+
+	block := ir.openBlock(entry.offset, entry.length)
+	id, name, recordingMetadata, err := readRecordingHeader(block, ir.metadataKeys, ir.options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Uncompressed binaries can be read straight from the recording later
+	source := binarySource{}
+	if ir.index.codec.ID() == CodecNone {
+		source.at = ir.in
+		source.offset = func() int64 {
+			return int64(entry.offset) + int64(block.TotalRead())
+		}
+	}
+
+	references, binaries, err := readBinaries(block, ir.metadataKeys, ir.options, source, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every collection lives in it's own block, so they can all be read and
+	// decoded independently of one another
+	collections := make([]format.CaptureCollection, len(entry.collections))
+	err = runWorkers(ir.options.decodeWorkers, len(entry.collections), func(i int) error {
+		collectionEntry := entry.collections[i]
+		collectionBlock := ir.openBlock(collectionEntry.offset, collectionEntry.length)
+		collection, err := readCollection(collectionBlock, ir.index.encoders, ir.encoderHeaders, ir.options)
+		collections[i] = collection
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	children := make([]format.Recording, len(entry.children))
+	for i, child := range entry.children {
+		children[i], err = ir.readNode(child, depth+1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return format.NewRecording(id, name, collections, children, recordingMetadata, binaries, references), nil
+}
+
+// resolvePath finds the index entry the path refers to. Each segment of the
+// path is matched against the ID of a recording, falling back to it's name,
+// with the first segment referring to the root recording itself.
+func (ir indexedRecordingReader) resolvePath(path string) (uint64, error) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	matches := func(entry indexEntry, segment string) bool {
+		return entry.id == segment || entry.name == segment
+	}
+
+	if !matches(ir.index.entries[0], segments[0]) {
+		return 0, fmt.Errorf("no recording found at path: %s", path)
+	}
+
+	current := uint64(0)
+	for _, segment := range segments[1:] {
+		found := false
+
+		// Prefer matching on ID before name
+		for _, child := range ir.index.entries[current].children {
+			if ir.index.entries[child].id == segment {
+				current = child
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			for _, child := range ir.index.entries[current].children {
+				if matches(ir.index.entries[child], segment) {
+					current = child
+					found = true
+					break
+				}
+			}
+		}
+
+		if !found {
+			return 0, fmt.Errorf("no recording found at path: %s", path)
+		}
+	}
+
+	return current, nil
+}
+
+// readIndexedSequentially reads a v3 recording from a reader that may not
+// support random access. The version byte is assumed to have already been
+// consumed.
+func (r Reader) readIndexedSequentially() (format.Recording, int, error) {
+	// The version has already been read
+	if readerAt, size, err := indexedSection(r.in, 1); err == nil {
+		indexed, err := r.openIndexed(readerAt, size)
+		if err != nil {
+			return nil, 0, err
+		}
+		rec, err := indexed.readNode(0, 1)
+		return rec, int(size) - 1, err
+	}
+
+	// Without random access the whole file is buffered in memory, so it's
+	// held to the decompressed size limit too
+	in := r.in
+	if r.options.maxDecompressedSize > 0 {
+		in = io.LimitReader(r.in, int64(r.options.maxDecompressedSize)+1)
+	}
+
+	buffer := bytes.Buffer{}
+	buffer.WriteByte(3)
+	read, err := io.Copy(&buffer, in)
+	if err != nil {
+		return nil, int(read), err
+	}
+
+	if max := r.options.maxDecompressedSize; max > 0 && uint64(read) > max {
+		return nil, int(read), LimitError{Limit: "buffered input size", Value: uint64(read), Max: max}
+	}
+
+	data := bytes.NewReader(buffer.Bytes())
+	indexed, err := r.openIndexed(data, data.Size())
+	if err != nil {
+		return nil, int(read), err
+	}
+
+	rec, err := indexed.readNode(0, 1)
+	return rec, int(read), err
+}
+
+// ReadPath decodes only the sub-recording found at the path provided, along
+// with all of it's children. Paths are made up of recording IDs (or names)
+// separated by slashes, starting at the root recording, like
+// "root/players/p7". The underlying reader must be positioned at the start of
+// a v3 recording and implement both io.ReaderAt and io.Seeker.
+func (r Reader) ReadPath(path string) (format.Recording, error) {
+	rec, _, err := r.withSpillCleanup(func(r Reader) (format.Recording, int, error) {
+		rec, err := r.readPath(path)
+		return rec, 0, err
+	})
+	return rec, err
+}
+
+func (r Reader) readPath(path string) (format.Recording, error) {
+	if r.in == nil {
+		panic("Attempting to load recording from nil reader")
+	}
+
+	readerAt, size, err := indexedSection(r.in, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	indexed, err := r.openIndexed(readerAt, size)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := indexed.resolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return indexed.readNode(entry, 1)
+}
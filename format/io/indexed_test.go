@@ -0,0 +1,261 @@
+package io_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	goio "io"
+	"testing"
+
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/event"
+	"github.com/recolude/rap/format/collection/position"
+	"github.com/recolude/rap/format/encoding"
+	eventEncoding "github.com/recolude/rap/format/encoding/event"
+	positionEncoding "github.com/recolude/rap/format/encoding/position"
+	"github.com/recolude/rap/format/io"
+	"github.com/recolude/rap/format/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildIndexedTestRecording() format.Recording {
+	player := func(id string, offset float64) format.Recording {
+		return format.NewRecording(
+			id,
+			"Player "+id,
+			[]format.CaptureCollection{
+				position.NewCollection("Position", []position.Capture{
+					position.NewCapture(1, offset, 2, 3),
+					position.NewCapture(2, offset+1, 5, 6),
+				}),
+				event.NewCollection("Events", []event.Capture{
+					event.NewCapture(1.5, "Jump", metadata.NewBlock(map[string]metadata.Property{
+						"height": metadata.NewIntProperty(3),
+					})),
+				}),
+			},
+			nil,
+			metadata.NewBlock(map[string]metadata.Property{
+				"team": metadata.NewStringProperty("red"),
+			}),
+			[]format.Binary{
+				io.NewBinary("voice", []byte("hello "+id), metadata.EmptyBlock()),
+			},
+			nil,
+		)
+	}
+
+	return format.NewRecording(
+		"root",
+		"Session",
+		[]format.CaptureCollection{
+			event.NewCollection("Session Events", []event.Capture{
+				event.NewCapture(0, "Start", metadata.EmptyBlock()),
+			}),
+		},
+		[]format.Recording{
+			format.NewRecording(
+				"players",
+				"Players",
+				nil,
+				[]format.Recording{player("p1", 10), player("p7", 70)},
+				metadata.EmptyBlock(),
+				nil,
+				nil,
+			),
+		},
+		metadata.NewBlock(map[string]metadata.Property{
+			"level": metadata.NewStringProperty("lobby"),
+		}),
+		nil,
+		nil,
+	)
+}
+
+func Test_Indexed_RoundTrip(t *testing.T) {
+	for _, compress := range []bool{true, false} {
+		// ARRANGE ============================================================
+		fileData := new(bytes.Buffer)
+		encoders := []encoding.Encoder{
+			eventEncoding.NewEncoder(),
+			positionEncoding.NewEncoder(positionEncoding.Raw64),
+		}
+		recIn := buildIndexedTestRecording()
+
+		// ACT ================================================================
+		n, errWrite := io.NewIndexedWriter(encoders, compress, fileData, io.Raw64).Write(recIn)
+		recOut, nOut, errRead := io.NewReader(encoders, bytes.NewBuffer(fileData.Bytes())).Read()
+
+		// ASSERT =============================================================
+		assert.NoError(t, errWrite)
+		assert.NoError(t, errRead)
+		assert.Equal(t, fileData.Len(), n)
+		assert.Equal(t, n, nOut)
+		assertRecordingsMatch(t, recIn, recOut, 0)
+	}
+}
+
+func Test_Indexed_ReadPath(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	encoders := []encoding.Encoder{
+		eventEncoding.NewEncoder(),
+		positionEncoding.NewEncoder(positionEncoding.Raw64),
+	}
+	recIn := buildIndexedTestRecording()
+	_, errWrite := io.NewIndexedWriter(encoders, true, fileData, io.Raw64).Write(recIn)
+	r := io.NewReader(encoders, bytes.NewReader(fileData.Bytes()))
+
+	// ACT ====================================================================
+	p7, errP7 := r.ReadPath("root/players/p7")
+	p1ByName, errP1 := r.ReadPath("Session/Players/Player p1")
+	root, errRoot := r.ReadPath("root")
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	assert.NoError(t, errP7)
+	assert.NoError(t, errP1)
+	assert.NoError(t, errRoot)
+	assertRecordingsMatch(t, recIn.Recordings()[0].Recordings()[1], p7, 0)
+	assertRecordingsMatch(t, recIn.Recordings()[0].Recordings()[0], p1ByName, 0)
+	assertRecordingsMatch(t, recIn, root, 0)
+}
+
+func Test_Indexed_ReadPath_ErrorsOnUnknownPath(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	encoders := []encoding.Encoder{
+		eventEncoding.NewEncoder(),
+		positionEncoding.NewEncoder(positionEncoding.Raw64),
+	}
+	_, errWrite := io.NewIndexedWriter(encoders, true, fileData, io.Raw64).Write(buildIndexedTestRecording())
+	r := io.NewReader(encoders, bytes.NewReader(fileData.Bytes()))
+
+	// ACT ====================================================================
+	rec, err := r.ReadPath("root/players/p9")
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	assert.Nil(t, rec)
+	assert.EqualError(t, err, "no recording found at path: root/players/p9")
+}
+
+func Test_Indexed_ReadPath_ErrorsOnV2Recording(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	encoders := []encoding.Encoder{
+		eventEncoding.NewEncoder(),
+		positionEncoding.NewEncoder(positionEncoding.Raw64),
+	}
+	_, errWrite := io.NewWriter(encoders, true, fileData, io.Raw64).Write(buildIndexedTestRecording())
+	r := io.NewReader(encoders, bytes.NewReader(fileData.Bytes()))
+
+	// ACT ====================================================================
+	rec, err := r.ReadPath("root")
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	assert.Nil(t, rec)
+	assert.Error(t, err)
+}
+
+func Test_Indexed_ReadPath_RequiresRandomAccess(t *testing.T) {
+	// ARRANGE ================================================================
+	r := io.NewReader(nil, new(bytes.Buffer))
+
+	// ACT ====================================================================
+	rec, err := r.ReadPath("root")
+
+	// ASSERT =================================================================
+	assert.Nil(t, rec)
+	assert.EqualError(t, err, "random access requires the underlying reader to implement io.ReaderAt")
+}
+
+// sequentialReader hides every method but Read, preventing random access.
+type sequentialReader struct {
+	in *bytes.Reader
+}
+
+func (s sequentialReader) Read(p []byte) (int, error) {
+	return s.in.Read(p)
+}
+
+func Test_Indexed_Sequential_EnforcesDecompressedSize(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	encoders := []encoding.Encoder{
+		eventEncoding.NewEncoder(),
+		positionEncoding.NewEncoder(positionEncoding.Raw64),
+	}
+	recIn := buildIndexedTestRecording()
+	_, errWrite := io.NewIndexedWriter(encoders, false, fileData, io.Raw64).Write(recIn)
+
+	// ACT ====================================================================
+	recLimited, _, errLimited := io.NewReader(encoders, sequentialReader{bytes.NewReader(fileData.Bytes())}, io.MaxDecompressedSize(64)).Read()
+	recOut, _, errRead := io.NewReader(encoders, sequentialReader{bytes.NewReader(fileData.Bytes())}, io.MaxDecompressedSize(uint64(fileData.Len()))).Read()
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	assert.Nil(t, recLimited)
+	var limitErr io.LimitError
+	if assert.ErrorAs(t, errLimited, &limitErr) {
+		assert.Equal(t, "buffered input size", limitErr.Limit)
+		assert.Equal(t, uint64(64), limitErr.Max)
+	}
+	assert.NoError(t, errRead)
+	assertRecordingsMatch(t, recIn, recOut, 0)
+}
+
+func Test_Indexed_ReadsFromMidStream(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := bytes.NewBufferString("unrelated leading data")
+	prefix := fileData.Len()
+	encoders := []encoding.Encoder{
+		eventEncoding.NewEncoder(),
+		positionEncoding.NewEncoder(positionEncoding.Raw64),
+	}
+	recIn := buildIndexedTestRecording()
+	n, errWrite := io.NewIndexedWriter(encoders, true, fileData, io.Raw64).Write(recIn)
+
+	in := bytes.NewReader(fileData.Bytes())
+	_, errSeek := in.Seek(int64(prefix), goio.SeekStart)
+
+	pathIn := bytes.NewReader(fileData.Bytes())
+	_, errPathSeek := pathIn.Seek(int64(prefix), goio.SeekStart)
+
+	// ACT ====================================================================
+	recOut, nOut, errRead := io.NewReader(encoders, in).Read()
+	p7, errPath := io.NewReader(encoders, pathIn).ReadPath("root/players/p7")
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	assert.NoError(t, errSeek)
+	assert.NoError(t, errPathSeek)
+	assert.NoError(t, errRead)
+	assert.NoError(t, errPath)
+	assert.Equal(t, n, nOut)
+	assertRecordingsMatch(t, recIn, recOut, 0)
+	assertRecordingsMatch(t, recIn.Recordings()[0].Recordings()[1], p7, 0)
+}
+
+func Test_Indexed_TruncatedIndex(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	encoders := []encoding.Encoder{
+		eventEncoding.NewEncoder(),
+		positionEncoding.NewEncoder(positionEncoding.Raw64),
+	}
+	_, errWrite := io.NewIndexedWriter(encoders, true, fileData, io.Raw64).Write(buildIndexedTestRecording())
+
+	// Point the footer at the final byte of the index, cutting off
+	// everything before it
+	truncated := append([]byte{}, fileData.Bytes()...)
+	binary.LittleEndian.PutUint64(truncated[len(truncated)-8:], uint64(len(truncated)-9))
+
+	// ACT ====================================================================
+	rec, _, err := io.NewReader(encoders, bytes.NewReader(truncated)).Read()
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	assert.Nil(t, rec)
+	assert.ErrorIs(t, err, goio.EOF)
+}
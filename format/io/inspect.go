@@ -0,0 +1,449 @@
+package io
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/recolude/rap/format/encoding"
+	"github.com/recolude/rap/format/metadata"
+	"github.com/recolude/rap/internal/io/binary"
+	"github.com/recolude/rap/internal/io/rapv1"
+)
+
+// EncoderManifest describes an encoder used within a recording.
+type EncoderManifest struct {
+	Signature string
+	Version   uint
+}
+
+// CollectionManifest describes a capture collection without any of it's
+// captures having been decoded.
+type CollectionManifest struct {
+	Name string
+
+	// Signature is the signature of the encoder the collection was written
+	// with
+	Signature string
+
+	Captures int
+	Start    float64
+	End      float64
+}
+
+// RecordingManifest describes a recording and all of it's children.
+type RecordingManifest struct {
+	ID          string
+	Name        string
+	Metadata    metadata.Block
+	Collections []CollectionManifest
+	Recordings  []RecordingManifest
+}
+
+// Manifest describes the contents of a recording file, built without
+// decoding any captures or reading any embedded binaries.
+type Manifest struct {
+	Version      int
+	Encoders     []EncoderManifest
+	Compressed   bool
+	MetadataKeys []string
+
+	// Codec is the compression codec used on the recording's contents. Only
+	// set for v2 and v3 recordings.
+	Codec CodecID
+
+	// Checksummed is whether or not the recording was written with
+	// checksums.
+	Checksummed bool
+
+	// Signed is whether or not the recording ends with a signature block.
+	// Inspecting a recording does not verify it's signature.
+	Signed bool
+
+	// Encrypted is whether or not the recording's contents are encrypted,
+	// with KeyID identifying the key they were encrypted with. Encrypted
+	// recordings inspected without a key provider fail with ErrEncrypted,
+	// with the manifest only describing their header.
+	Encrypted bool
+	KeyID     string
+
+	// Deduplicated is whether or not identical collections and binaries
+	// were stored once.
+	Deduplicated bool
+
+	Recording RecordingManifest
+}
+
+// Inspect builds a manifest of the recording provided without decoding it's
+// captures, making it far cheaper than a full read. Only the times of
+// captures are read, and no encoders are required to be registered.
+func Inspect(in io.Reader, options ...ReaderOption) (Manifest, error) {
+	return NewReader(nil, in, options...).Inspect()
+}
+
+func encoderManifests(encoders []encoding.Encoder) []EncoderManifest {
+	manifests := make([]EncoderManifest, len(encoders))
+	for i, encoder := range encoders {
+		manifests[i] = EncoderManifest{
+			Signature: encoder.Signature(),
+			Version:   encoder.Version(),
+		}
+	}
+	return manifests
+}
+
+// inspectCollection reads the times of a single capture collection, skipping
+// over it's encoded data.
+func inspectCollection(in io.Reader, encoders []encoding.Encoder, opts readerOptions) (CollectionManifest, error) {
+	encoderIndex, _, err := binary.ReadUvarint(in)
+	if err != nil {
+		return CollectionManifest{}, err
+	}
+
+	if encoderIndex >= uint64(len(encoders)) {
+		return CollectionManifest{}, fmt.Errorf("encoder index out of range: %d", encoderIndex)
+	}
+	encoder := encoders[encoderIndex]
+
+	manifest := CollectionManifest{Signature: encoder.Signature()}
+	manifest.Name, _, err = binary.ReadStringLimited(in, opts.maxLength)
+	if err != nil {
+		return manifest, collectionError{encoder: encoder.Signature(), err: err}
+	}
+
+	times, err := decodeTime(in, opts.maxCaptures)
+	if err != nil {
+		return manifest, collectionError{collection: manifest.Name, encoder: encoder.Signature(), err: err}
+	}
+
+	manifest.Captures = len(times)
+	if len(times) > 0 {
+		manifest.Start = times[0]
+		manifest.End = times[len(times)-1]
+	}
+
+	bodyLength, _, err := binary.ReadUvarint(in)
+	if err == nil {
+		err = binary.CheckLimit("byte array length", bodyLength, opts.maxDecompressedSize)
+	}
+	if err == nil {
+		_, err = io.CopyN(io.Discard, in, int64(bodyLength))
+	}
+	if err != nil {
+		return manifest, collectionError{collection: manifest.Name, encoder: encoder.Signature(), err: err}
+	}
+
+	return manifest, nil
+}
+
+// inspectCollection inspects the next collection, expanding it when it refers
+// to one inspected previously.
+func (d *decoder) inspectCollection() (CollectionManifest, error) {
+	inspectFull := func() (CollectionManifest, error) {
+		return inspectCollection(d.in, d.encoders, d.opts)
+	}
+
+	if d.dedup == nil {
+		return inspectFull()
+	}
+	return d.dedup.inspectCollection(d.in, d.opts, inspectFull)
+}
+
+func (d *decoder) inspectRecording() (RecordingManifest, error) {
+	err := binary.CheckLimit("recording nesting depth", uint64(len(d.path)+1), uint64(d.opts.maxDepth))
+	if err != nil {
+		return RecordingManifest{}, d.fail(err)
+	}
+
+	manifest := RecordingManifest{}
+	manifest.ID, manifest.Name, manifest.Metadata, err = readRecordingHeader(d.in, d.metadataKeys, d.opts)
+	if err != nil {
+		return manifest, d.fail(err)
+	}
+
+	d.path = append(d.path, pathSegment(manifest.ID, manifest.Name))
+	defer func() { d.path = d.path[:len(d.path)-1] }()
+
+	numStreams, _, err := binary.ReadUvarint(d.in)
+	if err != nil {
+		return manifest, d.fail(err)
+	}
+
+	err = binary.CheckLimit("array length", numStreams, d.opts.maxLength)
+	if err != nil {
+		return manifest, d.fail(err)
+	}
+
+	err = d.sums.verify("", "")
+	if err != nil {
+		return manifest, d.fail(err)
+	}
+
+	manifest.Collections = make([]CollectionManifest, 0)
+	for i := uint64(0); i < numStreams; i++ {
+		collection, err := d.inspectCollection()
+		if err != nil {
+			return manifest, d.fail(err)
+		}
+
+		err = d.sums.verify(collection.Name, "")
+		if err != nil {
+			return manifest, d.fail(err)
+		}
+		manifest.Collections = append(manifest.Collections, collection)
+	}
+
+	_, _, err = readBinaries(d.in, d.metadataKeys, d.opts, binarySource{}, d.sums, d.dedup)
+	if err != nil {
+		return manifest, d.fail(err)
+	}
+
+	numRecordings, _, err := binary.ReadUvarint(d.in)
+	if err != nil {
+		return manifest, d.fail(err)
+	}
+
+	err = binary.CheckLimit("array length", numRecordings, d.opts.maxLength)
+	if err != nil {
+		return manifest, d.fail(err)
+	}
+
+	err = d.sums.verify("", "")
+	if err != nil {
+		return manifest, d.fail(err)
+	}
+
+	manifest.Recordings = make([]RecordingManifest, 0)
+	for i := uint64(0); i < numRecordings; i++ {
+		child, err := d.inspectRecording()
+		if err != nil {
+			return manifest, err
+		}
+		manifest.Recordings = append(manifest.Recordings, child)
+	}
+
+	return manifest, nil
+}
+
+func (r Reader) inspectV2(manifest Manifest) (Manifest, error) {
+	cleartext := bytes.NewBuffer([]byte{2})
+	header := newHeaderReader(io.TeeReader(r.in, cleartext), 2)
+	headerDecoder := &decoder{in: header, opts: r.options}
+
+	encoders, _, err := r.readEncoders(header)
+	if err != nil {
+		return manifest, headerDecoder.fail(err)
+	}
+	versionAndEncoders := append([]byte{}, cleartext.Bytes()...)
+	manifest.Encoders = encoderManifests(encoders)
+
+	features, err := readCodecAndFeatures(header, r.options.maxLength, headerDecoder.checksumMismatch)
+	if err != nil {
+		return manifest, headerDecoder.fail(err)
+	}
+	manifest.Codec = features.codec.ID()
+	manifest.Compressed = manifest.Codec != CodecNone
+	manifest.Checksummed = features.has(featureChecksums)
+	manifest.Signed = features.has(featureSigned)
+	manifest.Encrypted = features.has(featureEncrypted)
+	manifest.KeyID = features.keyID
+	manifest.Deduplicated = features.has(featureDeduplicated)
+
+	// Signed recordings have their signature block held back, as the body
+	// must end where the block begins
+	var contents io.Reader = r.in
+	if manifest.Signed {
+		contents = &signedReader{in: r.in, digest: sha256.New()}
+	}
+
+	if manifest.Encrypted {
+		contents, err = r.decrypt(contents, versionAndEncoders, features)
+		if err != nil {
+			return manifest, headerDecoder.fail(err)
+		}
+	}
+
+	body, err := features.codec.NewReader(contents)
+	if err != nil {
+		return manifest, headerDecoder.fail(err)
+	}
+
+	decompressed := uint64(0)
+	d := &decoder{
+		in:          &countingReader{Reader: newSizeLimitedReader(body, &decompressed, r.options.maxDecompressedSize)},
+		startOffset: header.n,
+		encoders:    encoders,
+		opts:        r.options,
+	}
+
+	if manifest.Checksummed {
+		d.in.hash = newChecksum()
+		d.sums = &sectionChecksums{in: d.in, mismatch: d.checksumMismatch}
+	}
+
+	if manifest.Deduplicated {
+		d.dedup = &dedupReader{}
+	}
+
+	for i := range encoders {
+		_, _, err = binary.ReadBytesArrayLimited(d.in, r.options.maxDecompressedSize)
+		if err != nil {
+			return manifest, d.fail(collectionError{encoder: encoders[i].Signature(), err: err})
+		}
+	}
+
+	d.metadataKeys, _, err = binary.ReadStringArrayLimited(d.in, r.options.maxLength)
+	if err != nil {
+		return manifest, d.fail(err)
+	}
+	manifest.MetadataKeys = d.metadataKeys
+
+	err = d.sums.verify("", "")
+	if err != nil {
+		return manifest, d.fail(err)
+	}
+
+	manifest.Recording, err = d.inspectRecording()
+	return manifest, err
+}
+
+func (r Reader) inspectIndexed(manifest Manifest) (Manifest, error) {
+	readerAt, size, err := indexedSection(r.in, 1)
+	if err != nil {
+		// Fall back to buffering the recording when random access isn't
+		// available
+		buffer := bytes.Buffer{}
+		buffer.WriteByte(3)
+		_, err = io.Copy(&buffer, r.in)
+		if err != nil {
+			return manifest, err
+		}
+		data := bytes.NewReader(buffer.Bytes())
+		readerAt, size = data, data.Size()
+	}
+
+	ir, err := r.openIndexed(readerAt, size)
+	if err != nil {
+		return manifest, err
+	}
+
+	manifest.Encoders = encoderManifests(ir.index.encoders)
+	manifest.Codec = ir.index.codec.ID()
+	manifest.Compressed = manifest.Codec != CodecNone
+	manifest.MetadataKeys = ir.metadataKeys
+	manifest.Recording, err = ir.inspectNode(0, 1)
+	return manifest, err
+}
+
+func (ir indexedRecordingReader) inspectNode(entryIndex uint64, depth int) (RecordingManifest, error) {
+	entry := ir.index.entries[entryIndex]
+
+	err := binary.CheckLimit("recording nesting depth", uint64(depth), uint64(ir.options.maxDepth))
+	if err != nil {
+		return RecordingManifest{}, err
+	}
+
+	manifest := RecordingManifest{}
+	manifest.ID, manifest.Name, manifest.Metadata, err = readRecordingHeader(ir.openBlock(entry.offset, entry.length), ir.metadataKeys, ir.options)
+	if err != nil {
+		return manifest, err
+	}
+
+	manifest.Collections = make([]CollectionManifest, len(entry.collections))
+	for i, collectionEntry := range entry.collections {
+		manifest.Collections[i], err = inspectCollection(ir.openBlock(collectionEntry.offset, collectionEntry.length), ir.index.encoders, ir.options)
+		if err != nil {
+			return manifest, err
+		}
+	}
+
+	manifest.Recordings = make([]RecordingManifest, len(entry.children))
+	for i, child := range entry.children {
+		manifest.Recordings[i], err = ir.inspectNode(child, depth+1)
+		if err != nil {
+			return manifest, err
+		}
+	}
+
+	return manifest, nil
+}
+
+func fromV1Summary(summary rapv1.RecordingSummary, keys map[string]bool) RecordingManifest {
+	for key := range summary.Metadata.Mapping() {
+		keys[key] = true
+	}
+
+	manifest := RecordingManifest{
+		ID:          summary.ID,
+		Name:        summary.Name,
+		Metadata:    summary.Metadata,
+		Collections: make([]CollectionManifest, len(summary.Collections)),
+		Recordings:  make([]RecordingManifest, len(summary.Recordings)),
+	}
+
+	for i, collection := range summary.Collections {
+		manifest.Collections[i] = CollectionManifest(collection)
+	}
+
+	for i, child := range summary.Recordings {
+		manifest.Recordings[i] = fromV1Summary(child, keys)
+	}
+
+	return manifest
+}
+
+func (r Reader) inspectV1(manifest Manifest) (Manifest, error) {
+	summary, _, err := rapv1.SummarizeRecording(r.in, r.options.convertV1Rotations)
+	if err != nil {
+		return manifest, err
+	}
+
+	keys := make(map[string]bool)
+	manifest.Compressed = true
+	manifest.Encoders = make([]EncoderManifest, 0)
+	manifest.Recording = fromV1Summary(summary, keys)
+	manifest.MetadataKeys = make([]string, 0, len(keys))
+	for key := range keys {
+		manifest.MetadataKeys = append(manifest.MetadataKeys, key)
+	}
+	sort.Strings(manifest.MetadataKeys)
+
+	return manifest, nil
+}
+
+// Inspect builds a manifest of the recording without decoding it's captures
+// or reading embedded binaries. Encoders found within the recording do not
+// need to be registered with the reader. Chunked recordings can not be
+// inspected, and must be read in full.
+func (r Reader) Inspect() (Manifest, error) {
+	if r.in == nil {
+		panic("Attempting to inspect recording from nil reader")
+	}
+
+	version, _, err := GetRecoringVersion(r.in)
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	// Nothing is decoded, so every encoder can be treated as unknown, which
+	// also reports the encoder versions exactly as found in the recording
+	r.encoders = nil
+	r.options.preserveUnknownEncoders = true
+	r.options.skipBinaryData = true
+
+	manifest := Manifest{Version: version}
+	switch version {
+	case 1:
+		return r.inspectV1(manifest)
+
+	case 2:
+		return r.inspectV2(manifest)
+
+	case 3:
+		return r.inspectIndexed(manifest)
+	}
+
+	return manifest, fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+}
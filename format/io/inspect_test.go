@@ -0,0 +1,132 @@
+package io_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/recolude/rap/format/encoding"
+	eventEncoding "github.com/recolude/rap/format/encoding/event"
+	positionEncoding "github.com/recolude/rap/format/encoding/position"
+	"github.com/recolude/rap/format/io"
+	"github.com/recolude/rap/format/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Inspect_V2AndV3(t *testing.T) {
+	encoders := []encoding.Encoder{
+		eventEncoding.NewEncoder(),
+		positionEncoding.NewEncoder(positionEncoding.Raw64),
+	}
+
+	writers := map[string]func(*bytes.Buffer, bool) io.Writer{
+		"v2": func(out *bytes.Buffer, compress bool) io.Writer {
+			return io.NewWriter(encoders, compress, out, io.Raw64)
+		},
+		"v3": func(out *bytes.Buffer, compress bool) io.Writer {
+			return io.NewIndexedWriter(encoders, compress, out, io.Raw64)
+		},
+	}
+
+	for name, buildWriter := range writers {
+		for _, compress := range []bool{true, false} {
+			t.Run(name, func(t *testing.T) {
+				// ARRANGE ====================================================
+				fileData := new(bytes.Buffer)
+				_, errWrite := buildWriter(fileData, compress).Write(buildIndexedTestRecording())
+
+				// ACT ========================================================
+				manifest, err := io.Inspect(bytes.NewReader(fileData.Bytes()))
+
+				// ASSERT =====================================================
+				assert.NoError(t, errWrite)
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				assert.Equal(t, compress, manifest.Compressed)
+				assert.ElementsMatch(t, []io.EncoderManifest{
+					{Signature: "recolude.event", Version: 0},
+					{Signature: "recolude.position", Version: 1},
+				}, manifest.Encoders)
+				assert.ElementsMatch(t, []string{"level", "team"}, manifest.MetadataKeys)
+
+				root := manifest.Recording
+				assert.Equal(t, "root", root.ID)
+				assert.Equal(t, "Session", root.Name)
+				assert.Equal(t, metadata.NewStringProperty("lobby"), root.Metadata.Mapping()["level"])
+				assert.Equal(t, []io.CollectionManifest{
+					{Name: "Session Events", Signature: "recolude.event", Captures: 1, Start: 0, End: 0},
+				}, root.Collections)
+
+				if assert.Len(t, root.Recordings, 1) && assert.Len(t, root.Recordings[0].Recordings, 2) {
+					p7 := root.Recordings[0].Recordings[1]
+					assert.Equal(t, "p7", p7.ID)
+					assert.Equal(t, []io.CollectionManifest{
+						{Name: "Position", Signature: "recolude.position", Captures: 2, Start: 1, End: 2},
+						{Name: "Events", Signature: "recolude.event", Captures: 1, Start: 1.5, End: 1.5},
+					}, p7.Collections)
+				}
+			})
+		}
+	}
+}
+
+func Test_Inspect_V2ReportsVersionFromFile(t *testing.T) {
+	// ARRANGE ================================================================
+	data := writeDecodeErrorTestRecording(t)
+
+	// [version][num encoders][signature length][signature][encoder version]
+	data[3+len("recolude.event")] = 7
+
+	// ACT ====================================================================
+	manifest, err := io.NewReader([]encoding.Encoder{eventEncoding.NewEncoder()}, bytes.NewReader(data)).Inspect()
+
+	// ASSERT =================================================================
+	assert.NoError(t, err)
+	assert.Equal(t, 2, manifest.Version)
+	assert.Equal(t, []io.EncoderManifest{{Signature: "recolude.event", Version: 7}}, manifest.Encoders)
+}
+
+func Test_Inspect_V1(t *testing.T) {
+	// ARRANGE ================================================================
+	f, err := os.Open(filepath.Join(v1DirectoryTestData, "Demo 38subj v1.rap"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+
+	// ACT ====================================================================
+	manifest, err := io.Inspect(f)
+
+	// ASSERT =================================================================
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 1, manifest.Version)
+	assert.Equal(t, "Demo", manifest.Recording.Name)
+	assert.Len(t, manifest.Recording.Collections, 1)
+	assert.Equal(t, "Custom Event", manifest.Recording.Collections[0].Name)
+	if assert.Len(t, manifest.Recording.Recordings, 38) {
+		subject := manifest.Recording.Recordings[0]
+		if assert.Len(t, subject.Collections, 4) {
+			assert.Equal(t, "Position", subject.Collections[0].Name)
+			assert.Equal(t, "recolude.position", subject.Collections[0].Signature)
+		}
+
+		// Everything matches what a full read produces
+		f.Seek(0, 0)
+		rec, _, err := io.Load(f)
+		if assert.NoError(t, err) {
+			for i, collection := range rec.Recordings()[0].CaptureCollections() {
+				assert.Equal(t, collection.Name(), subject.Collections[i].Name)
+				assert.Equal(t, collection.Length(), subject.Collections[i].Captures)
+				if collection.Length() > 0 {
+					assert.Equal(t, collection.Start(), subject.Collections[i].Start)
+					assert.Equal(t, collection.End(), subject.Collections[i].End)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package io
+
+import (
+	"crypto/ed25519"
+	"io"
+	"sync/atomic"
+
+	"github.com/recolude/rap/internal/io/binary"
+)
+
+// LimitError is returned when a recording being read exceeds one of the
+// limits configured on the Reader.
+type LimitError = binary.LimitError
+
+type ReaderOption func(options *readerOptions)
+
+// readerOptions bounds how much memory and recursion a recording can demand
+// while being read, along with how to treat collections that can't be
+// decoded. A limit of 0 means there is no limit.
+type readerOptions struct {
+	maxDecompressedSize uint64
+	maxLength           uint64
+	maxDepth            int
+	maxCaptures         uint64
+	maxBinarySize       uint64
+
+	preserveUnknownEncoders bool
+
+	skipBinaryData bool
+
+	decodeWorkers int
+
+	// verify collects checksum mismatches instead of stopping at them
+	verify *VerifyReport
+
+	// requireSignature fails reading recordings not signed by one of the
+	// trusted keys
+	requireSignature bool
+	trustedKeys      []ed25519.PublicKey
+
+	// keys decrypt encrypted recordings
+	keys KeyProvider
+
+	// convertV1Rotations reads v1 rotations in as quaternions
+	convertV1Rotations bool
+
+	// spills tracks the temp files spilled while reading
+	spills *spillFiles
+
+	// decompressed, when set, is shared between recordings read one after
+	// another so they're held to a single decompressed size limit
+	decompressed *uint64
+}
+
+// MaxDecompressedSize limits the total number of bytes the recording's
+// contents can inflate to, along with the length of any single capture body
+// or encoder header within them. Indexed recordings read without random access are
+// buffered in memory, and are held to the same limit.
+func MaxDecompressedSize(bytes uint64) ReaderOption {
+	return func(options *readerOptions) {
+		options.maxDecompressedSize = bytes
+	}
+}
+
+// MaxLength limits the length of every string and array found within the
+// recording, including those within metadata.
+func MaxLength(length uint64) ReaderOption {
+	return func(options *readerOptions) {
+		options.maxLength = length
+	}
+}
+
+// MaxNestingDepth limits how deeply recordings can be nested within one
+// another, and how deeply metadata blocks can be nested within one another.
+func MaxNestingDepth(depth int) ReaderOption {
+	return func(options *readerOptions) {
+		options.maxDepth = depth
+	}
+}
+
+// MaxCapturesPerCollection limits the number of captures any single capture
+// collection can contain.
+func MaxCapturesPerCollection(captures uint64) ReaderOption {
+	return func(options *readerOptions) {
+		options.maxCaptures = captures
+	}
+}
+
+// MaxBinarySize limits the size of any binary embedded in the recording.
+func MaxBinarySize(bytes uint64) ReaderOption {
+	return func(options *readerOptions) {
+		options.maxBinarySize = bytes
+	}
+}
+
+// SkipBinaryData skips over the data of embedded binaries while reading,
+// keeping only their name, size, and metadata. Reading the data of a skipped
+// binary results in ErrBinaryDataSkipped.
+func SkipBinaryData() ReaderOption {
+	return func(options *readerOptions) {
+		options.skipBinaryData = true
+	}
+}
+
+// sizeLimitedReader errors once more than max bytes have been read through
+// it. The count is shared through a pointer so a limit can span multiple
+// independently read blocks.
+type sizeLimitedReader struct {
+	in   io.Reader
+	read *uint64
+	max  uint64
+}
+
+func newSizeLimitedReader(in io.Reader, read *uint64, max uint64) io.Reader {
+	if max == 0 {
+		return in
+	}
+	return &sizeLimitedReader{in: in, read: read, max: max}
+}
+
+func (s *sizeLimitedReader) Read(p []byte) (int, error) {
+	read := atomic.LoadUint64(s.read)
+	if read >= s.max {
+		// Determine if there's anything left before declaring it too large
+		probe := []byte{0}
+		n, err := s.in.Read(probe)
+		if n > 0 {
+			return 0, LimitError{Limit: "decompressed size", Value: read + uint64(n), Max: s.max}
+		}
+		return 0, err
+	}
+
+	if remaining := s.max - read; uint64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+
+	n, err := s.in.Read(p)
+
+	// Blocks read concurrently may have pushed the count past the limit
+	if total := atomic.AddUint64(s.read, uint64(n)); total > s.max {
+		return 0, LimitError{Limit: "decompressed size", Value: total, Max: s.max}
+	}
+	return n, err
+}
+
+func (s *sizeLimitedReader) skip(n int64) error {
+	read := atomic.LoadUint64(s.read)
+	if read+uint64(n) > s.max {
+		return LimitError{Limit: "decompressed size", Value: read + uint64(n), Max: s.max}
+	}
+
+	err := skip(s.in, n)
+	if err == nil {
+		atomic.AddUint64(s.read, uint64(n))
+	}
+	return err
+}
@@ -0,0 +1,323 @@
+package io_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/enum"
+	"github.com/recolude/rap/format/collection/event"
+	"github.com/recolude/rap/format/encoding"
+	eventEncoding "github.com/recolude/rap/format/encoding/event"
+	"github.com/recolude/rap/format/io"
+	"github.com/recolude/rap/format/metadata"
+	rapbinary "github.com/recolude/rap/internal/io/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildLimitsTestRecording() format.Recording {
+	return format.NewRecording(
+		"root",
+		"Root",
+		[]format.CaptureCollection{
+			event.NewCollection("Events", []event.Capture{
+				event.NewCapture(1, "Jump", metadata.EmptyBlock()),
+				event.NewCapture(2, "Land", metadata.EmptyBlock()),
+			}),
+		},
+		[]format.Recording{
+			format.NewRecording(
+				"child",
+				"Child",
+				nil,
+				nil,
+				metadata.NewBlock(map[string]metadata.Property{
+					"nested": metadata.NewMetadataProperty(metadata.NewBlock(map[string]metadata.Property{
+						"deeper": metadata.NewMetadataProperty(metadata.NewBlock(map[string]metadata.Property{
+							"deepest": metadata.NewMetadataProperty(metadata.EmptyBlock()),
+						})),
+					})),
+				}),
+				nil,
+				nil,
+			),
+		},
+		metadata.NewBlock(map[string]metadata.Property{
+			"nested": metadata.NewMetadataProperty(metadata.EmptyBlock()),
+		}),
+		[]format.Binary{
+			io.NewBinary("voice", []byte("hello world"), metadata.EmptyBlock()),
+		},
+		nil,
+	)
+}
+
+func Test_Reader_EnforcesLimits(t *testing.T) {
+	tests := map[string]struct {
+		option io.ReaderOption
+		limit  string
+	}{
+		"decompressed size": {option: io.MaxDecompressedSize(32), limit: "decompressed size"},
+		"string length":     {option: io.MaxLength(4), limit: "string length"},
+		"recording depth":   {option: io.MaxNestingDepth(1), limit: "recording nesting depth"},
+		"metadata depth":    {option: io.MaxNestingDepth(2), limit: "metadata nesting depth"},
+		"captures":          {option: io.MaxCapturesPerCollection(1), limit: "captures per collection"},
+		"binary size":       {option: io.MaxBinarySize(10), limit: "binary size"},
+	}
+
+	encoders := []encoding.Encoder{eventEncoding.NewEncoder()}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, compress := range []bool{true, false} {
+				// ARRANGE ====================================================
+				fileData := new(bytes.Buffer)
+				_, errWrite := io.NewWriter(encoders, compress, fileData, io.Raw64).Write(buildLimitsTestRecording())
+
+				// ACT ========================================================
+				rec, _, err := io.NewReader(encoders, bytes.NewReader(fileData.Bytes()), tc.option).Read()
+
+				// ASSERT =====================================================
+				assert.NoError(t, errWrite)
+				assert.Nil(t, rec)
+
+				var limitErr io.LimitError
+				if assert.ErrorAs(t, err, &limitErr) {
+					assert.Equal(t, tc.limit, limitErr.Limit)
+				}
+			}
+		})
+	}
+}
+
+func Test_Reader_ReadsWithinLimits(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	encoders := []encoding.Encoder{eventEncoding.NewEncoder()}
+	recIn := buildLimitsTestRecording()
+	_, errWrite := io.NewWriter(encoders, true, fileData, io.Raw64).Write(recIn)
+
+	// ACT ====================================================================
+	recOut, _, errRead := io.NewReader(
+		encoders,
+		bytes.NewReader(fileData.Bytes()),
+		io.MaxDecompressedSize(1024),
+		io.MaxLength(16),
+		io.MaxNestingDepth(3),
+		io.MaxCapturesPerCollection(2),
+		io.MaxBinarySize(11),
+	).Read()
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	assert.NoError(t, errRead)
+	assertRecordingsMatch(t, recIn, recOut, 0)
+}
+
+func Test_Reader_HugeLengthPrefixDoesNotAllocate(t *testing.T) {
+	// ARRANGE ================================================================
+	hugeLength := make([]byte, binary.MaxVarintLen64)
+	read := binary.PutUvarint(hugeLength, 1<<40)
+
+	// [version][no encoders][no versions][uncompressed][metadata keys]
+	fileData := append([]byte{2, 0, 0}, hugeLength[:read]...)
+
+	// ACT ====================================================================
+	rec, _, errUnlimited := io.Load(bytes.NewReader(fileData))
+	_, _, errLimited := io.Load(bytes.NewReader(fileData), io.MaxLength(1024))
+
+	// ASSERT =================================================================
+	assert.Nil(t, rec)
+	assert.ErrorIs(t, errUnlimited, io.ErrTruncated)
+	assert.EqualError(t, errLimited, "decoding recording at byte 9: array length of 1099511627776 exceeds limit of 1024")
+}
+
+// craftedEncoder writes the header and stream data provided for every
+// collection, standing in for a built in encoder to produce hostile files.
+type craftedEncoder struct {
+	signature string
+	header    []byte
+	stream    []byte
+}
+
+func (c craftedEncoder) Accepts(collection format.CaptureCollection) bool {
+	return collection.Signature() == c.signature
+}
+
+func (c craftedEncoder) Decode(string, []byte, []byte, []float64) (format.CaptureCollection, error) {
+	return nil, nil
+}
+
+func (c craftedEncoder) Encode(collections []format.CaptureCollection) ([]byte, [][]byte, error) {
+	streams := make([][]byte, len(collections))
+	for i := range streams {
+		streams[i] = c.stream
+	}
+	return c.header, streams, nil
+}
+
+func (c craftedEncoder) Version() uint {
+	return 0
+}
+
+func (c craftedEncoder) Signature() string {
+	return c.signature
+}
+
+func Test_Load_OutOfRangeIndexes(t *testing.T) {
+	property := metadata.NewStringProperty("x")
+	names := rapbinary.StringArrayToBytes([]string{"Jump"})
+	keys := rapbinary.StringArrayToBytes([]string{"damage"})
+
+	tests := map[string]struct {
+		collection format.CaptureCollection
+		encoder    craftedEncoder
+		err        string
+	}{
+		"event name": {
+			collection: event.NewCollection("Events", []event.Capture{event.NewCapture(1, "Jump", metadata.EmptyBlock())}),
+			encoder: craftedEncoder{
+				signature: "recolude.event",
+				header:    append(append([]byte{}, names...), keys...),
+				stream:    []byte{5, 0},
+			},
+			err: "event name index 5 is out of range of 1 names",
+		},
+		"event metadata key": {
+			collection: event.NewCollection("Events", []event.Capture{event.NewCapture(1, "Jump", metadata.EmptyBlock())}),
+			encoder: craftedEncoder{
+				signature: "recolude.event",
+				header:    append(append([]byte{}, names...), keys...),
+				stream:    append([]byte{0, 1, 3, property.Code()}, property.Data()...),
+			},
+			err: "event metadata key index 3 is out of range of 1 keys",
+		},
+		"enum member": {
+			collection: enum.NewCollection("States", []string{"A"}, []enum.Capture{enum.NewCapture(1, 0)}),
+			encoder: craftedEncoder{
+				signature: "recolude.enum",
+				header:    rapbinary.StringArrayToBytes([]string{"A"}),
+				stream:    []byte{1, 4, 0},
+			},
+			err: "enum member index 4 is out of range of 1 members",
+		},
+		"enum truncated members": {
+			collection: enum.NewCollection("States", []string{"A"}, []enum.Capture{enum.NewCapture(1, 0)}),
+			encoder: craftedEncoder{
+				signature: "recolude.enum",
+				header:    rapbinary.StringArrayToBytes([]string{"A"}),
+				stream:    []byte{3, 0},
+			},
+			err: "EOF",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// ARRANGE ========================================================
+			fileData := new(bytes.Buffer)
+			_, errWrite := io.NewWriter([]encoding.Encoder{tc.encoder}, false, fileData, io.Raw64).Write(
+				format.NewRecording("", "Hostile", []format.CaptureCollection{tc.collection}, nil, metadata.EmptyBlock(), nil, nil),
+			)
+
+			// ACT ============================================================
+			rec, _, err := io.Load(bytes.NewReader(fileData.Bytes()))
+
+			// ASSERT =========================================================
+			assert.NoError(t, errWrite)
+			assert.Nil(t, rec)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.err)
+			}
+		})
+	}
+}
+
+// withHugeLengthPrefix overwrites the single byte length prefix of the value
+// provided with one claiming a length of 1<<40. The prefix spills over into
+// the value so every offset after it stays the same.
+func withHugeLengthPrefix(t *testing.T, data, value []byte) []byte {
+	start := bytes.Index(data, value)
+	if !assert.Greater(t, start, 0) || !assert.Equal(t, byte(len(value)), data[start-1]) {
+		return data
+	}
+
+	hostile := append([]byte{}, data...)
+	binary.PutUvarint(hostile[start-1:], 1<<40)
+	return hostile
+}
+
+func Test_Reader_HostileLengthPrefixes(t *testing.T) {
+	events := event.NewCollection("Events", []event.Capture{event.NewCapture(1, "Jump", metadata.EmptyBlock())})
+	key := make([]byte, 32)
+
+	tests := map[string]struct {
+		encoder craftedEncoder
+		indexed bool
+		options []io.WriterOption
+		value   string
+		limit   io.ReaderOption
+		err     io.LimitError
+	}{
+		"capture body": {
+			encoder: craftedEncoder{signature: "recolude.event", stream: []byte("hostile capture body")},
+			value:   "hostile capture body",
+			limit:   io.MaxDecompressedSize(1024),
+			err:     io.LimitError{Limit: "byte array length", Value: 1 << 40, Max: 1024},
+		},
+		"encoder header": {
+			encoder: craftedEncoder{signature: "recolude.event", header: []byte("hostile encoder header")},
+			value:   "hostile encoder header",
+			limit:   io.MaxDecompressedSize(1024),
+			err:     io.LimitError{Limit: "byte array length", Value: 1 << 40, Max: 1024},
+		},
+		"indexed encoder header": {
+			encoder: craftedEncoder{signature: "recolude.event", header: []byte("hostile encoder header")},
+			indexed: true,
+			value:   "hostile encoder header",
+			limit:   io.MaxDecompressedSize(1024),
+			err:     io.LimitError{Limit: "byte array length", Value: 1 << 40, Max: 1024},
+		},
+		"key id": {
+			encoder: craftedEncoder{signature: "recolude.event"},
+			options: []io.WriterOption{io.Encryption(io.StaticKey(key), "hostile key id")},
+			value:   "hostile key id",
+			limit:   io.MaxLength(1024),
+			err:     io.LimitError{Limit: "string length", Value: 1 << 40, Max: 1024},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// ARRANGE ========================================================
+			fileData := new(bytes.Buffer)
+			recIn := format.NewRecording("", "Hostile", []format.CaptureCollection{events}, nil, metadata.EmptyBlock(), nil, nil)
+			encoders := []encoding.Encoder{tc.encoder}
+			var errWrite error
+			if tc.indexed {
+				_, errWrite = io.NewIndexedWriter(encoders, false, fileData, io.Raw64, tc.options...).Write(recIn)
+			} else {
+				_, errWrite = io.NewWriter(encoders, false, fileData, io.Raw64, tc.options...).Write(recIn)
+			}
+			hostile := withHugeLengthPrefix(t, fileData.Bytes(), []byte(tc.value))
+			decryption := io.Decryption(io.StaticKey(key))
+
+			// ACT ============================================================
+			rec, _, errRead := io.Load(bytes.NewReader(hostile), tc.limit, decryption)
+			_, errInspect := io.Inspect(bytes.NewReader(hostile), tc.limit, decryption)
+
+			// ASSERT =========================================================
+			assert.NoError(t, errWrite)
+			assert.Nil(t, rec)
+
+			var limitErr io.LimitError
+			if assert.ErrorAs(t, errRead, &limitErr) {
+				assert.Equal(t, tc.err, limitErr)
+			}
+			if assert.ErrorAs(t, errInspect, &limitErr) {
+				assert.Equal(t, tc.err, limitErr)
+			}
+		})
+	}
+}
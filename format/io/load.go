@@ -5,10 +5,6 @@ import (
 
 	"github.com/recolude/rap/format"
 	"github.com/recolude/rap/format/encoding"
-	"github.com/recolude/rap/format/encoding/enum"
-	"github.com/recolude/rap/format/encoding/euler"
-	"github.com/recolude/rap/format/encoding/event"
-	"github.com/recolude/rap/format/encoding/position"
 )
 
 func GetRecoringVersion(file io.Reader) (int, int, error) {
@@ -22,11 +18,9 @@ func GetRecoringVersion(file io.Reader) (int, int, error) {
 	return int(version[0]), bytesRead, nil
 }
 
-func Load(in io.Reader) (format.Recording, int, error) {
-	return NewReader([]encoding.Encoder{
-		event.NewEncoder(),
-		position.NewEncoder(position.Oct48),
-		euler.NewEncoder(euler.Raw32),
-		enum.NewEncoder(),
-	}, in).Read()
+// Load reads a recording using every encoder found in the encoding registry,
+// with any options provided for bounding the resources reading can consume.
+// See Reader.Read for how long the binaries of the recording stay readable.
+func Load(in io.Reader, options ...ReaderOption) (format.Recording, int, error) {
+	return NewReader(encoding.RegisteredVersions(), in, options...).Read()
 }
@@ -8,7 +8,15 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/euler"
+	"github.com/recolude/rap/format/collection/event"
+	"github.com/recolude/rap/format/collection/float"
+	"github.com/recolude/rap/format/collection/quaternion"
+	"github.com/recolude/rap/format/encoding"
+	eventEncoding "github.com/recolude/rap/format/encoding/event"
 	rapio "github.com/recolude/rap/format/io"
+	"github.com/recolude/rap/format/metadata"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -36,7 +44,7 @@ func Test_Load_ErrorsOnEmptyBuffer(t *testing.T) {
 func Test_Load_ErrorsOnUnrecognizedFileVersion(t *testing.T) {
 	// ARRANGE ================================================================
 	buf := bytes.Buffer{}
-	buf.Write([]byte{3})
+	buf.Write([]byte{99})
 
 	// ACT ====================================================================
 	rec, bytesRead, err := rapio.Load(&buf)
@@ -44,7 +52,120 @@ func Test_Load_ErrorsOnUnrecognizedFileVersion(t *testing.T) {
 	// ASSERT =================================================================
 	assert.Nil(t, rec)
 	assert.Equal(t, 1, bytesRead)
-	assert.EqualError(t, err, "Unrecognized file version: 3")
+	assert.EqualError(t, err, "Unrecognized file version: 99")
+	assert.ErrorIs(t, err, rapio.ErrUnsupportedVersion)
+}
+
+func writeDecodeErrorTestRecording(t *testing.T) []byte {
+	fileData := new(bytes.Buffer)
+	rec := format.NewRecording(
+		"root",
+		"Root",
+		nil,
+		[]format.Recording{
+			format.NewRecording(
+				"child",
+				"Child",
+				[]format.CaptureCollection{
+					event.NewCollection("Events", []event.Capture{
+						event.NewCapture(1, "Jump", metadata.EmptyBlock()),
+					}),
+				},
+				nil,
+				metadata.EmptyBlock(),
+				nil,
+				nil,
+			),
+		},
+		metadata.EmptyBlock(),
+		nil,
+		nil,
+	)
+	_, err := rapio.NewWriter([]encoding.Encoder{eventEncoding.NewEncoder()}, false, fileData, rapio.Raw64).Write(rec)
+	assert.NoError(t, err)
+	return fileData.Bytes()
+}
+
+func Test_Load_ErrorsOnTruncatedRecording(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := writeDecodeErrorTestRecording(t)
+	truncated := fileData[:len(fileData)-1]
+
+	// ACT ====================================================================
+	rec, bytesRead, err := rapio.NewReader([]encoding.Encoder{eventEncoding.NewEncoder()}, bytes.NewReader(truncated)).Read()
+
+	// ASSERT =================================================================
+	assert.Nil(t, rec)
+	assert.Equal(t, len(truncated), bytesRead)
+	assert.ErrorIs(t, err, rapio.ErrTruncated)
+	assert.ErrorIs(t, err, io.EOF)
+
+	var decodeErr *rapio.DecodeError
+	if assert.ErrorAs(t, err, &decodeErr) {
+		assert.Equal(t, int64(len(truncated)), decodeErr.Offset)
+		assert.Equal(t, []string{"root", "child"}, decodeErr.Path)
+	}
+}
+
+func Test_Load_ErrorsOnTruncatedCollection(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := writeDecodeErrorTestRecording(t)
+
+	// Cut off within the event collection's body
+	truncated := fileData[:len(fileData)-6]
+
+	// ACT ====================================================================
+	rec, _, err := rapio.NewReader([]encoding.Encoder{eventEncoding.NewEncoder()}, bytes.NewReader(truncated)).Read()
+
+	// ASSERT =================================================================
+	assert.Nil(t, rec)
+	assert.ErrorIs(t, err, rapio.ErrTruncated)
+
+	var decodeErr *rapio.DecodeError
+	if assert.ErrorAs(t, err, &decodeErr) {
+		assert.Equal(t, []string{"root", "child"}, decodeErr.Path)
+		assert.Equal(t, "Events", decodeErr.Collection)
+		assert.Equal(t, "recolude.event", decodeErr.Encoder)
+	}
+}
+
+func Test_Load_ErrorsOnUnknownEncoder(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := writeDecodeErrorTestRecording(t)
+
+	// ACT ====================================================================
+	rec, _, err := rapio.NewReader(nil, bytes.NewReader(fileData)).Read()
+
+	// ASSERT =================================================================
+	assert.Nil(t, rec)
+	assert.ErrorIs(t, err, rapio.ErrUnknownEncoder)
+
+	var decodeErr *rapio.DecodeError
+	if assert.ErrorAs(t, err, &decodeErr) {
+		assert.Equal(t, "recolude.event", decodeErr.Encoder)
+	}
+}
+
+func Test_Load_ErrorsOnEncoderTooOld(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := writeDecodeErrorTestRecording(t)
+
+	// [version][num encoders][signature length][signature][encoder version]
+	versionOffset := 3 + len(eventEncoding.NewEncoder().Signature())
+	fileData[versionOffset] = 99
+
+	// ACT ====================================================================
+	rec, _, err := rapio.NewReader([]encoding.Encoder{eventEncoding.NewEncoder()}, bytes.NewReader(fileData)).Read()
+
+	// ASSERT =================================================================
+	assert.Nil(t, rec)
+	assert.ErrorIs(t, err, rapio.ErrEncoderTooOld)
+
+	var decodeErr *rapio.DecodeError
+	if assert.ErrorAs(t, err, &decodeErr) {
+		assert.Equal(t, "recolude.event", decodeErr.Encoder)
+		assert.Equal(t, int64(versionOffset+1), decodeErr.Offset)
+	}
 }
 
 func TestLoad(t *testing.T) {
@@ -84,3 +205,82 @@ func TestLoad(t *testing.T) {
 		assert.Equal(t, "Life Cycle", subj.CaptureCollections()[3].Name())
 	}
 }
+
+func Test_Load_ConvertV1Rotations(t *testing.T) {
+	// ARRANGE ================================================================
+	allBytes, err := os.ReadFile(filepath.Join(v1DirectoryTestData, "Demo 38subj v1.rap"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// ACT ====================================================================
+	eulerRec, _, errEuler := rapio.Load(bytes.NewReader(allBytes))
+	quaternionRec, _, errQuaternion := rapio.Load(bytes.NewReader(allBytes), rapio.ConvertV1Rotations())
+	manifest, errInspect := rapio.Inspect(bytes.NewReader(allBytes), rapio.ConvertV1Rotations())
+
+	// Converted recordings can be written with the registered encoders
+	fileData := new(bytes.Buffer)
+	_, errWrite := rapio.NewRecoludeWriter(fileData).Write(quaternionRec)
+	recOut, _, errRead := rapio.Load(fileData)
+
+	// ASSERT =================================================================
+	assert.NoError(t, errEuler)
+	assert.NoError(t, errQuaternion)
+	assert.NoError(t, errInspect)
+	assert.NoError(t, errWrite)
+	assert.NoError(t, errRead)
+
+	if !assert.Len(t, quaternionRec.Recordings(), 38) {
+		return
+	}
+
+	for subjectIndex, subject := range quaternionRec.Recordings() {
+		rotations := subject.CaptureCollections()[1]
+		original := eulerRec.Recordings()[subjectIndex].CaptureCollections()[1]
+		assert.Equal(t, "Rotation", rotations.Name())
+		assert.Equal(t, "recolude.quaternion", rotations.Signature())
+		assert.Equal(t, "recolude.quaternion", manifest.Recording.Recordings[subjectIndex].Collections[1].Signature)
+		assert.Equal(t, "recolude.quaternion", recOut.Recordings()[subjectIndex].CaptureCollections()[1].Signature())
+
+		if assert.Equal(t, original.Length(), rotations.Length()) {
+			for i, capture := range rotations.Captures() {
+				euler := original.Captures()[i].(euler.Capture).EulerZXY()
+				expected := quaternion.NewEulerZXYCapture(0, euler.X(), euler.Y(), euler.Z()).Rotation()
+				assert.Equal(t, original.Captures()[i].Time(), capture.Time())
+				assert.Equal(t, expected, capture.(quaternion.Capture).Rotation())
+			}
+		}
+	}
+}
+
+func Test_Load_ResolvesRegisteredEncoders(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	recIn := format.NewRecording(
+		"root",
+		"Root",
+		[]format.CaptureCollection{
+			float.NewCollection("Health", []float.Capture{
+				float.NewCapture(1, 100),
+				float.NewCapture(2, 75),
+			}),
+		},
+		nil,
+		metadata.EmptyBlock(),
+		nil,
+		nil,
+	)
+
+	// ACT ====================================================================
+	_, errWrite := rapio.NewRecoludeWriter(fileData).Write(recIn)
+	recOut, _, errRead := rapio.Load(fileData)
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	if assert.NoError(t, errRead) && assert.Len(t, recOut.CaptureCollections(), 1) {
+		health := recOut.CaptureCollections()[0]
+		assert.Equal(t, "Health", health.Name())
+		assert.Equal(t, 2, health.Length())
+		assert.Equal(t, 75.0, health.CaptureAt(1).(float.Capture).Value())
+	}
+}
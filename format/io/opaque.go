@@ -0,0 +1,156 @@
+package io
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/recolude/rap/format"
+)
+
+// PreserveUnknownEncoders allows reading recordings containing collections
+// written with encoders the reader does not have (or only has an older
+// version of). Instead of erroring, those collections are read in as
+// OpaqueCollections, which the Writer re-emits byte for byte.
+func PreserveUnknownEncoders() ReaderOption {
+	return func(options *readerOptions) {
+		options.preserveUnknownEncoders = true
+	}
+}
+
+type opaqueCapture struct {
+	time float64
+}
+
+func (c opaqueCapture) Time() float64 {
+	return c.time
+}
+
+func (c opaqueCapture) String() string {
+	return fmt.Sprintf("[%.2f] Opaque Capture", c.time)
+}
+
+// OpaqueCollection is a capture collection that was encoded with an encoder
+// the reader did not recognize. It keeps everything required to write the
+// collection back out exactly as it was read, but the only thing known about
+// it's captures is their time.
+type OpaqueCollection struct {
+	name      string
+	signature string
+	version   uint
+	header    []byte
+	data      []byte
+	timeBlock []byte
+	times     []float64
+}
+
+func (c OpaqueCollection) Name() string {
+	return c.name
+}
+
+// Signature is the signature of the encoder the collection was written with.
+func (c OpaqueCollection) Signature() string {
+	return c.signature
+}
+
+// EncoderVersion is the version of the encoder the collection was written
+// with.
+func (c OpaqueCollection) EncoderVersion() uint {
+	return c.version
+}
+
+// Header is the header written by the encoder the collection was encoded
+// with, which may be shared with other collections using the same encoder.
+func (c OpaqueCollection) Header() []byte {
+	return c.header
+}
+
+// Data is the raw bytes the encoder wrote for this collection.
+func (c OpaqueCollection) Data() []byte {
+	return c.data
+}
+
+// Times are the decoded times of every capture within the collection.
+func (c OpaqueCollection) Times() []float64 {
+	return c.times
+}
+
+func (c OpaqueCollection) Captures() []format.Capture {
+	captures := make([]format.Capture, len(c.times))
+	for i, time := range c.times {
+		captures[i] = opaqueCapture{time: time}
+	}
+	return captures
+}
+
+// Slice returns the collection unchanged, as it's captures can not be
+// separated from their encoded data.
+func (c OpaqueCollection) Slice(beginning, end float64) format.CaptureCollection {
+	return c
+}
+
+// Start is the time of the first capture, or 0 when there are none.
+func (c OpaqueCollection) Start() float64 {
+	if len(c.times) == 0 {
+		return 0
+	}
+	return c.times[0]
+}
+
+// End is the time of the last capture, or 0 when there are none.
+func (c OpaqueCollection) End() float64 {
+	if len(c.times) == 0 {
+		return 0
+	}
+	return c.times[len(c.times)-1]
+}
+
+func (c OpaqueCollection) Length() int {
+	return len(c.times)
+}
+
+func (c OpaqueCollection) CaptureAt(index int) format.Capture {
+	return opaqueCapture{time: c.times[index]}
+}
+
+// opaqueEncoder stands in for an encoder the reader or writer does not have,
+// passing collections through untouched.
+type opaqueEncoder struct {
+	signature string
+	version   uint
+	header    []byte
+}
+
+func (e opaqueEncoder) Accepts(collection format.CaptureCollection) bool {
+	opaque, ok := collection.(OpaqueCollection)
+	if !ok {
+		return false
+	}
+	return opaque.signature == e.signature && opaque.version == e.version && bytes.Equal(opaque.header, e.header)
+}
+
+func (e opaqueEncoder) Decode(name string, header []byte, streamData []byte, times []float64) (format.CaptureCollection, error) {
+	return OpaqueCollection{
+		name:      name,
+		signature: e.signature,
+		version:   e.version,
+		header:    header,
+		data:      streamData,
+		times:     times,
+	}, nil
+}
+
+func (e opaqueEncoder) Encode(collections []format.CaptureCollection) ([]byte, [][]byte, error) {
+	streams := make([][]byte, len(collections))
+	for i, collection := range collections {
+		streams[i] = collection.(OpaqueCollection).data
+	}
+	return e.header, streams, nil
+}
+
+func (e opaqueEncoder) Version() uint {
+	return e.version
+}
+
+func (e opaqueEncoder) Signature() string {
+	return e.signature
+}
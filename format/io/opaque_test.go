@@ -0,0 +1,177 @@
+package io_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/event"
+	"github.com/recolude/rap/format/collection/float"
+	"github.com/recolude/rap/format/encoding"
+	eventEncoding "github.com/recolude/rap/format/encoding/event"
+	floatEncoding "github.com/recolude/rap/format/encoding/float"
+	"github.com/recolude/rap/format/io"
+	"github.com/recolude/rap/format/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildOpaqueTestRecording() format.Recording {
+	return format.NewRecording(
+		"root",
+		"Root",
+		[]format.CaptureCollection{
+			float.NewCollection("Health", []float.Capture{
+				float.NewCapture(1, 100),
+				float.NewCapture(2.3, 75),
+				float.NewCapture(4.1, 12.5),
+			}),
+			event.NewCollection("Events", []event.Capture{
+				event.NewCapture(1, "Hit", metadata.EmptyBlock()),
+			}),
+		},
+		[]format.Recording{
+			format.NewRecording(
+				"child",
+				"Child",
+				[]format.CaptureCollection{
+					float.NewCollection("Stamina", []float.Capture{
+						float.NewCapture(1, 3),
+					}),
+				},
+				nil,
+				metadata.EmptyBlock(),
+				nil,
+				nil,
+			),
+		},
+		metadata.EmptyBlock(),
+		nil,
+		nil,
+	)
+}
+
+func Test_Opaque_ErrorsWithoutOptingIn(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	_, errWrite := io.NewWriter(
+		[]encoding.Encoder{floatEncoding.NewEncoder(floatEncoding.Raw64), eventEncoding.NewEncoder()},
+		true,
+		fileData,
+		io.BST16,
+	).Write(buildOpaqueTestRecording())
+
+	// ACT ====================================================================
+	rec, _, err := io.NewReader([]encoding.Encoder{eventEncoding.NewEncoder()}, fileData).Read()
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	assert.Nil(t, rec)
+	assert.ErrorIs(t, err, io.ErrUnknownEncoder)
+}
+
+func Test_Opaque_RoundTripsUnknownEncoders(t *testing.T) {
+	// ARRANGE ================================================================
+	original := new(bytes.Buffer)
+	recIn := buildOpaqueTestRecording()
+	_, errWrite := io.NewWriter(
+		[]encoding.Encoder{floatEncoding.NewEncoder(floatEncoding.BST16), eventEncoding.NewEncoder()},
+		true,
+		original,
+		io.BST16,
+	).Write(recIn)
+	expected, _, errExpected := io.NewReader(
+		[]encoding.Encoder{floatEncoding.NewEncoder(floatEncoding.BST16), eventEncoding.NewEncoder()},
+		bytes.NewReader(original.Bytes()),
+	).Read()
+
+	// ACT ====================================================================
+	opaqueRec, _, errOpaque := io.NewReader(
+		[]encoding.Encoder{eventEncoding.NewEncoder()},
+		bytes.NewReader(original.Bytes()),
+		io.PreserveUnknownEncoders(),
+	).Read()
+
+	edited := format.NewRecording(
+		opaqueRec.ID(),
+		"Edited",
+		opaqueRec.CaptureCollections(),
+		opaqueRec.Recordings(),
+		metadata.NewBlock(map[string]metadata.Property{
+			"edited": metadata.NewBoolProperty(true),
+		}),
+		nil,
+		nil,
+	)
+
+	rewritten := new(bytes.Buffer)
+	_, errRewrite := io.NewWriter([]encoding.Encoder{eventEncoding.NewEncoder()}, false, rewritten, io.Raw64).Write(edited)
+
+	recOut, _, errRead := io.NewReader(
+		[]encoding.Encoder{floatEncoding.NewEncoder(floatEncoding.Raw64), eventEncoding.NewEncoder()},
+		rewritten,
+	).Read()
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	assert.NoError(t, errExpected)
+	if !assert.NoError(t, errOpaque) {
+		return
+	}
+
+	health, ok := opaqueRec.CaptureCollections()[0].(io.OpaqueCollection)
+	if assert.True(t, ok) {
+		assert.Equal(t, "Health", health.Name())
+		assert.Equal(t, "recolude.float", health.Signature())
+		assert.Equal(t, uint(0), health.EncoderVersion())
+		assert.Equal(t, 3, health.Length())
+		assert.Len(t, health.Times(), 3)
+		assert.NotEmpty(t, health.Data())
+	}
+	assert.IsType(t, event.Collection{}, opaqueRec.CaptureCollections()[1])
+	assert.IsType(t, io.OpaqueCollection{}, opaqueRec.Recordings()[0].CaptureCollections()[0])
+
+	assert.NoError(t, errRewrite)
+	if !assert.NoError(t, errRead) {
+		return
+	}
+	assert.Equal(t, "Edited", recOut.Name())
+	assert.True(t, recOut.Metadata().Mapping()["edited"].(metadata.BoolProperty).Value())
+
+	// Times and values come back exactly as originally decoded, even though
+	// the rewrite used a different time storage technique
+	assert.Equal(t, expected.CaptureCollections(), recOut.CaptureCollections())
+	assert.Equal(t, expected.Recordings()[0].CaptureCollections(), recOut.Recordings()[0].CaptureCollections())
+}
+
+func Test_Opaque_EmptyCollectionStartAndEnd(t *testing.T) {
+	// ARRANGE ================================================================
+	original := new(bytes.Buffer)
+	_, errWrite := io.NewWriter(
+		[]encoding.Encoder{floatEncoding.NewEncoder(floatEncoding.Raw64)},
+		false,
+		original,
+		io.Raw64,
+	).Write(format.NewRecording(
+		"root",
+		"Root",
+		[]format.CaptureCollection{float.NewCollection("Health", nil)},
+		nil,
+		metadata.EmptyBlock(),
+		nil,
+		nil,
+	))
+
+	// ACT ====================================================================
+	rec, _, errRead := io.NewReader(nil, bytes.NewReader(original.Bytes()), io.PreserveUnknownEncoders()).Read()
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	if !assert.NoError(t, errRead) {
+		return
+	}
+	health := rec.CaptureCollections()[0]
+	assert.IsType(t, io.OpaqueCollection{}, health)
+	assert.Equal(t, 0, health.Length())
+	assert.Equal(t, 0., health.Start())
+	assert.Equal(t, 0., health.End())
+}
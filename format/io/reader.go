@@ -1,7 +1,9 @@
 package io
 
 import (
-	"compress/flate"
+	"bytes"
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
 
@@ -15,19 +17,31 @@ import (
 type Reader struct {
 	encoders []encoding.Encoder
 	in       io.Reader
+	options  readerOptions
 }
 
-func NewReader(encoders []encoding.Encoder, r io.Reader) Reader {
+// NewReader builds a reader for recordings written with the encoders provided.
+// When r is seekable and the recording is uncompressed, embedded binaries are
+// read from r on demand, so r must stay open for as long as they are in use.
+func NewReader(encoders []encoding.Encoder, r io.Reader, options ...ReaderOption) Reader {
+	finalOpts := readerOptions{}
+
+	// Loop through each option
+	for _, opt := range options {
+		opt(&finalOpts)
+	}
+
 	return Reader{
 		encoders: encoders,
 		in:       r,
+		options:  finalOpts,
 	}
 }
 
-func (r Reader) readEncoders() ([]encoding.Encoder, int, error) {
+func (r Reader) readEncoders(in io.Reader) ([]encoding.Encoder, int, error) {
 	totalBytesRead := 0
 
-	encoderSignatures, read, err := binary.ReadStringArray(r.in)
+	encoderSignatures, read, err := binary.ReadStringArrayLimited(in, r.options.maxLength)
 	totalBytesRead += read
 	if err != nil {
 		return nil, totalBytesRead, err
@@ -35,7 +49,7 @@ func (r Reader) readEncoders() ([]encoding.Encoder, int, error) {
 
 	encoderVersions := make([]uint64, len(encoderSignatures))
 	for i := range encoderSignatures {
-		val, read, err := binary.ReadUvarint(r.in)
+		val, read, err := binary.ReadUvarint(in)
 		if err != nil {
 			return nil, totalBytesRead, err
 		}
@@ -45,40 +59,60 @@ func (r Reader) readEncoders() ([]encoding.Encoder, int, error) {
 
 	encoders := make([]encoding.Encoder, len(encoderSignatures))
 	for i, desiredEncoderSignature := range encoderSignatures {
-		found := false
+		var newestFound encoding.Encoder
 		for _, registeredEncoder := range r.encoders {
-			if registeredEncoder.Signature() == desiredEncoderSignature {
-				if registeredEncoder.Version() >= uint(encoderVersions[i]) {
-					encoders[i] = registeredEncoder
-					found = true
-				} else {
-					return nil, totalBytesRead, fmt.Errorf(
-						"registered encoder (%s) version is behind what is found in recording: %d < %d",
-						desiredEncoderSignature,
-						registeredEncoder.Version(),
-						encoderVersions[i],
-					)
-				}
+			if registeredEncoder.Signature() != desiredEncoderSignature {
+				continue
+			}
+			if newestFound == nil || registeredEncoder.Version() > newestFound.Version() {
+				newestFound = registeredEncoder
 			}
 		}
-		if found == false {
-			return nil, totalBytesRead, fmt.Errorf("no registered encoder has signature %s", desiredEncoderSignature)
+
+		if r.options.preserveUnknownEncoders && (newestFound == nil || newestFound.Version() < uint(encoderVersions[i])) {
+			encoders[i] = opaqueEncoder{signature: desiredEncoderSignature, version: uint(encoderVersions[i])}
+			continue
+		}
+
+		if newestFound == nil {
+			return nil, totalBytesRead, collectionError{
+				encoder: desiredEncoderSignature,
+				err:     fmt.Errorf("%w %s", ErrUnknownEncoder, desiredEncoderSignature),
+			}
 		}
+
+		if newestFound.Version() < uint(encoderVersions[i]) {
+			return nil, totalBytesRead, collectionError{
+				encoder: desiredEncoderSignature,
+				err: fmt.Errorf(
+					"%w: %d < %d",
+					ErrEncoderTooOld,
+					newestFound.Version(),
+					encoderVersions[i],
+				),
+			}
+		}
+
+		encoders[i] = newestFound
 	}
 
 	return encoders, totalBytesRead, nil
 }
 
-func readRecordingMetadataBlock(in io.Reader, metadataKeys []string) (metadata.Block, error) {
+func readRecordingMetadataBlock(in io.Reader, metadataKeys []string, opts readerOptions) (metadata.Block, error) {
 	propMapping := make(map[string]metadata.Property)
 
-	keyIndecies, _, err := binary.ReadUvarIntArray(in)
+	keyIndecies, _, err := binary.ReadUvarIntArrayLimited(in, opts.maxLength)
 	if err != nil {
 		return metadata.EmptyBlock(), err
 	}
 
 	for _, key := range keyIndecies {
-		propMapping[metadataKeys[key]], err = metadata.ReadProperty(in)
+		if key >= uint(len(metadataKeys)) {
+			return metadata.EmptyBlock(), fmt.Errorf("metadata key index out of range: %d", key)
+		}
+
+		propMapping[metadataKeys[key]], err = metadata.ReadPropertyWithLimits(in, opts.maxLength, opts.maxDepth)
 		if err != nil {
 			return metadata.EmptyBlock(), err
 		}
@@ -87,85 +121,467 @@ func readRecordingMetadataBlock(in io.Reader, metadataKeys []string) (metadata.B
 	return metadata.NewBlock(propMapping), nil
 }
 
-func recursiveBuidRecordings(inStream io.Reader, metadataKeys []string, encoders []encoding.Encoder, headers [][]byte) (format.Recording, int, error) {
-	// in := bytes.NewReader(recordingData)
-	er := binary.NewErrReader(inStream)
-
+// readRecordingHeader reads the id, name, and metadata of a recording.
+func readRecordingHeader(in io.Reader, metadataKeys []string, opts readerOptions) (string, string, metadata.Block, error) {
 	// Read Recording id
-	recordingID, _, err := binary.ReadString(er)
+	recordingID, _, err := binary.ReadStringLimited(in, opts.maxLength)
+	if err != nil {
+		return "", "", metadata.EmptyBlock(), err
+	}
 
 	// Read Recording name
-	recordingName, _, err := binary.ReadString(er)
+	recordingName, _, err := binary.ReadStringLimited(in, opts.maxLength)
+	if err != nil {
+		return "", "", metadata.EmptyBlock(), err
+	}
 
 	// Read Recording metadata
-	recordingMetadataBlock, err := readRecordingMetadataBlock(er, metadataKeys)
+	recordingMetadataBlock, err := readRecordingMetadataBlock(in, metadataKeys, opts)
+	return recordingID, recordingName, recordingMetadataBlock, err
+}
+
+// collectionError records which collection and encoder were involved in a
+// failure so it can be surfaced through a DecodeError.
+type collectionError struct {
+	collection string
+	encoder    string
+	err        error
+}
+
+func (e collectionError) Error() string {
+	return e.err.Error()
+}
+
+func (e collectionError) Unwrap() error {
+	return e.err
+}
+
+// encodedCollection is a capture collection that has been read but not yet
+// decoded.
+type encodedCollection struct {
+	encoder   encoding.Encoder
+	name      string
+	header    []byte
+	body      []byte
+	times     []float64
+	timeBlock []byte
+}
+
+// decode builds the capture collection using the encoder it was written with.
+func (c encodedCollection) decode() (format.CaptureCollection, error) {
+	if _, opaque := c.encoder.(opaqueEncoder); opaque {
+		return OpaqueCollection{
+			name:      c.name,
+			signature: c.encoder.Signature(),
+			version:   c.encoder.Version(),
+			header:    c.header,
+			data:      c.body,
+			timeBlock: c.timeBlock,
+			times:     c.times,
+		}, nil
+	}
+
+	stream, err := c.encoder.Decode(c.name, c.header, c.body, c.times)
 	if err != nil {
-		return nil, er.TotalRead(), err
+		return nil, collectionError{collection: c.name, encoder: c.encoder.Signature(), err: err}
 	}
+	return stream, nil
+}
 
-	// read num streams
-	numStreams, _, err := binary.ReadUvarint(er)
+// readCollection reads a single capture collection and decodes it with the
+// encoder it was written with.
+func readCollection(in io.Reader, encoders []encoding.Encoder, headers [][]byte, opts readerOptions) (format.CaptureCollection, error) {
+	collection, err := readEncodedCollection(in, encoders, headers, opts)
+	if err != nil {
+		return nil, err
+	}
+	return collection.decode()
+}
 
-	// read streams
-	allStreams := make([]format.CaptureCollection, numStreams)
-	for i := 0; i < int(numStreams); i++ {
-		encoderIndex, _, _ := binary.ReadUvarint(er)
+// readEncodedCollection reads a single capture collection without decoding
+// it.
+func readEncodedCollection(in io.Reader, encoders []encoding.Encoder, headers [][]byte, opts readerOptions) (encodedCollection, error) {
+	encoderIndex, _, err := binary.ReadUvarint(in)
+	if err != nil {
+		return encodedCollection{}, err
+	}
 
-		streamName, _, _ := binary.ReadString(er)
+	if encoderIndex >= uint64(len(encoders)) {
+		return encodedCollection{}, fmt.Errorf("encoder index out of range: %d", encoderIndex)
+	}
+	encoder := encoders[encoderIndex]
 
-		times, _ := decodeTime(er)
-		captureBody, _, _ := binary.ReadBytesArray(er)
-		stream, _ := encoders[encoderIndex].Decode(streamName, headers[encoderIndex], captureBody, times)
-		allStreams[i] = stream
+	streamName, _, err := binary.ReadStringLimited(in, opts.maxLength)
+	if err != nil {
+		return encodedCollection{}, collectionError{encoder: encoder.Signature(), err: err}
 	}
 
+	// Opaque collections keep the time block exactly as it was written
+	timeBlock := bytes.Buffer{}
+	timeIn := in
+	_, opaque := encoder.(opaqueEncoder)
+	if opaque {
+		timeIn = io.TeeReader(in, &timeBlock)
+	}
+
+	times, err := decodeTime(timeIn, opts.maxCaptures)
+	if err != nil {
+		return encodedCollection{}, collectionError{collection: streamName, encoder: encoder.Signature(), err: err}
+	}
+
+	captureBody, _, err := binary.ReadBytesArrayLimited(in, opts.maxDecompressedSize)
+	if err != nil {
+		return encodedCollection{}, collectionError{collection: streamName, encoder: encoder.Signature(), err: err}
+	}
+
+	collection := encodedCollection{
+		encoder: encoder,
+		name:    streamName,
+		header:  headers[encoderIndex],
+		body:    captureBody,
+		times:   times,
+	}
+	if opaque {
+		collection.timeBlock = timeBlock.Bytes()
+	}
+	return collection, nil
+}
+
+// readBinaries reads both the binary references and the binaries embedded
+// within a recording, with the source determining whether or not binary data
+// can be left where it is and read later.
+func readBinaries(in io.Reader, metadataKeys []string, opts readerOptions, source binarySource, sums *sectionChecksums, dedup *dedupReader) ([]format.BinaryReference, []format.Binary, error) {
 	// read binary references
-	numBinaryReferences, _, err := binary.ReadUvarint(er)
-	binReferences := make([]format.BinaryReference, numBinaryReferences)
+	numBinaryReferences, _, err := binary.ReadUvarint(in)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = binary.CheckLimit("array length", numBinaryReferences, opts.maxLength)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	for i := 0; i < int(numBinaryReferences); i++ {
-		name, _, _ := binary.ReadString(er)
-		uri, _, _ := binary.ReadString(er)
-		refSize, _, _ := binary.ReadUvarint(er)
-		// Read Recording metadata
-		block, _ := readRecordingMetadataBlock(er, metadataKeys)
+	binReferences := make([]format.BinaryReference, 0)
+	for i := uint64(0); i < numBinaryReferences; i++ {
+		name, _, err := binary.ReadStringLimited(in, opts.maxLength)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		uri, _, err := binary.ReadStringLimited(in, opts.maxLength)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		refSize, _, err := binary.ReadUvarint(in)
+		if err != nil {
+			return nil, nil, err
+		}
 
-		binReferences[i] = NewBinaryReference(name, uri, refSize, block)
+		block, err := readRecordingMetadataBlock(in, metadataKeys, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		binReferences = append(binReferences, NewBinaryReference(name, uri, refSize, block))
 	}
 
 	// read binaries
-	numBinaries, _, err := binary.ReadUvarint(er)
-	binaries := make([]format.Binary, numBinaries)
+	numBinaries, _, err := binary.ReadUvarint(in)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = binary.CheckLimit("array length", numBinaries, opts.maxLength)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = sums.verify("", "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	binaries := make([]format.Binary, 0)
+	for i := uint64(0); i < numBinaries; i++ {
+		name, _, err := binary.ReadStringLimited(in, opts.maxLength)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		refSize, _, err := binary.ReadUvarint(in)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		err = binary.CheckLimit("binary size", refSize, opts.maxBinarySize)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		block, err := readRecordingMetadataBlock(in, metadataKeys, opts)
+		if err != nil {
+			return nil, nil, err
+		}
 
-	for i := 0; i < int(numBinaries); i++ {
-		name, _, _ := binary.ReadString(er)
-		refSize, _, _ := binary.ReadUvarint(er)
+		ref := uint64(0)
+		if dedup != nil {
+			ref, err = readReference(in, "binary", len(dedup.binaries))
+			if err != nil {
+				return nil, nil, err
+			}
+		}
 
-		// Read Recording metadata
-		block, _ := readRecordingMetadataBlock(er, metadataKeys)
+		err = sums.verify("", name)
+		if err != nil {
+			return nil, nil, err
+		}
 
-		allData := make([]byte, refSize)
-		io.ReadFull(er, allData)
+		if ref != 0 {
+			bin, err := dedup.binary(ref, name, refSize, block)
+			if err != nil {
+				return nil, nil, err
+			}
+			binaries = append(binaries, bin)
+			continue
+		}
 
-		binaries[i] = NewBinary(name, allData, block)
+		bin, err := readBinaryData(in, name, refSize, block, source, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		bin, err = sums.verifyBinaryData(bin)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if dedup != nil {
+			dedup.binaries = append(dedup.binaries, bin)
+		}
+		binaries = append(binaries, bin)
+	}
+
+	return binReferences, binaries, nil
+}
+
+// decoder walks the recording tree of a v2 recording, keeping track of where
+// it is so failures can be reported with context.
+type decoder struct {
+	in           *countingReader
+	startOffset  int64
+	metadataKeys []string
+	encoders     []encoding.Encoder
+	headers      [][]byte
+	path         []string
+	opts         readerOptions
+	binaries     binarySource
+
+	// sums verifies the checksums between sections, nil when the recording
+	// has none
+	sums *sectionChecksums
+
+	// dedup expands references to collections and binaries read previously,
+	// nil when the recording was not deduplicated
+	dedup *dedupReader
+
+	// deferred are the collections left to be decoded once the structure of
+	// the entire recording has been read
+	deferred []deferredCollection
+}
+
+// deferredCollection is a collection whose decoding has been put off, along
+// with where it was found for reporting errors.
+type deferredCollection struct {
+	collection encodedCollection
+	into       *format.CaptureCollection
+	offset     int64
+	path       []string
+}
+
+func (d decoder) offset() int64 {
+	return d.startOffset + d.in.n
+}
+
+func (d decoder) fail(err error) error {
+	return failAt(d.offset(), d.path, err)
+}
+
+// failAt builds the error for a failure found at the offset and recording
+// path provided.
+func failAt(offset int64, path []string, err error) error {
+	decodeErr := &DecodeError{
+		Offset: offset,
+		Path:   append([]string{}, path...),
+	}
+
+	// Failing to decrypt is down to the key or the recording having been
+	// altered, never the collection being read at the time
+	var colErr collectionError
+	if errors.As(err, &colErr) {
+		if !errors.Is(colErr.err, ErrDecryption) {
+			decodeErr.Collection = colErr.collection
+			decodeErr.Encoder = colErr.encoder
+		}
+		err = colErr.err
+	}
+
+	var sumErr checksumError
+	if errors.As(err, &sumErr) {
+		decodeErr.Collection = sumErr.collection
+		decodeErr.Binary = sumErr.binary
+	}
+
+	decodeErr.Err = classifyDecodeError(err)
+	return decodeErr
+}
+
+// readCollection reads the next collection, expanding it when it refers to
+// one read previously.
+func (d *decoder) readCollection() (encodedCollection, error) {
+	readFull := func() (encodedCollection, error) {
+		return readEncodedCollection(d.in, d.encoders, d.headers, d.opts)
+	}
+
+	if d.dedup == nil {
+		return readFull()
+	}
+	return d.dedup.readCollection(d.in, d.opts, readFull)
+}
+
+// decodeDeferred decodes every collection whose decoding was put off while
+// reading the structure of the recording.
+func (d *decoder) decodeDeferred() error {
+	return runWorkers(d.opts.decodeWorkers, len(d.deferred), func(job int) error {
+		deferred := d.deferred[job]
+		collection, err := deferred.collection.decode()
+		if err != nil {
+			return failAt(deferred.offset, deferred.path, err)
+		}
+		*deferred.into = collection
+		return nil
+	})
+}
+
+func (d *decoder) readRecording() (format.Recording, error) {
+	err := binary.CheckLimit("recording nesting depth", uint64(len(d.path)+1), uint64(d.opts.maxDepth))
+	if err != nil {
+		return nil, d.fail(err)
+	}
+
+	recordingID, recordingName, recordingMetadataBlock, err := readRecordingHeader(d.in, d.metadataKeys, d.opts)
+	if err != nil {
+		return nil, d.fail(err)
+	}
+
+	d.path = append(d.path, pathSegment(recordingID, recordingName))
+	defer func() { d.path = d.path[:len(d.path)-1] }()
+
+	// read num streams
+	numStreams, _, err := binary.ReadUvarint(d.in)
+	if err != nil {
+		return nil, d.fail(err)
+	}
+
+	err = binary.CheckLimit("array length", numStreams, d.opts.maxLength)
+	if err != nil {
+		return nil, d.fail(err)
+	}
+
+	err = d.sums.verify("", "")
+	if err != nil {
+		return nil, d.fail(err)
+	}
+
+	// read streams
+	allStreams := make([]format.CaptureCollection, 0)
+	deferred := make([]deferredCollection, 0)
+	for i := uint64(0); i < numStreams; i++ {
+		collection, err := d.readCollection()
+		if err != nil {
+			return nil, d.fail(err)
+		}
+
+		err = d.sums.verify(collection.name, "")
+		if err != nil {
+			return nil, d.fail(err)
+		}
+
+		// Spreading decoding across workers requires reading everything first
+		if d.opts.decodeWorkers > 1 {
+			deferred = append(deferred, deferredCollection{
+				collection: collection,
+				offset:     d.offset(),
+				path:       append([]string{}, d.path...),
+			})
+			allStreams = append(allStreams, nil)
+			continue
+		}
+
+		stream, err := collection.decode()
+		if err != nil {
+			return nil, d.fail(err)
+		}
+		allStreams = append(allStreams, stream)
+	}
+
+	for i := range deferred {
+		deferred[i].into = &allStreams[i]
+	}
+	d.deferred = append(d.deferred, deferred...)
+
+	binReferences, binaries, err := readBinaries(d.in, d.metadataKeys, d.opts, d.binaries, d.sums, d.dedup)
+	if err != nil {
+		return nil, d.fail(err)
 	}
 
 	// read num recordings
-	numRecordings, _, err := binary.ReadUvarint(er)
+	numRecordings, _, err := binary.ReadUvarint(d.in)
+	if err != nil {
+		return nil, d.fail(err)
+	}
 
-	allChildRecordings := make([]format.Recording, numRecordings)
-	for i := 0; i < int(numRecordings); i++ {
-		childRec, _, err := recursiveBuidRecordings(er, metadataKeys, encoders, headers)
+	err = binary.CheckLimit("array length", numRecordings, d.opts.maxLength)
+	if err != nil {
+		return nil, d.fail(err)
+	}
+
+	err = d.sums.verify("", "")
+	if err != nil {
+		return nil, d.fail(err)
+	}
+
+	allChildRecordings := make([]format.Recording, 0)
+	for i := uint64(0); i < numRecordings; i++ {
+		childRec, err := d.readRecording()
 		if err != nil {
-			return nil, er.TotalRead(), err
+			return nil, err
 		}
-		allChildRecordings[i] = childRec
+		allChildRecordings = append(allChildRecordings, childRec)
 	}
 
-	return format.NewRecording(recordingID, recordingName, allStreams, allChildRecordings, recordingMetadataBlock, binaries, binReferences), er.TotalRead(), er.Error()
+	return format.NewRecording(recordingID, recordingName, allStreams, allChildRecordings, recordingMetadataBlock, binaries, binReferences), nil
 }
 
+// ConvertV1Rotations reads the rotations of v1 recordings in as
+// recolude.quaternion collections instead of recolude.euler collections.
+func ConvertV1Rotations() ReaderOption {
+	return func(options *readerOptions) {
+		options.convertV1Rotations = true
+	}
+}
+
+// Read decodes the entire recording. Binaries from seekable, uncompressed
+// input are read in place, so the source reader must stay open for as long
+// as their data is needed. Large binaries that can't be read in place are
+// spilled to temp files, which CloseBinaries removes once the recording is
+// no longer needed.
 func (r Reader) Read() (format.Recording, int, error) {
+	return r.withSpillCleanup(Reader.read)
+}
+
+func (r Reader) read() (format.Recording, int, error) {
 	if r.in == nil {
 		panic("Attempting to load recording from nil reader")
 	}
@@ -179,57 +595,158 @@ func (r Reader) Read() (format.Recording, int, error) {
 		return nil, bytesRead, err
 	}
 
+	if (version == 1 || version == 3) && r.options.requireSignature {
+		return nil, totalBytesRead, ErrUnsigned
+	}
+
 	if version == 1 {
-		rec, read, err := rapv1.ReadRecording(r.in)
+		rec, read, err := rapv1.ReadRecording(r.in, r.options.convertV1Rotations)
+		return rec, read + totalBytesRead, err
+	}
+
+	if version == 3 {
+		rec, read, err := r.readIndexedSequentially()
+		return rec, read + totalBytesRead, err
+	}
+
+	if version == 4 {
+		rec, read, err := r.readChunks(false)
 		return rec, read + totalBytesRead, err
 	}
 
 	if version != 2 {
-		return nil, totalBytesRead, fmt.Errorf("Unrecognized file version: %d", version)
+		return nil, totalBytesRead, fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
 	}
 
+	// The header is hashed in case the recording turns out to be signed, and
+	// the encoders kept in case it's encrypted
+	digest := sha256.New()
+	digest.Write([]byte{2})
+	cleartext := bytes.NewBuffer([]byte{2})
+	header := newHeaderReader(io.TeeReader(r.in, io.MultiWriter(digest, cleartext)), 2)
+	headerDecoder := &decoder{in: header, opts: r.options}
+
 	// Read encoders
-	encodersToUse, bytesRead, err := r.readEncoders()
-	totalBytesRead += bytesRead
+	encodersToUse, _, err := r.readEncoders(header)
 	if err != nil {
-		return nil, totalBytesRead, err
+		return nil, int(header.n), headerDecoder.fail(err)
 	}
+	versionAndEncoders := append([]byte{}, cleartext.Bytes()...)
 
-	compressedFlag := []byte{0}
-	bytesRead, err = r.in.Read(compressedFlag)
-	totalBytesRead += bytesRead
+	features, err := readCodecAndFeatures(header, r.options.maxLength, headerDecoder.checksumMismatch)
 	if err != nil {
-		return nil, totalBytesRead, err
+		return nil, int(header.n), headerDecoder.fail(err)
+	}
+
+	var signed *signedReader
+	if features.has(featureSigned) {
+		signed = &signedReader{in: r.in, digest: digest}
+	} else if r.options.requireSignature {
+		return nil, int(header.n), ErrUnsigned
 	}
 
 	var readcloser io.Reader = r.in
-	if compressedFlag[0] == 1 {
-		readcloser = flate.NewReader(r.in)
+	if signed != nil {
+		readcloser = signed
 	}
 
-	encoderHeaders := make([][]byte, len(encodersToUse))
-	for i := range encoderHeaders {
-		header, read, err := binary.ReadBytesArray(readcloser)
-		totalBytesRead += read
+	if features.has(featureEncrypted) {
+		readcloser, err = r.decrypt(readcloser, versionAndEncoders, features)
 		if err != nil {
-			return nil, totalBytesRead, err
+			return nil, int(header.n), headerDecoder.fail(err)
+		}
+	}
+
+	var binaryData io.ReaderAt
+	var binaryDataStart int64
+	if features.codec.ID() != CodecNone {
+		readcloser, err = features.codec.NewReader(readcloser)
+		if err != nil {
+			return nil, int(header.n), headerDecoder.fail(err)
+		}
+	} else if readerAt, size, err := readerAtAndSize(r.in); err == nil && signed == nil && !features.has(featureEncrypted) {
+		// Uncompressed binaries can be read straight from the source later
+		// instead of being held in memory
+		position, err := r.in.(io.Seeker).Seek(0, io.SeekCurrent)
+		if err == nil {
+			binaryData = readerAt
+			binaryDataStart = position - header.n
+			readcloser = seekSkipper{ReadSeeker: r.in.(io.ReadSeeker), size: size}
+		}
+	}
+
+	decompressed := r.options.decompressed
+	if decompressed == nil {
+		decompressed = new(uint64)
+	}
+	d := &decoder{
+		in:          &countingReader{Reader: newSizeLimitedReader(readcloser, decompressed, r.options.maxDecompressedSize)},
+		startOffset: header.n,
+		encoders:    encodersToUse,
+		opts:        r.options,
+	}
+
+	if features.has(featureChecksums) {
+		d.in.hash = newChecksum()
+		d.sums = &sectionChecksums{in: d.in, mismatch: d.checksumMismatch, eager: r.options.verify != nil}
+		if r.options.verify != nil {
+			r.options.verify.Checksummed = true
+		}
+	}
+
+	if features.has(featureDeduplicated) {
+		d.dedup = &dedupReader{}
+	}
+
+	if binaryData != nil {
+		d.binaries = binarySource{
+			at: binaryData,
+			offset: func() int64 {
+				return binaryDataStart + d.offset()
+			},
+		}
+	}
+
+	d.headers = make([][]byte, len(encodersToUse))
+	for i := range d.headers {
+		d.headers[i], _, err = binary.ReadBytesArrayLimited(d.in, r.options.maxDecompressedSize)
+		if err != nil {
+			return nil, int(d.offset()), d.fail(collectionError{encoder: encodersToUse[i].Signature(), err: err})
 		}
-		encoderHeaders[i] = header
 	}
 
 	// Read off metadata keys
-	metdataKeys, bytesRead, err := binary.ReadStringArray(readcloser)
-	totalBytesRead += bytesRead
+	d.metadataKeys, _, err = binary.ReadStringArrayLimited(d.in, r.options.maxLength)
 	if err != nil {
-		return nil, totalBytesRead, err
+		return nil, int(d.offset()), d.fail(err)
+	}
+
+	err = d.sums.verify("", "")
+	if err != nil {
+		return nil, int(d.offset()), d.fail(err)
 	}
 
 	// Read off recordings
-	rec, bytesRead, err := recursiveBuidRecordings(readcloser, metdataKeys, encodersToUse, encoderHeaders)
-	totalBytesRead += bytesRead
+	rec, err := d.readRecording()
 	if err != nil {
-		return nil, totalBytesRead, err
+		return nil, int(d.offset()), err
+	}
+
+	err = d.decodeDeferred()
+	if err != nil {
+		return nil, int(d.offset()), err
+	}
+
+	if signed == nil {
+		return rec, int(d.offset()), nil
+	}
+
+	if r.options.requireSignature {
+		err = signed.verify(r.options.trustedKeys)
+		if err != nil {
+			return nil, int(d.offset()), err
+		}
 	}
 
-	return rec, totalBytesRead, err
+	return rec, int(d.offset()) + signatureSize, nil
 }
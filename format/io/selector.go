@@ -0,0 +1,56 @@
+package io
+
+import (
+	"reflect"
+
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/encoding"
+)
+
+// CollectionContext describes a capture collection about to be written, so
+// an EncoderSelector can decide what it should be written with.
+type CollectionContext struct {
+	// Path is the ID of every recording from the root down to the one
+	// containing the collection, using a recording's name when it has no ID
+	Path []string
+
+	// Recording is the recording containing the collection, whose metadata
+	// may be used to tell collections apart
+	Recording format.Recording
+
+	Collection format.CaptureCollection
+}
+
+// EncoderSelector picks the encoder a capture collection is written with,
+// returning nil to leave the decision to the writer's encoders.
+type EncoderSelector func(CollectionContext) encoding.Encoder
+
+// SelectEncoders has the writer ask the selector for the encoder of every
+// capture collection before falling back to the encoders it was built with,
+// allowing collections of the same type to be written with differently
+// configured encoders, such as a player's head and hands with Oct48 and
+// background characters with Oct24. The encoder selected must accept the
+// collection.
+func SelectEncoders(selector EncoderSelector) WriterOption {
+	return func(options *writerOptions) {
+		options.encoderSelector = selector
+	}
+}
+
+// sameInstance determines whether or not two encoders are the same instance,
+// meaning they're equal values of the same type. Encoders that can't be
+// compared, like those holding a slice or map, are never considered the same.
+func sameInstance(a, b encoding.Encoder) (same bool) {
+	if a == nil || b == nil || reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+
+	// A comparable type can still hold an interface whose value isn't, which
+	// only panics once compared
+	defer func() {
+		if recover() != nil {
+			same = false
+		}
+	}()
+	return a == b
+}
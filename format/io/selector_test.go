@@ -0,0 +1,203 @@
+package io_test
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/event"
+	"github.com/recolude/rap/format/collection/position"
+	"github.com/recolude/rap/format/encoding"
+	eventEncoding "github.com/recolude/rap/format/encoding/event"
+	positionEncoding "github.com/recolude/rap/format/encoding/position"
+	"github.com/recolude/rap/format/io"
+	"github.com/recolude/rap/format/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildSelectorTestRecording() format.Recording {
+	character := func(id string, npc bool) format.Recording {
+		positions := func(name string) format.CaptureCollection {
+			captures := make([]position.Capture, 0)
+			for i := 0; i < 50; i++ {
+				captures = append(captures, position.NewCapture(float64(i), math.Sin(float64(i)*1.3)*50, math.Cos(float64(i)*0.7)*20, math.Sin(float64(i)*2.1)*30))
+			}
+			return position.NewCollection(name, captures)
+		}
+
+		return format.NewRecording(
+			id,
+			"Character "+id,
+			[]format.CaptureCollection{positions("Head"), positions("Body")},
+			nil,
+			metadata.NewBlock(map[string]metadata.Property{
+				"npc": metadata.NewBoolProperty(npc),
+			}),
+			nil,
+			nil,
+		)
+	}
+
+	return format.NewRecording(
+		"root",
+		"Session",
+		[]format.CaptureCollection{
+			event.NewCollection("Events", []event.Capture{event.NewCapture(0, "Start", metadata.EmptyBlock())}),
+		},
+		[]format.Recording{character("player", false), character("npc-1", true), character("npc-2", true)},
+		metadata.EmptyBlock(),
+		nil,
+		nil,
+	)
+}
+
+func Test_SelectEncoders(t *testing.T) {
+	// ARRANGE ================================================================
+	paths := make([]string, 0)
+	selector := func(ctx io.CollectionContext) encoding.Encoder {
+		paths = append(paths, strings.Join(ctx.Path, "/")+"/"+ctx.Collection.Name())
+
+		if npc, ok := ctx.Recording.Metadata().Mapping()["npc"]; ok && npc.(metadata.BoolProperty).Value() {
+			return positionEncoding.NewEncoder(positionEncoding.Oct24)
+		}
+
+		if ctx.Collection.Name() == "Head" {
+			return positionEncoding.NewEncoder(positionEncoding.Oct48)
+		}
+		return nil
+	}
+
+	recIn := buildSelectorTestRecording()
+	fileData := new(bytes.Buffer)
+	encoders := []encoding.Encoder{eventEncoding.NewEncoder(), positionEncoding.NewEncoder(positionEncoding.Raw64)}
+
+	// ACT ====================================================================
+	_, errWrite := io.NewWriter(encoders, true, fileData, io.Raw64, io.SelectEncoders(selector)).Write(recIn)
+	recOut, _, errRead := io.NewReader(encoders, bytes.NewReader(fileData.Bytes())).Read()
+	manifest, errInspect := io.Inspect(bytes.NewReader(fileData.Bytes()))
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	assert.NoError(t, errRead)
+	assert.NoError(t, errInspect)
+
+	assert.Equal(t, []string{
+		"root/Events",
+		"root/player/Head",
+		"root/player/Body",
+		"root/npc-1/Head",
+		"root/npc-1/Body",
+		"root/npc-2/Head",
+		"root/npc-2/Body",
+	}, paths)
+
+	// Event, Oct48, Raw64 and a single Oct24 shared by both NPCs
+	signatures := make([]string, 0)
+	for _, encoder := range manifest.Encoders {
+		signatures = append(signatures, encoder.Signature)
+	}
+	assert.ElementsMatch(t, []string{"recolude.event", "recolude.position", "recolude.position", "recolude.position"}, signatures)
+
+	maxError := func(recording format.Recording, collection int) float64 {
+		largest := 0.0
+		for i, capture := range recording.CaptureCollections()[collection].Captures() {
+			expected := buildSelectorTestRecording()
+			for _, child := range expected.Recordings() {
+				if child.ID() != recording.ID() {
+					continue
+				}
+				original := child.CaptureCollections()[collection].Captures()[i].(position.Capture).Position()
+				largest = math.Max(largest, original.Distance(capture.(position.Capture).Position()))
+			}
+		}
+		return largest
+	}
+
+	if assert.Len(t, recOut.Recordings(), 3) {
+		player := recOut.Recordings()[0]
+		assert.Greater(t, maxError(player, 0), 0.0)
+		assert.Less(t, maxError(player, 0), 0.1)
+		assert.Equal(t, 0.0, maxError(player, 1))
+
+		for _, npc := range recOut.Recordings()[1:] {
+			assert.Greater(t, maxError(npc, 0), maxError(player, 0))
+			assert.Greater(t, maxError(npc, 1), 0.0)
+		}
+	}
+}
+
+func Test_SelectEncoders_RejectsEncodersNotAccepting(t *testing.T) {
+	// ARRANGE ================================================================
+	selector := func(ctx io.CollectionContext) encoding.Encoder {
+		return eventEncoding.NewEncoder()
+	}
+	writer := io.NewWriter(codecTestEncoders(), true, new(bytes.Buffer), io.Raw64, io.SelectEncoders(selector))
+
+	// ACT ====================================================================
+	_, err := writer.Write(buildSelectorTestRecording())
+
+	// ASSERT =================================================================
+	assert.EqualError(t, err, "encoder recolude.event selected for collection Head does not accept it")
+}
+
+// uncomparableEncoder can't be compared with ==, as it holds a slice.
+type uncomparableEncoder struct {
+	eventEncoding.Encoder
+	tags []string
+}
+
+// uncomparableValueEncoder has a comparable type, but panics when compared
+// with == while it's interface holds a slice.
+type uncomparableValueEncoder struct {
+	eventEncoding.Encoder
+	tags interface{}
+}
+
+func Test_SelectEncoders_UncomparableEncoders(t *testing.T) {
+	tests := map[string]func() encoding.Encoder{
+		"uncomparable type": func() encoding.Encoder {
+			return uncomparableEncoder{Encoder: eventEncoding.NewEncoder(), tags: []string{"events"}}
+		},
+		"uncomparable value": func() encoding.Encoder {
+			return uncomparableValueEncoder{Encoder: eventEncoding.NewEncoder(), tags: []string{"events"}}
+		},
+	}
+
+	for name, newEncoder := range tests {
+		t.Run(name, func(t *testing.T) {
+			// ARRANGE ========================================================
+			selector := func(ctx io.CollectionContext) encoding.Encoder {
+				if ctx.Collection.Signature() == "recolude.event" {
+					return newEncoder()
+				}
+				return nil
+			}
+
+			recIn := format.NewRecording(
+				"root",
+				"Session",
+				[]format.CaptureCollection{
+					event.NewCollection("Start", []event.Capture{event.NewCapture(0, "Start", metadata.EmptyBlock())}),
+					event.NewCollection("End", []event.Capture{event.NewCapture(1, "End", metadata.EmptyBlock())}),
+				},
+				nil,
+				metadata.EmptyBlock(),
+				nil,
+				nil,
+			)
+			fileData := new(bytes.Buffer)
+
+			// ACT ============================================================
+			_, errWrite := io.NewWriter(codecTestEncoders(), true, fileData, io.Raw64, io.SelectEncoders(selector)).Write(recIn)
+			recOut, _, errRead := io.NewReader(codecTestEncoders(), bytes.NewReader(fileData.Bytes())).Read()
+
+			// ASSERT =========================================================
+			assert.NoError(t, errWrite)
+			if assert.NoError(t, errRead) {
+				assertRecordingsMatch(t, recIn, recOut, 0)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package io
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+
+	rapbinary "github.com/recolude/rap/internal/io/binary"
+)
+
+// A signed v2 recording ends with a signature block:
+//
+//   [32 bytes] Ed25519 public key of the signer
+//   [64 bytes] Ed25519 signature of the digest
+//
+// The digest is the SHA-256 of every byte preceding the signature block,
+// starting with the version byte. The header flags the recording as signed,
+// so a signature can't be stripped without the header changing with it.
+
+const signatureSize = ed25519.PublicKeySize + ed25519.SignatureSize
+
+var (
+	// ErrUnsigned is returned when a signature is required but the
+	// recording has none.
+	ErrUnsigned = errors.New("recording is not signed")
+
+	// ErrUntrustedSigner is returned when a recording was signed by a key
+	// that isn't trusted.
+	ErrUntrustedSigner = errors.New("recording signed by untrusted key")
+
+	// ErrInvalidSignature is returned when a recording's signature does not
+	// match it's contents, meaning it has been altered since being signed.
+	ErrInvalidSignature = errors.New("recording signature is invalid")
+)
+
+// Sign has the writer append a signature block, made with the key provided,
+// so the recording can later be proven to be unaltered. Only supported by
+// the v2 layout.
+func Sign(key ed25519.PrivateKey) WriterOption {
+	return func(options *writerOptions) {
+		options.signingKey = key
+	}
+}
+
+// TrustedKeys has the reader require recordings be signed by one of the keys
+// provided, failing the read when the recording is unsigned, signed by
+// anyone else, or altered after being signed.
+func TrustedKeys(keys ...ed25519.PublicKey) ReaderOption {
+	return func(options *readerOptions) {
+		options.requireSignature = true
+		options.trustedKeys = keys
+	}
+}
+
+// ParsePrivateKeyPEM reads an Ed25519 private key from a PKCS #8 PEM block,
+// as produced by `openssl genpkey -algorithm ed25519`.
+func ParsePrivateKeyPEM(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("expected an ed25519 private key, found %T", key)
+	}
+	return edKey, nil
+}
+
+// ParsePublicKeysPEM reads every Ed25519 public key found within PKIX PEM
+// blocks, allowing a single file to hold a set of trusted keys.
+func ParsePublicKeysPEM(data []byte) ([]ed25519.PublicKey, error) {
+	keys := make([]ed25519.PublicKey, 0)
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		edKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("expected an ed25519 public key, found %T", key)
+		}
+		keys = append(keys, edKey)
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no PEM block found")
+	}
+	return keys, nil
+}
+
+// signingWriter hashes everything written through it so a signature block
+// can be appended once the recording has been written.
+type signingWriter struct {
+	out    io.Writer
+	digest hash.Hash
+	key    ed25519.PrivateKey
+}
+
+func newSigningWriter(out io.Writer, key ed25519.PrivateKey) *signingWriter {
+	return &signingWriter{out: out, digest: sha256.New(), key: key}
+}
+
+func (s *signingWriter) Write(p []byte) (int, error) {
+	n, err := s.out.Write(p)
+	s.digest.Write(p[:n])
+	return n, err
+}
+
+// finish writes the signature block. Does nothing when the recording isn't
+// being signed.
+func (s *signingWriter) finish() (int, error) {
+	if s == nil {
+		return 0, nil
+	}
+
+	block := make([]byte, 0, signatureSize)
+	block = append(block, s.key.Public().(ed25519.PublicKey)...)
+	block = append(block, ed25519.Sign(s.key, s.digest.Sum(nil))...)
+	return s.out.Write(block)
+}
+
+// signedReader hashes everything read through it, holding back the final
+// bytes of the input as they are the signature block and not part of the
+// recording's contents.
+type signedReader struct {
+	in     io.Reader
+	digest hash.Hash
+	held   []byte
+	err    error
+}
+
+func (s *signedReader) Read(p []byte) (int, error) {
+	for len(s.held) < signatureSize+len(p) && s.err == nil {
+		chunk := make([]byte, signatureSize+len(p)-len(s.held))
+		n, err := s.in.Read(chunk)
+		s.held = append(s.held, chunk[:n]...)
+		s.err = err
+	}
+
+	available := len(s.held) - signatureSize
+	if available <= 0 {
+		return 0, s.err
+	}
+
+	n := copy(p, s.held[:available])
+	s.digest.Write(p[:n])
+	s.held = s.held[n:]
+	return n, nil
+}
+
+// verify reads whatever remains of the recording and checks the signature
+// block against the keys trusted.
+func (s *signedReader) verify(trusted []ed25519.PublicKey) error {
+	_, err := io.Copy(io.Discard, s)
+	if err != nil {
+		return err
+	}
+
+	if len(s.held) != signatureSize {
+		return fmt.Errorf("%w: signature block is truncated", ErrInvalidSignature)
+	}
+
+	signer := ed25519.PublicKey(s.held[:ed25519.PublicKeySize])
+	signature := s.held[ed25519.PublicKeySize:]
+
+	isTrusted := false
+	for _, key := range trusted {
+		if signer.Equal(key) {
+			isTrusted = true
+			break
+		}
+	}
+
+	if !isTrusted {
+		return ErrUntrustedSigner
+	}
+
+	if !ed25519.Verify(signer, s.digest.Sum(nil), signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// SignRecording copies a v2 recording from in to out, flagging it as signed
+// and appending a signature block made with the key provided. Unlike
+// writing the recording again with Sign, the recording's contents are left
+// untouched.
+func SignRecording(in io.Reader, out io.Writer, key ed25519.PrivateKey) (int, error) {
+	version, _, err := GetRecoringVersion(in)
+	if err != nil {
+		return 0, err
+	}
+
+	if version != 2 {
+		return 0, fmt.Errorf("only v2 recordings can be signed, found version: %d", version)
+	}
+
+	// Capture the encoders exactly as they were written
+	encoders := bytes.Buffer{}
+	tee := io.TeeReader(in, &encoders)
+	signatures, _, err := rapbinary.ReadStringArray(tee)
+	if err != nil {
+		return 0, err
+	}
+	for range signatures {
+		_, _, err = rapbinary.ReadUvarint(tee)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	codecByte := []byte{0}
+	_, err = io.ReadFull(in, codecByte)
+	if err != nil {
+		return 0, err
+	}
+
+	codec, features, err := readFeatures(in, codecByte[0])
+	if err != nil {
+		return 0, err
+	}
+
+	if features&featureSigned != 0 {
+		return 0, errors.New("recording is already signed")
+	}
+
+	keyID := ""
+	if features&featureEncrypted != 0 {
+		keyID, _, err = rapbinary.ReadString(in)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// The header's checksum is replaced, as the header now flags the
+	// recording as signed
+	if features&featureChecksums != 0 {
+		_, err = io.CopyN(io.Discard, in, crc32.Size)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	signer := newSigningWriter(out, key)
+	var headerOut io.Writer = signer
+	var headerSums *checksumWriter
+	if features&featureChecksums != 0 {
+		headerSums = newChecksumWriter(signer)
+		headerOut = headerSums
+	}
+
+	header := &errWriter{Writer: headerOut}
+	header.Write([]byte{2})
+	header.Write(encoders.Bytes())
+	header.Write([]byte{byte(codec) | featureFlag})
+	writeUvarint(header, features|featureSigned)
+	if features&featureEncrypted != 0 {
+		header.Write(rapbinary.StringToBytes(keyID))
+	}
+	headerSums.endSection(header)
+	if header.err != nil {
+		return header.TotalWritten(), header.err
+	}
+
+	written, err := io.Copy(signer, in)
+	total := header.TotalWritten() + int(written)
+	if err != nil {
+		return total, err
+	}
+
+	trailer, err := signer.finish()
+	return total + trailer, err
+}
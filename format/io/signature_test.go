@@ -0,0 +1,156 @@
+package io_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"testing"
+
+	"github.com/recolude/rap/format/io"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSigningKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	return public, private
+}
+
+func Test_Signature_RoundTrip(t *testing.T) {
+	tests := map[string][]io.WriterOption{
+		"none":      {io.Compression(io.CodecNone)},
+		"flate":     {io.Compression(io.CodecFlate)},
+		"zstd":      {io.Compression(io.CodecZstd)},
+		"checksums": {io.Compression(io.CodecZstd), io.Checksums()},
+	}
+
+	for name, options := range tests {
+		t.Run(name, func(t *testing.T) {
+			// ARRANGE ========================================================
+			public, private := newSigningKey(t)
+			other, _ := newSigningKey(t)
+			fileData := new(bytes.Buffer)
+			recIn := buildIndexedTestRecording()
+
+			// ACT ============================================================
+			n, errWrite := io.NewWriter(codecTestEncoders(), false, fileData, io.Raw64, append(options, io.Sign(private))...).Write(recIn)
+			recOut, nOut, errRead := io.NewReader(codecTestEncoders(), bytes.NewReader(fileData.Bytes()), io.TrustedKeys(other, public)).Read()
+			_, _, errUntrusted := io.NewReader(codecTestEncoders(), bytes.NewReader(fileData.Bytes())).Read()
+			manifest, errInspect := io.Inspect(bytes.NewReader(fileData.Bytes()))
+
+			// ASSERT =========================================================
+			assert.NoError(t, errWrite)
+			assert.NoError(t, errRead)
+			assert.NoError(t, errUntrusted)
+			assert.NoError(t, errInspect)
+			assert.Equal(t, n, nOut)
+			assertRecordingsMatch(t, recIn, recOut, 0)
+			assert.True(t, manifest.Signed)
+		})
+	}
+}
+
+func Test_Signature_Rejections(t *testing.T) {
+	// ARRANGE ================================================================
+	public, private := newSigningKey(t)
+	other, _ := newSigningKey(t)
+
+	signed := new(bytes.Buffer)
+	_, err := io.NewWriter(codecTestEncoders(), false, signed, io.Raw64, io.Sign(private)).Write(buildIndexedTestRecording())
+	assert.NoError(t, err)
+
+	unsigned := new(bytes.Buffer)
+	_, err = io.NewWriter(codecTestEncoders(), true, unsigned, io.Raw64).Write(buildIndexedTestRecording())
+	assert.NoError(t, err)
+
+	indexed := new(bytes.Buffer)
+	_, err = io.NewIndexedWriter(codecTestEncoders(), true, indexed, io.Raw64).Write(buildIndexedTestRecording())
+	assert.NoError(t, err)
+
+	tampered := append([]byte{}, signed.Bytes()...)
+	damage(t, tampered, []byte("hello p1"))
+
+	// ACT ====================================================================
+	_, _, errUnsigned := io.NewReader(codecTestEncoders(), bytes.NewReader(unsigned.Bytes()), io.TrustedKeys(public)).Read()
+	_, _, errIndexed := io.NewReader(codecTestEncoders(), bytes.NewReader(indexed.Bytes()), io.TrustedKeys(public)).Read()
+	_, _, errUntrusted := io.NewReader(codecTestEncoders(), bytes.NewReader(signed.Bytes()), io.TrustedKeys(other)).Read()
+	_, _, errTampered := io.NewReader(codecTestEncoders(), bytes.NewReader(tampered), io.TrustedKeys(public)).Read()
+	_, errIndexedWrite := io.NewIndexedWriter(codecTestEncoders(), true, new(bytes.Buffer), io.Raw64, io.Sign(private)).Write(buildIndexedTestRecording())
+
+	// ASSERT =================================================================
+	assert.ErrorIs(t, errUnsigned, io.ErrUnsigned)
+	assert.ErrorIs(t, errIndexed, io.ErrUnsigned)
+	assert.ErrorIs(t, errUntrusted, io.ErrUntrustedSigner)
+	assert.ErrorIs(t, errTampered, io.ErrInvalidSignature)
+	assert.Error(t, errIndexedWrite)
+}
+
+func Test_SignRecording(t *testing.T) {
+	for _, checksums := range []bool{false, true} {
+		t.Run(fmt.Sprintf("checksums %t", checksums), func(t *testing.T) {
+			// ARRANGE ========================================================
+			public, private := newSigningKey(t)
+			options := []io.WriterOption{io.Compression(io.CodecZstd)}
+			if checksums {
+				options = append(options, io.Checksums())
+			}
+
+			original := new(bytes.Buffer)
+			recIn := buildIndexedTestRecording()
+			_, err := io.NewWriter(codecTestEncoders(), false, original, io.Raw64, options...).Write(recIn)
+			assert.NoError(t, err)
+
+			signed := new(bytes.Buffer)
+
+			// ACT ============================================================
+			n, errSign := io.SignRecording(bytes.NewReader(original.Bytes()), signed, private)
+			_, errResign := io.SignRecording(bytes.NewReader(signed.Bytes()), new(bytes.Buffer), private)
+			recOut, _, errRead := io.NewReader(codecTestEncoders(), bytes.NewReader(signed.Bytes()), io.TrustedKeys(public)).Read()
+			report, errVerify := io.NewReader(codecTestEncoders(), bytes.NewReader(signed.Bytes())).Verify()
+
+			// ASSERT =========================================================
+			assert.NoError(t, errSign)
+			assert.Error(t, errResign)
+			assert.NoError(t, errRead)
+			assert.NoError(t, errVerify)
+			assert.Equal(t, signed.Len(), n)
+			assert.Empty(t, report.Damaged)
+			assertRecordingsMatch(t, recIn, recOut, 0)
+		})
+	}
+}
+
+func Test_ParseKeysPEM(t *testing.T) {
+	// ARRANGE ================================================================
+	public, private := newSigningKey(t)
+	other, _ := newSigningKey(t)
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	assert.NoError(t, err)
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})
+
+	publicPEM := []byte{}
+	for _, key := range []ed25519.PublicKey{public, other} {
+		publicDER, err := x509.MarshalPKIXPublicKey(key)
+		assert.NoError(t, err)
+		publicPEM = append(publicPEM, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})...)
+	}
+
+	// ACT ====================================================================
+	parsedPrivate, errPrivate := io.ParsePrivateKeyPEM(privatePEM)
+	parsedPublic, errPublic := io.ParsePublicKeysPEM(publicPEM)
+	_, errEmpty := io.ParsePublicKeysPEM([]byte("not a key"))
+
+	// ASSERT =================================================================
+	assert.NoError(t, errPrivate)
+	assert.NoError(t, errPublic)
+	assert.Error(t, errEmpty)
+	assert.True(t, private.Equal(parsedPrivate))
+	if assert.Len(t, parsedPublic, 2) {
+		assert.True(t, public.Equal(parsedPublic[0]))
+		assert.True(t, other.Equal(parsedPublic[1]))
+	}
+}
@@ -127,25 +127,31 @@ func encodeTime(technique TimeStorageTechnique, out io.Writer, captures []format
 }
 
 func decodeTime64(in io.Reader, numCaptures int) ([]float64, error) {
-	times := make([]float64, numCaptures)
+	times := make([]float64, 0, rapbinary.InitialCapacity(uint64(numCaptures)))
 	for i := 0; i < int(numCaptures); i++ {
 		var time float64
 
-		binary.Read(in, binary.LittleEndian, &time)
+		err := binary.Read(in, binary.LittleEndian, &time)
+		if err != nil {
+			return nil, err
+		}
 
-		times[i] = time
+		times = append(times, time)
 	}
 	return times, nil
 }
 
 func decodeTime32(in io.Reader, numCaptures int) ([]float64, error) {
-	times := make([]float64, numCaptures)
+	times := make([]float64, 0, rapbinary.InitialCapacity(uint64(numCaptures)))
 	for i := 0; i < int(numCaptures); i++ {
 
 		var time32 float32
-		binary.Read(in, binary.LittleEndian, &time32)
+		err := binary.Read(in, binary.LittleEndian, &time32)
+		if err != nil {
+			return nil, err
+		}
 
-		times[i] = float64(time32)
+		times = append(times, float64(time32))
 	}
 
 	return times, nil
@@ -172,27 +178,33 @@ func decodeTimeBST16(in io.Reader, numCaptures int) ([]float64, error) {
 		return nil, err
 	}
 
-	captures := make([]float64, numCaptures)
+	captures := make([]float64, 1, rapbinary.InitialCapacity(uint64(numCaptures)))
 	captures[0] = float64(startTime)
 	buffer := make([]byte, 2)
 	currentTime := float64(startTime)
 
 	for i := 1; i < int(numCaptures); i++ {
-		in.Read(buffer)
+		_, err = io.ReadFull(in, buffer)
+		if err != nil {
+			return nil, err
+		}
 		time := rapbinary.BytesToUnisngedFloatBST(0, float64(maxTimeDifference), buffer)
 		currentTime += time
 
-		captures[i] = currentTime
+		captures = append(captures, currentTime)
 	}
 
 	return captures, nil
 }
 
-func decodeTime(in io.Reader) ([]float64, error) {
+// decodeTime reads the times of every capture within a collection, erroring
+// if there are more than maxCaptures. A maxCaptures of 0 means there is no
+// limit.
+func decodeTime(in io.Reader, maxCaptures uint64) ([]float64, error) {
 	typeByte := []byte{0}
 
 	// Read Storage Technique
-	_, err := in.Read(typeByte)
+	_, err := io.ReadFull(in, typeByte)
 	if err != nil {
 		return nil, err
 	}
@@ -204,6 +216,11 @@ func decodeTime(in io.Reader) ([]float64, error) {
 		return nil, err
 	}
 
+	err = rapbinary.CheckLimit("captures per collection", numCaptures, maxCaptures)
+	if err != nil {
+		return nil, err
+	}
+
 	switch encodingTechnique {
 	case Raw64:
 		return decodeTime64(in, int(numCaptures))
@@ -217,3 +234,68 @@ func decodeTime(in io.Reader) ([]float64, error) {
 
 	return nil, fmt.Errorf("unrecognized time encoding: %d", encodingTechnique)
 }
+
+// TimeSelection describes the time storage technique picked for a collection
+// by a writer using AutoTimeStorage.
+type TimeSelection struct {
+	Collection string
+	Technique  TimeStorageTechnique
+
+	// MaxError is the largest difference between a capture's original time
+	// and the time decoded back out
+	MaxError float64
+}
+
+// autoTimeTechniques are the techniques picked between when writing with
+// AutoTimeStorage, Raw64 being lossless and always within budget.
+var autoTimeTechniques = []TimeStorageTechnique{Raw64, Raw32, BST16}
+
+// AutoTimeStorage has the writer pick the time storage technique producing
+// the fewest bytes for every collection, while keeping every time within
+// maxError of it's original, instead of using the technique it was built
+// with. When not nil, report is called with what was picked for every
+// collection.
+func AutoTimeStorage(maxError float64, report func(TimeSelection)) WriterOption {
+	return func(options *writerOptions) {
+		options.autoTime = true
+		options.maxTimeError = maxError
+		options.timeReport = report
+	}
+}
+
+func selectTimeStorageTechnique(captures []format.Capture, maxError float64) (TimeSelection, []byte, error) {
+	var best []byte
+	selection := TimeSelection{}
+	for _, technique := range autoTimeTechniques {
+		encoded := bytes.Buffer{}
+		_, err := encodeTime(technique, &encoded, captures)
+		if err != nil {
+			return selection, nil, err
+		}
+
+		if best != nil && encoded.Len() >= len(best) {
+			continue
+		}
+
+		times, err := decodeTime(bytes.NewReader(encoded.Bytes()), 0)
+		if err != nil {
+			return selection, nil, err
+		}
+
+		measured := 0.0
+		for i, capture := range captures {
+			measured = math.Max(measured, math.Abs(capture.Time()-times[i]))
+		}
+
+		// Raw64 is always kept as the fallback
+		if best != nil && !(measured <= maxError) {
+			continue
+		}
+
+		best = encoded.Bytes()
+		selection.Technique = technique
+		selection.MaxError = measured
+	}
+
+	return selection, best, nil
+}
@@ -0,0 +1,60 @@
+package io
+
+import "sync"
+
+// EncodeWorkers sets how many capture collections are encoded at once. Values
+// of 1 or less encode collections one at a time. Output is identical
+// regardless of the number of workers.
+func EncodeWorkers(workers int) WriterOption {
+	return func(options *writerOptions) {
+		options.encodeWorkers = workers
+	}
+}
+
+// DecodeWorkers sets how many capture collections are decoded at once. Values
+// of 1 or less decode collections one at a time, as they are read.
+func DecodeWorkers(workers int) ReaderOption {
+	return func(options *readerOptions) {
+		options.decodeWorkers = workers
+	}
+}
+
+// runWorkers calls fn for every job using up to the number of workers
+// provided. The error of the earliest job to fail is returned, so the result
+// never depends on how jobs happened to be scheduled.
+func runWorkers(workers, jobs int, fn func(job int) error) error {
+	if workers <= 1 {
+		for job := 0; job < jobs; job++ {
+			if err := fn(job); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	errs := make([]error, jobs)
+	next := make(chan int)
+	wg := sync.WaitGroup{}
+	for worker := 0; worker < workers && worker < jobs; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range next {
+				errs[job] = fn(job)
+			}
+		}()
+	}
+
+	for job := 0; job < jobs; job++ {
+		next <- job
+	}
+	close(next)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
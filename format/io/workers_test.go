@@ -0,0 +1,159 @@
+package io_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/event"
+	"github.com/recolude/rap/format/collection/float"
+	"github.com/recolude/rap/format/collection/position"
+	"github.com/recolude/rap/format/encoding"
+	eventEncoding "github.com/recolude/rap/format/encoding/event"
+	floatEncoding "github.com/recolude/rap/format/encoding/float"
+	positionEncoding "github.com/recolude/rap/format/encoding/position"
+	"github.com/recolude/rap/format/io"
+	"github.com/recolude/rap/format/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildWorkersTestRecording() format.Recording {
+	player := func(id int) format.Recording {
+		return format.NewRecording(
+			fmt.Sprintf("player-%d", id),
+			fmt.Sprintf("Player %d", id),
+			[]format.CaptureCollection{
+				position.NewCollection("Position", []position.Capture{
+					position.NewCapture(1, float64(id), 2, 3),
+					position.NewCapture(2, float64(id)+1, 5, 6),
+				}),
+				float.NewCollection("Health", []float.Capture{
+					float.NewCapture(1, float64(100-id)),
+				}),
+				event.NewCollection("Events", []event.Capture{
+					event.NewCapture(1.5, fmt.Sprintf("Spawn %d", id), metadata.EmptyBlock()),
+				}),
+			},
+			nil,
+			metadata.EmptyBlock(),
+			nil,
+			nil,
+		)
+	}
+
+	players := make([]format.Recording, 0)
+	for i := 0; i < 16; i++ {
+		players = append(players, player(i))
+	}
+
+	return format.NewRecording(
+		"root",
+		"Session",
+		[]format.CaptureCollection{
+			event.NewCollection("Session Events", []event.Capture{
+				event.NewCapture(0, "Start", metadata.EmptyBlock()),
+			}),
+		},
+		players,
+		metadata.EmptyBlock(),
+		nil,
+		nil,
+	)
+}
+
+func workersTestEncoders() []encoding.Encoder {
+	return []encoding.Encoder{
+		eventEncoding.NewEncoder(),
+		floatEncoding.NewEncoder(floatEncoding.Raw32),
+		positionEncoding.NewEncoder(positionEncoding.Raw64),
+	}
+}
+
+func Test_EncodeWorkers_ProducesIdenticalOutput(t *testing.T) {
+	for _, indexed := range []bool{false, true} {
+		// ARRANGE ============================================================
+		newWriter := io.NewWriter
+		if indexed {
+			newWriter = io.NewIndexedWriter
+		}
+		serial := new(bytes.Buffer)
+		concurrent := new(bytes.Buffer)
+		rec := buildWorkersTestRecording()
+
+		// ACT ================================================================
+		nSerial, errSerial := newWriter(workersTestEncoders(), true, serial, io.BST16).Write(rec)
+		nConcurrent, errConcurrent := newWriter(workersTestEncoders(), true, concurrent, io.BST16, io.EncodeWorkers(8)).Write(rec)
+
+		// ASSERT =============================================================
+		assert.NoError(t, errSerial)
+		assert.NoError(t, errConcurrent)
+		assert.Equal(t, nSerial, nConcurrent)
+		assert.Equal(t, serial.Bytes(), concurrent.Bytes())
+	}
+}
+
+func Test_DecodeWorkers_RoundTrip(t *testing.T) {
+	for _, indexed := range []bool{false, true} {
+		for _, compress := range []bool{false, true} {
+			// ARRANGE ========================================================
+			newWriter := io.NewWriter
+			if indexed {
+				newWriter = io.NewIndexedWriter
+			}
+			fileData := new(bytes.Buffer)
+			recIn := buildWorkersTestRecording()
+
+			// ACT ============================================================
+			n, errWrite := newWriter(workersTestEncoders(), compress, fileData, io.Raw64).Write(recIn)
+			recOut, nOut, errRead := io.NewReader(workersTestEncoders(), bytes.NewReader(fileData.Bytes()), io.DecodeWorkers(4)).Read()
+
+			// ASSERT =========================================================
+			assert.NoError(t, errWrite)
+			assert.NoError(t, errRead)
+			assert.Equal(t, n, nOut)
+			assertRecordingsMatch(t, recIn, recOut, 0)
+		}
+	}
+}
+
+// failingEventEncoder fails to decode every collection whose name is
+// rejected by fails.
+type failingEventEncoder struct {
+	eventEncoding.Encoder
+	fails func(name string) bool
+}
+
+func (e failingEventEncoder) Decode(name string, header []byte, data []byte, times []float64) (format.CaptureCollection, error) {
+	if e.fails(name) {
+		return nil, errors.New("bad collection")
+	}
+	return e.Encoder.Decode(name, header, data, times)
+}
+
+func Test_DecodeWorkers_ReportsEarliestError(t *testing.T) {
+	// ARRANGE ================================================================
+	fileData := new(bytes.Buffer)
+	_, err := io.NewWriter(workersTestEncoders(), false, fileData, io.Raw64).Write(buildWorkersTestRecording())
+	assert.NoError(t, err)
+
+	encoders := []encoding.Encoder{
+		failingEventEncoder{fails: func(name string) bool { return name == "Events" }},
+		floatEncoding.NewEncoder(floatEncoding.Raw32),
+		positionEncoding.NewEncoder(positionEncoding.Raw64),
+	}
+
+	// ACT ====================================================================
+	_, _, errSerial := io.NewReader(encoders, bytes.NewReader(fileData.Bytes())).Read()
+	_, _, errConcurrent := io.NewReader(encoders, bytes.NewReader(fileData.Bytes()), io.DecodeWorkers(4)).Read()
+
+	// ASSERT =================================================================
+	var decodeErr *io.DecodeError
+	if assert.ErrorAs(t, errConcurrent, &decodeErr) {
+		assert.Equal(t, []string{"root", "player-0"}, decodeErr.Path)
+		assert.Equal(t, "Events", decodeErr.Collection)
+		assert.Equal(t, "recolude.event", decodeErr.Encoder)
+	}
+	assert.EqualError(t, errConcurrent, errSerial.Error())
+}
@@ -2,18 +2,16 @@ package io
 
 import (
 	"bytes"
-	"compress/flate"
+	"crypto/cipher"
+	"crypto/ed25519"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"sort"
 
 	"github.com/recolude/rap/format"
 	"github.com/recolude/rap/format/encoding"
-	"github.com/recolude/rap/format/encoding/enum"
-	"github.com/recolude/rap/format/encoding/euler"
-	"github.com/recolude/rap/format/encoding/event"
-	"github.com/recolude/rap/format/encoding/position"
 	"github.com/recolude/rap/format/metadata"
 	rapbinary "github.com/recolude/rap/internal/io/binary"
 )
@@ -48,38 +46,140 @@ type encoderCollectionMapping struct {
 	encoder         encoding.Encoder
 	collections     []format.CaptureCollection
 	collectionOrder []int
+
+	// selected is whether or not the encoder was picked by an
+	// EncoderSelector, in which case it's only ever shared with collections
+	// the same instance was selected for
+	selected bool
+}
+
+// sameEncoder determines whether or not two mappings refer to the same
+// encoder. Signatures alone aren't enough, as opaque collections that share
+// a signature may still have been written with different versions or
+// headers.
+func (m encoderCollectionMapping) sameEncoder(other encoderCollectionMapping) bool {
+	ourOpaque, ourIsOpaque := m.encoder.(opaqueEncoder)
+	otherOpaque, otherIsOpaque := other.encoder.(opaqueEncoder)
+	if ourIsOpaque || otherIsOpaque {
+		return ourIsOpaque && otherIsOpaque &&
+			ourOpaque.signature == otherOpaque.signature &&
+			ourOpaque.version == otherOpaque.version &&
+			bytes.Equal(ourOpaque.header, otherOpaque.header)
+	}
+
+	if m.selected || other.selected {
+		return m.selected && other.selected && sameInstance(m.encoder, other.encoder)
+	}
+	return m.encoder.Signature() == other.encoder.Signature()
+}
+
+// addToMappings assigns a collection to the mapping provided, merging it into
+// an existing mapping of the same encoder if there is one.
+func addToMappings(mappings []encoderCollectionMapping, mapping encoderCollectionMapping, collection format.CaptureCollection, order int) []encoderCollectionMapping {
+	for i, existing := range mappings {
+		if existing.sameEncoder(mapping) {
+			mappings[i].collections = append(existing.collections, collection)
+			mappings[i].collectionOrder = append(existing.collectionOrder, order)
+			return mappings
+		}
+	}
+
+	mapping.collections = []format.CaptureCollection{collection}
+	mapping.collectionOrder = []int{order}
+	return append(mappings, mapping)
+}
+
+type writerOptions struct {
+	computeBinarySizes bool
+	encodeWorkers      int
+
+	codec    CodecID
+	codecSet bool
+	level    int
+	levelSet bool
+
+	checksums   bool
+	deduplicate bool
+
+	autoTime     bool
+	maxTimeError float64
+	timeReport   func(TimeSelection)
+
+	encoderSelector EncoderSelector
+
+	signingKey ed25519.PrivateKey
+
+	keys  KeyProvider
+	keyID string
+}
+
+// WriterOption configures how a Writer writes recordings.
+type WriterOption func(*writerOptions)
+
+// ComputeBinarySizes has the writer determine the size of every embedded
+// binary by buffering it's data, instead of trusting the size the binary
+// reports.
+func ComputeBinarySizes() WriterOption {
+	return func(options *writerOptions) {
+		options.computeBinarySizes = true
+	}
+}
+
+func buildWriterOptions(options []WriterOption) writerOptions {
+	finalOpts := writerOptions{}
+
+	// Loop through each option
+	for _, opt := range options {
+		opt(&finalOpts)
+	}
+
+	return finalOpts
 }
 
 type Writer struct {
 	encoders             []encoding.Encoder
 	timeStorageTechnique TimeStorageTechnique
 	compress             bool
+	indexed              bool
 	out                  io.Writer
+	options              writerOptions
 }
 
-// NewRecoludeWriter builds a new recording writer with default recolude
-// encoders.
-func NewRecoludeWriter(out io.Writer) Writer {
+// NewRecoludeWriter builds a new recording writer with every encoder found
+// in the encoding registry.
+func NewRecoludeWriter(out io.Writer, options ...WriterOption) Writer {
 	return Writer{
-		encoders: []encoding.Encoder{
-			event.NewEncoder(),
-			position.NewEncoder(position.Oct48),
-			euler.NewEncoder(euler.Raw32),
-			enum.NewEncoder(),
-		},
+		encoders:             encoding.Registered(),
 		compress:             true,
 		timeStorageTechnique: BST16,
 		out:                  out,
+		options:              buildWriterOptions(options),
 	}
 }
 
 // NewWriter builds a new writer using the encoders provided.
-func NewWriter(encoders []encoding.Encoder, compress bool, out io.Writer, timeStorageTechnique TimeStorageTechnique) Writer {
+func NewWriter(encoders []encoding.Encoder, compress bool, out io.Writer, timeStorageTechnique TimeStorageTechnique, options ...WriterOption) Writer {
+	return Writer{
+		encoders:             encoders,
+		out:                  out,
+		compress:             compress,
+		timeStorageTechnique: timeStorageTechnique,
+		options:              buildWriterOptions(options),
+	}
+}
+
+// NewIndexedWriter builds a new writer using the encoders provided that writes
+// recordings in the random access (v3) layout, where every sub-recording and
+// capture collection is stored in it's own independently compressed block
+// that can be found through the index at the end of the file.
+func NewIndexedWriter(encoders []encoding.Encoder, compress bool, out io.Writer, timeStorageTechnique TimeStorageTechnique, options ...WriterOption) Writer {
 	return Writer{
 		encoders:             encoders,
 		out:                  out,
 		compress:             compress,
+		indexed:              true,
 		timeStorageTechnique: timeStorageTechnique,
+		options:              buildWriterOptions(options),
 	}
 }
 
@@ -91,42 +191,36 @@ func calcNumStreams(recording format.Recording) int {
 	return len(recording.CaptureCollections()) + total
 }
 
-// accumulateMetdataKeys builds out a mapping of metadata keys to some unique
-// index. Used to ensure the key is only ever written once to file.
-func accumulateMetdataKeys(recording format.Recording, keyMappingToIndex map[string]int) {
-	keyCount := len(keyMappingToIndex)
-
-	for key, _ := range recording.Metadata().Mapping() {
-		if _, ok := keyMappingToIndex[key]; !ok {
-			keyMappingToIndex[key] = keyCount
-			keyCount++
-		}
+// accumulateMetdataKeys collects every metadata key used throughout the
+// recording. Used to ensure the key is only ever written once to file.
+func accumulateMetdataKeys(recording format.Recording, keys map[string]bool) {
+	for key := range recording.Metadata().Mapping() {
+		keys[key] = true
 	}
 
 	for _, ref := range recording.BinaryReferences() {
 		for key := range ref.Metadata().Mapping() {
-			if _, ok := keyMappingToIndex[key]; !ok {
-				keyMappingToIndex[key] = keyCount
-				keyCount++
-			}
+			keys[key] = true
 		}
 	}
 
 	for _, bin := range recording.Binaries() {
 		for key := range bin.Metadata().Mapping() {
-			if _, ok := keyMappingToIndex[key]; !ok {
-				keyMappingToIndex[key] = keyCount
-				keyCount++
-			}
+			keys[key] = true
 		}
 	}
 
 	for _, rec := range recording.Recordings() {
-		accumulateMetdataKeys(rec, keyMappingToIndex)
+		accumulateMetdataKeys(rec, keys)
 	}
 }
 
-func (w Writer) evaluateCollections(recording format.Recording, offset int) ([]encoderCollectionMapping, int, error) {
+func (w Writer) evaluateCollections(recording format.Recording, path []string, offset int) ([]encoderCollectionMapping, int, error) {
+	// Paths are only ever needed by selectors
+	if w.options.encoderSelector != nil {
+		path = append(path, pathSegment(recording.ID(), recording.Name()))
+	}
+
 	mappings := make([]encoderCollectionMapping, 0)
 	streamsSatisfied := make([]bool, len(recording.CaptureCollections()))
 	for i, collection := range recording.CaptureCollections() {
@@ -136,6 +230,44 @@ func (w Writer) evaluateCollections(recording format.Recording, offset int) ([]e
 		streamsSatisfied[i] = false
 	}
 
+	// Opaque collections can only ever be written back out the way they came
+	for streamIndex, stream := range recording.CaptureCollections() {
+		opaque, ok := stream.(OpaqueCollection)
+		if !ok {
+			continue
+		}
+
+		mapping := encoderCollectionMapping{
+			encoder: opaqueEncoder{signature: opaque.signature, version: opaque.version, header: opaque.header},
+		}
+		mappings = addToMappings(mappings, mapping, stream, streamIndex+offset)
+		streamsSatisfied[streamIndex] = true
+	}
+
+	// Collections the selector has an opinion on go to the encoder selected
+	for streamIndex, stream := range recording.CaptureCollections() {
+		if w.options.encoderSelector == nil || streamsSatisfied[streamIndex] {
+			continue
+		}
+
+		encoder := w.options.encoderSelector(CollectionContext{
+			Path:       append([]string{}, path...),
+			Recording:  recording,
+			Collection: stream,
+		})
+		if encoder == nil {
+			continue
+		}
+
+		if !encoder.Accepts(stream) {
+			return nil, 0, fmt.Errorf("encoder %s selected for collection %s does not accept it", encoder.Signature(), stream.Name())
+		}
+
+		mapping := encoderCollectionMapping{encoder: encoder, selected: true}
+		mappings = addToMappings(mappings, mapping, stream, streamIndex+offset)
+		streamsSatisfied[streamIndex] = true
+	}
+
 	for i, encoder := range w.encoders {
 
 		mapping := encoderCollectionMapping{encoder: w.encoders[i]}
@@ -161,7 +293,7 @@ func (w Writer) evaluateCollections(recording format.Recording, offset int) ([]e
 	curOffset := offset + len(recording.CaptureCollections())
 
 	for _, childRecording := range recording.Recordings() {
-		childMappings, newOffset, err := w.evaluateCollections(childRecording, curOffset)
+		childMappings, newOffset, err := w.evaluateCollections(childRecording, path, curOffset)
 		curOffset = newOffset
 		if err != nil {
 			return nil, 0, err
@@ -169,7 +301,7 @@ func (w Writer) evaluateCollections(recording format.Recording, offset int) ([]e
 		for _, childMap := range childMappings {
 			found := false
 			for i, ourMap := range mappings {
-				if ourMap.encoder.Signature() == childMap.encoder.Signature() {
+				if ourMap.sameEncoder(childMap) {
 					mappings[i].collections = append(ourMap.collections, childMap.collections...)
 					mappings[i].collectionOrder = append(ourMap.collectionOrder, childMap.collectionOrder...)
 					found = true
@@ -188,12 +320,9 @@ func writeMetadata(out io.Writer, keyMappingToIndex map[string]int, block metada
 	metadataIndices := make([]uint, len(block.Mapping()))
 
 	metadataValuesBuffer := bytes.Buffer{}
-	i := 0
-	for key, val := range block.Mapping() {
+	for i, key := range block.Keys() {
 		metadataIndices[i] = uint(keyMappingToIndex[key])
-
-		metadata.WriteProprty(&metadataValuesBuffer, val)
-		i++
+		metadata.WriteProprty(&metadataValuesBuffer, block.Mapping()[key])
 	}
 
 	totalBytes := 0
@@ -241,7 +370,14 @@ func writeEncoders(out io.Writer, encoders []encoderCollectionMapping) (int, err
 	return totalWritten, err
 }
 
-func recurseRecordingToBytes(out io.Writer, recording format.Recording, keyMappingToIndex map[string]int, encodingBlocks [][]byte, streamIndexToEncoderUsedIndex []int, offset int, tech TimeStorageTechnique) (int, int, error) {
+func writeUvarint(out io.Writer, value uint64) (int, error) {
+	buf := make([]byte, binary.MaxVarintLen64)
+	read := binary.PutUvarint(buf, value)
+	return out.Write(buf[:read])
+}
+
+// writeRecordingHeader writes the id, name, and metadata of a recording.
+func writeRecordingHeader(out io.Writer, recording format.Recording, keyMappingToIndex map[string]int) {
 	ew := &errWriter{Writer: out}
 
 	// Write id
@@ -252,73 +388,202 @@ func recurseRecordingToBytes(out io.Writer, recording format.Recording, keyMappi
 
 	// Write metadata
 	writeMetadata(ew, keyMappingToIndex, recording.Metadata())
+}
 
-	// Write number of streams
-	numStreams := make([]byte, binary.MaxVarintLen64)
-	read := binary.PutUvarint(numStreams, uint64(len(recording.CaptureCollections())))
-	ew.Write(numStreams[:read])
+// writeCollection writes a single capture collection, prefixed with the index
+// of the encoder that was used to encode it. When deduplicating, collections
+// already written are instead written as a reference followed by their name.
+func writeCollection(out io.Writer, collection format.CaptureCollection, encoderIndex int, encodedBlock []byte, times []byte, dedup *dedupWriter) error {
+	ew := &errWriter{Writer: out}
 
-	// Write all streams
-	for streamIndex := range recording.CaptureCollections() {
-		// Write index of the encoder used to encode stream
-		encoderIndex := make([]byte, binary.MaxVarintLen64)
-		read := binary.PutUvarint(encoderIndex, uint64(streamIndexToEncoderUsedIndex[offset+streamIndex]))
-		ew.Write(encoderIndex[:read])
+	if dedup != nil {
+		ref := dedup.collection(encoderIndex, times, encodedBlock)
+		writeUvarint(ew, ref)
+		if ref != 0 {
+			ew.Write(rapbinary.StringToBytes(collection.Name()))
+			return ew.err
+		}
+	}
+
+	// Write index of the encoder used to encode stream
+	writeUvarint(ew, uint64(encoderIndex))
+
+	ew.Write(rapbinary.StringToBytes(collection.Name()))
+	ew.Write(times)
+
+	// Write stream data
+	ew.Write(rapbinary.BytesArrayToBytes(encodedBlock))
+	return ew.err
+}
+
+// encodeTimes builds the block of times written with a collection.
+func (w Writer) encodeTimes(collection format.CaptureCollection) ([]byte, error) {
+	if opaque, ok := collection.(OpaqueCollection); ok && opaque.timeBlock != nil {
+		return opaque.timeBlock, nil
+	}
+
+	if w.options.autoTime {
+		selection, times, err := selectTimeStorageTechnique(collection.Captures(), w.options.maxTimeError)
+		if err != nil {
+			return nil, err
+		}
+
+		selection.Collection = collection.Name()
+		if w.options.timeReport != nil {
+			w.options.timeReport(selection)
+		}
+		return times, nil
+	}
+
+	times := bytes.Buffer{}
+	_, err := encodeTime(w.timeStorageTechnique, &times, collection.Captures())
+	return times.Bytes(), err
+}
+
+// bufferBinaryData reads all of a binary's data into memory so it's size can
+// be determined.
+func bufferBinaryData(data io.Reader) (*bytes.Buffer, error) {
+	buffered := &bytes.Buffer{}
+	_, err := io.Copy(buffered, data)
+	return buffered, err
+}
 
-		ew.Write(rapbinary.StringToBytes(recording.CaptureCollections()[streamIndex].Name()))
-		encodeTime(tech, ew, recording.CaptureCollections()[streamIndex].Captures())
+// copyBinaryData writes the data of a binary, ensuring it is exactly the size
+// it was said to be.
+func copyBinaryData(out io.Writer, data io.Reader, size uint64) error {
+	counter := &countingReader{Reader: data}
 
-		// Write stream data
-		ew.Write(rapbinary.BytesArrayToBytes(encodingBlocks[offset+streamIndex]))
+	_, err := io.CopyN(out, counter, int64(size))
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: data was %d bytes but size is %d", ErrBinarySizeMismatch, counter.n, size)
+	}
+	if err != nil {
+		return err
+	}
+
+	extra, err := io.Copy(io.Discard, counter)
+	if err != nil {
+		return err
+	}
+	if extra > 0 {
+		return fmt.Errorf("%w: data was %d bytes but size is %d", ErrBinarySizeMismatch, counter.n, size)
 	}
 
+	return nil
+}
+
+// writeBinaries writes out both the references and the binaries embedded
+// within the recording provided.
+func writeBinaries(out io.Writer, recording format.Recording, keyMappingToIndex map[string]int, path []string, opts writerOptions, sums *checksumWriter, dedup *dedupWriter) error {
+	ew := &errWriter{Writer: out}
+
 	// Write number of references
-	numReferences := make([]byte, binary.MaxVarintLen64)
-	read = binary.PutUvarint(numReferences, uint64(len(recording.BinaryReferences())))
-	ew.Write(numReferences[:read])
+	writeUvarint(ew, uint64(len(recording.BinaryReferences())))
 
 	// Write binary references
 	for _, ref := range recording.BinaryReferences() {
 		ew.Write(rapbinary.StringToBytes(ref.Name()))
 		ew.Write(rapbinary.StringToBytes(ref.URI()))
-
-		refSize := make([]byte, binary.MaxVarintLen64)
-		read = binary.PutUvarint(refSize, ref.Size())
-		ew.Write(refSize[:read])
-
+		writeUvarint(ew, ref.Size())
 		writeMetadata(ew, keyMappingToIndex, ref.Metadata())
 	}
 
 	// Write number of binaries
-	numBinaries := make([]byte, binary.MaxVarintLen64)
-	read = binary.PutUvarint(numBinaries, uint64(len(recording.Binaries())))
-	ew.Write(numBinaries[:read])
+	writeUvarint(ew, uint64(len(recording.Binaries())))
+	sums.endSection(ew)
 
 	// Write binaries
 	for _, bin := range recording.Binaries() {
-		ew.Write(rapbinary.StringToBytes(bin.Name()))
+		data := bin.Data()
+		size := bin.Size()
+		ref := uint64(0)
+		if opts.computeBinarySizes || dedup != nil {
+			buffered, err := bufferBinaryData(data)
+			if err != nil {
+				return &BinaryError{Path: append([]string{}, path...), Binary: bin.Name(), Err: err}
+			}
+			data = buffered
+			if opts.computeBinarySizes {
+				size = uint64(buffered.Len())
+			}
 
-		refSize := make([]byte, binary.MaxVarintLen64)
-		read = binary.PutUvarint(refSize, bin.Size())
-		ew.Write(refSize[:read])
+			if dedup != nil {
+				if uint64(buffered.Len()) != size {
+					err = fmt.Errorf("%w: data was %d bytes but size is %d", ErrBinarySizeMismatch, buffered.Len(), size)
+					return &BinaryError{Path: append([]string{}, path...), Binary: bin.Name(), Err: err}
+				}
+				ref = dedup.binary(buffered.Bytes())
+			}
+		}
 
+		ew.Write(rapbinary.StringToBytes(bin.Name()))
+		writeUvarint(ew, size)
 		writeMetadata(ew, keyMappingToIndex, bin.Metadata())
+		if dedup != nil {
+			writeUvarint(ew, ref)
+		}
+		sums.endSection(ew)
+		if ew.err != nil {
+			return ew.err
+		}
+
+		// Binaries already written only refer back to the original's data
+		if ref != 0 {
+			continue
+		}
 
-		actualbinaryWritten, _ := io.Copy(ew, bin.Data())
-		if actualbinaryWritten != int64(bin.Size()) {
-			panic("Binary data written was larger than size in signature")
+		dataSum := newChecksum()
+		err := copyBinaryData(io.MultiWriter(ew, dataSum), data, size)
+		if ew.err != nil {
+			return ew.err
+		}
+		if err != nil {
+			return &BinaryError{Path: append([]string{}, path...), Binary: bin.Name(), Err: err}
 		}
+		sums.endBinaryData(ew, dataSum.Sum32())
+	}
+
+	return ew.err
+}
+
+func (w Writer) recurseRecordingToBytes(out io.Writer, recording format.Recording, encoded *encodedRecording, path []string, offset int, sums *checksumWriter, dedup *dedupWriter) (int, int, error) {
+	ew := &errWriter{Writer: out}
+	path = append(path, pathSegment(recording.ID(), recording.Name()))
+	keyMappingToIndex := encoded.keyMappingToIndex
+
+	writeRecordingHeader(ew, recording, keyMappingToIndex)
+
+	// Write number of streams
+	writeUvarint(ew, uint64(len(recording.CaptureCollections())))
+	sums.endSection(ew)
+
+	// Write all streams
+	for streamIndex, collection := range recording.CaptureCollections() {
+		times, err := w.encodeTimes(collection)
+		if err != nil {
+			return ew.TotalWritten(), -1, err
+		}
+
+		err = writeCollection(ew, collection, encoded.streamIndexToEncoderUsedIndex[offset+streamIndex], encoded.encodingBlocks[offset+streamIndex], times, dedup)
+		if err != nil {
+			return ew.TotalWritten(), -1, err
+		}
+		sums.endSection(ew)
+	}
+
+	err := writeBinaries(ew, recording, keyMappingToIndex, path, w.options, sums, dedup)
+	if err != nil {
+		return ew.TotalWritten(), -1, err
 	}
 
 	// Write number of recordings
-	numRecordings := make([]byte, binary.MaxVarintLen64)
-	read = binary.PutUvarint(numRecordings, uint64(len(recording.Recordings())))
-	ew.Write(numRecordings[:read])
+	writeUvarint(ew, uint64(len(recording.Recordings())))
+	sums.endSection(ew)
 
 	// Write all child recordings
 	newOffset := offset + len(recording.CaptureCollections())
 	for _, rec := range recording.Recordings() {
-		_, updatedOffset, err := recurseRecordingToBytes(ew, rec, keyMappingToIndex, encodingBlocks, streamIndexToEncoderUsedIndex, newOffset, tech)
+		_, updatedOffset, err := w.recurseRecordingToBytes(ew, rec, encoded, path, newOffset, sums, dedup)
 		if err != nil {
 			return ew.TotalWritten(), -1, err
 		}
@@ -355,77 +620,245 @@ func checkForNilInterfaces(recording format.Recording) error {
 	return nil
 }
 
+// encodedRecording is the result of running every capture collection within a
+// recording through the encoder assigned to it.
+type encodedRecording struct {
+	encoderMappings               []encoderCollectionMapping
+	encoderHeaders                [][]byte
+	encodingBlocks                [][]byte
+	streamIndexToEncoderUsedIndex []int
+	metadataKeys                  []string
+	keyMappingToIndex             map[string]int
+}
+
+// encodeJob encodes either every collection belonging to an encoder, or just
+// a single one of them when the encoder's collections are independent of one
+// another.
+type encodeJob struct {
+	encoderIndex int
+
+	// collection is the index of the single collection to encode, or -1 when
+	// all of the encoder's collections are to be encoded together
+	collection int
+}
+
+func (job encodeJob) run(encoderMappings []encoderCollectionMapping, encoded *encodedRecording) error {
+	mapping := encoderMappings[job.encoderIndex]
+
+	if job.collection >= 0 {
+		block, err := mapping.encoder.(encoding.CollectionEncoder).EncodeCollection(mapping.collections[job.collection])
+		if err != nil {
+			return err
+		}
+		order := mapping.collectionOrder[job.collection]
+		encoded.encodingBlocks[order] = block
+		encoded.streamIndexToEncoderUsedIndex[order] = job.encoderIndex
+		return nil
+	}
+
+	header, streamsEncoded, err := mapping.encoder.Encode(mapping.collections)
+	if err != nil {
+		return err
+	}
+
+	for i, order := range mapping.collectionOrder {
+		encoded.encodingBlocks[order] = streamsEncoded[i]
+		encoded.streamIndexToEncoderUsedIndex[order] = job.encoderIndex
+	}
+	encoded.encoderHeaders[job.encoderIndex] = header
+	return nil
+}
+
+// encodeJobs splits the work of encoding every collection into jobs that can
+// be ran independently of one another.
+func (w Writer) encodeJobs(encoderMappings []encoderCollectionMapping) []encodeJob {
+	jobs := make([]encodeJob, 0, len(encoderMappings))
+	for encoderIndex, mapping := range encoderMappings {
+		_, independent := mapping.encoder.(encoding.CollectionEncoder)
+		if w.options.encodeWorkers <= 1 || !independent {
+			jobs = append(jobs, encodeJob{encoderIndex: encoderIndex, collection: -1})
+			continue
+		}
+
+		for i := range mapping.collections {
+			jobs = append(jobs, encodeJob{encoderIndex: encoderIndex, collection: i})
+		}
+	}
+	return jobs
+}
+
+// encode validates the recording and runs all of it's capture collections
+// through their respective encoders.
+func (w Writer) encode(recording format.Recording) (*encodedRecording, error) {
+	err := checkForNilInterfaces(recording)
+	if err != nil {
+		return nil, err
+	}
+
+	encoderMappings, _, err := w.evaluateCollections(recording, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	numStreams := calcNumStreams(recording)
+	encoded := &encodedRecording{
+		encoderMappings:               encoderMappings,
+		encoderHeaders:                make([][]byte, len(encoderMappings)),
+		encodingBlocks:                make([][]byte, numStreams),
+		streamIndexToEncoderUsedIndex: make([]int, numStreams),
+		keyMappingToIndex:             make(map[string]int),
+	}
+
+	jobs := w.encodeJobs(encoderMappings)
+	err = runWorkers(w.options.encodeWorkers, len(jobs), func(job int) error {
+		return jobs[job].run(encoderMappings, encoded)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Keys are sorted so the same recording always produces the same bytes
+	keys := make(map[string]bool)
+	accumulateMetdataKeys(recording, keys)
+	encoded.metadataKeys = make([]string, 0, len(keys))
+	for key := range keys {
+		encoded.metadataKeys = append(encoded.metadataKeys, key)
+	}
+	sort.Strings(encoded.metadataKeys)
+	for index, key := range encoded.metadataKeys {
+		encoded.keyMappingToIndex[key] = index
+	}
+
+	return encoded, nil
+}
+
 // Write will take the recording provided and write it to the underlying stream
 // the writer was built with.
+//
+// Output is canonical: equal recordings written with the same encoders and
+// options always produce identical bytes, regardless of map iteration order
+// or the number of workers used. Metadata keys are written in sorted order,
+// while tables built by encoders, such as event names and enum members, are
+// ordered by first appearance. Encrypted recordings are the one exception, as
+// every one is sealed with random nonces.
 func (w Writer) Write(recording format.Recording) (int, error) {
 	if recording == nil {
-		panic(errors.New("can not write nil recording"))
+		return 0, errors.New("can not write nil recording")
 	}
 
-	err := checkForNilInterfaces(recording)
+	if w.indexed {
+		return w.writeIndexed(recording)
+	}
+
+	codec, level, err := w.codec()
 	if err != nil {
 		return 0, err
 	}
 
-	encoderMappings, _, err := w.evaluateCollections(recording, 0)
+	encoded, err := w.encode(recording)
 	if err != nil {
 		return 0, err
 	}
 
 	totalBytesWritten := 0
 
+	features := uint64(0)
+	var aead cipher.AEAD
+	if w.options.keys != nil {
+		features |= featureEncrypted
+		aead, err = newCipher(w.options.keys, w.options.keyID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	out := w.out
+	var signer *signingWriter
+	if w.options.signingKey != nil {
+		features |= featureSigned
+		signer = newSigningWriter(w.out, w.options.signingKey)
+		out = signer
+	}
+
+	var dedup *dedupWriter
+	if w.options.deduplicate {
+		features |= featureDeduplicated
+		dedup = newDedupWriter()
+	}
+
+	headerOut := out
+	var headerSums *checksumWriter
+	if w.options.checksums {
+		features |= featureChecksums
+		headerSums = newChecksumWriter(out)
+		headerOut = headerSums
+	}
+
 	// Write version number
-	written, err := w.out.Write([]byte{2})
+	written, err := headerOut.Write([]byte{2})
 	totalBytesWritten += written
 	if err != nil {
 		return totalBytesWritten, err
 	}
 
-	// Write encoders used
-	written, err = writeEncoders(w.out, encoderMappings)
+	// Write encoders used, keeping them so encrypted contents can be bound
+	// to them
+	encoders := &bytes.Buffer{}
+	_, err = writeEncoders(encoders, encoded.encoderMappings)
+	if err != nil {
+		return totalBytesWritten, err
+	}
+	written, err = headerOut.Write(encoders.Bytes())
 	totalBytesWritten += written
 	if err != nil {
 		return totalBytesWritten, err
 	}
 
-	// Build compression writer
-	var compressWriter io.WriteCloser
-	if w.compress {
-		written, err = w.out.Write([]byte{1})
-		totalBytesWritten += written
-		if err != nil {
-			return totalBytesWritten, err
-		}
-		compressWriter, err = flate.NewWriter(w.out, 9 /*Best Compression*/)
-		if err != nil {
-			return totalBytesWritten, err
-		}
+	// Write codec, followed by the features used if there are any
+	header := &errWriter{Writer: headerOut}
+	if features == 0 {
+		header.Write([]byte{byte(codec.ID())})
 	} else {
-		written, err = w.out.Write([]byte{0})
-		totalBytesWritten += written
-		if err != nil {
-			return totalBytesWritten, err
-		}
-		compressWriter = &errWriter{Writer: w.out}
+		header.Write([]byte{byte(codec.ID()) | featureFlag})
+		writeUvarint(header, features)
+	}
+	if aead != nil {
+		header.Write(rapbinary.StringToBytes(w.options.keyID))
+	}
+	headerSums.endSection(header)
+	totalBytesWritten += header.TotalWritten()
+	if header.err != nil {
+		return totalBytesWritten, header.err
 	}
 
-	numStreams := calcNumStreams(recording)
-	encodingBlocks := make([][]byte, numStreams)
-	streamIndexToEncoderUsedIndex := make([]int, numStreams)
-
-	for encoderIndex, val := range encoderMappings {
-		header, streamsEncoded, err := val.encoder.Encode(val.collections)
+	// Contents are compressed before being encrypted
+	var encrypter *encryptingWriter
+	contents := out
+	if aead != nil {
+		bound := authenticatedHeader(append([]byte{2}, encoders.Bytes()...), codec.ID(), features, w.options.keyID)
+		encrypter, err = newEncryptingWriter(out, aead, bound)
 		if err != nil {
 			return totalBytesWritten, err
 		}
+		contents = encrypter
+	}
 
-		for i, order := range val.collectionOrder {
-			encodingBlocks[order] = streamsEncoded[i]
-			streamIndexToEncoderUsedIndex[order] = encoderIndex
-		}
+	// Build compression writer
+	compressWriter, err := codec.NewWriter(contents, level)
+	if err != nil {
+		return totalBytesWritten, err
+	}
 
-		// Write header
-		written, err = compressWriter.Write(rapbinary.BytesArrayToBytes(header))
+	var body io.Writer = compressWriter
+	var sums *checksumWriter
+	if w.options.checksums {
+		sums = newChecksumWriter(compressWriter)
+		body = sums
+	}
+
+	// Write headers
+	for _, header := range encoded.encoderHeaders {
+		written, err = body.Write(rapbinary.BytesArrayToBytes(header))
 		totalBytesWritten += written
 		if err != nil {
 			return totalBytesWritten, err
@@ -433,29 +866,32 @@ func (w Writer) Write(recording format.Recording) (int, error) {
 	}
 
 	// Write metadata keys
-	keyMappingToIndex := make(map[string]int)
-	accumulateMetdataKeys(recording, keyMappingToIndex)
-	allKeys := make([]string, len(keyMappingToIndex))
-	for key, index := range keyMappingToIndex {
-		allKeys[index] = key
+	keys := &errWriter{Writer: body}
+	keys.Write(rapbinary.StringArrayToBytes(encoded.metadataKeys))
+	sums.endSection(keys)
+	totalBytesWritten += keys.TotalWritten()
+	if keys.err != nil {
+		return totalBytesWritten, keys.err
 	}
-	written, err = compressWriter.Write(rapbinary.StringArrayToBytes(allKeys))
+
+	// Write out all recordings
+	written, _, err = w.recurseRecordingToBytes(body, recording, encoded, nil, 0, sums, dedup)
 	totalBytesWritten += written
 	if err != nil {
 		return totalBytesWritten, err
 	}
 
-	// Write out all recordings
-	written, _, err = recurseRecordingToBytes(compressWriter, recording, keyMappingToIndex, encodingBlocks, streamIndexToEncoderUsedIndex, 0, w.timeStorageTechnique)
-	totalBytesWritten += written
+	err = compressWriter.Close()
 	if err != nil {
 		return totalBytesWritten, err
 	}
 
-	err = compressWriter.Close()
+	err = encrypter.Close()
 	if err != nil {
 		return totalBytesWritten, err
 	}
 
+	written, err = signer.finish()
+	totalBytesWritten += written
 	return totalBytesWritten, err
 }
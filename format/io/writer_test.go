@@ -2,10 +2,12 @@ package io_test
 
 import (
 	"bytes"
+	"io/ioutil"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/recolude/rap/format"
+	"github.com/recolude/rap/format/collection/event"
 	"github.com/recolude/rap/format/io"
 	"github.com/recolude/rap/format/metadata"
 	"github.com/recolude/rap/internal/mocks"
@@ -35,12 +37,114 @@ func Test_ErrorsWithNoValidEncoders(t *testing.T) {
 	assert.EqualError(t, err, "no encoder registered to handle stream: test.data")
 }
 
-func Test_PanicsWithNilRecording(t *testing.T) {
+func Test_ErrorsWithNilRecording(t *testing.T) {
 	// ARRANGE ================================================================
 	w := io.NewWriter(nil, false, nil, io.Raw64)
 
-	// ACT/ASSERT =============================================================
-	assert.PanicsWithError(t, "can not write nil recording", func() { w.Write(nil) })
+	// ACT ====================================================================
+	n, err := w.Write(nil)
+
+	// ASSERT =================================================================
+	assert.EqualError(t, err, "can not write nil recording")
+	assert.Equal(t, 0, n)
+}
+
+func buildMismatchedBinaryRecording(data string, size uint64) format.Recording {
+	return format.NewRecording(
+		"root",
+		"Root",
+		nil,
+		[]format.Recording{
+			format.NewRecording(
+				"",
+				"Child",
+				nil,
+				nil,
+				metadata.EmptyBlock(),
+				[]format.Binary{
+					sizedBinary{Binary: io.NewBinary("voice", []byte(data), metadata.EmptyBlock()), size: size},
+				},
+				nil,
+			),
+		},
+		metadata.EmptyBlock(),
+		nil,
+		nil,
+	)
+}
+
+// sizedBinary reports a size that may not match it's data.
+type sizedBinary struct {
+	io.Binary
+	size uint64
+}
+
+func (sb sizedBinary) Size() uint64 {
+	return sb.size
+}
+
+func Test_ErrorsWithMismatchedBinarySize(t *testing.T) {
+	tests := map[string]struct {
+		size    uint64
+		message string
+	}{
+		"data larger than size":  {size: 4, message: "writing recording root/Child, binary voice: binary data does not match its size: data was 11 bytes but size is 4"},
+		"data smaller than size": {size: 20, message: "writing recording root/Child, binary voice: binary data does not match its size: data was 11 bytes but size is 20"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, indexed := range []bool{false, true} {
+				// ARRANGE ====================================================
+				newWriter := io.NewWriter
+				if indexed {
+					newWriter = io.NewIndexedWriter
+				}
+				w := newWriter(nil, true, new(bytes.Buffer), io.Raw64)
+
+				// ACT ========================================================
+				_, err := w.Write(buildMismatchedBinaryRecording("hello world", tc.size))
+
+				// ASSERT =====================================================
+				assert.EqualError(t, err, tc.message)
+				assert.ErrorIs(t, err, io.ErrBinarySizeMismatch)
+
+				var binErr *io.BinaryError
+				if assert.ErrorAs(t, err, &binErr) {
+					assert.Equal(t, []string{"root", "Child"}, binErr.Path)
+					assert.Equal(t, "voice", binErr.Binary)
+				}
+			}
+		})
+	}
+}
+
+func Test_ComputeBinarySizes(t *testing.T) {
+	for _, indexed := range []bool{false, true} {
+		// ARRANGE ============================================================
+		fileData := new(bytes.Buffer)
+		newWriter := io.NewWriter
+		if indexed {
+			newWriter = io.NewIndexedWriter
+		}
+		w := newWriter(nil, false, fileData, io.Raw64, io.ComputeBinarySizes())
+
+		// ACT ================================================================
+		n, errWrite := w.Write(buildMismatchedBinaryRecording("hello world", 0))
+		recOut, nOut, errRead := io.NewReader(nil, fileData).Read()
+
+		// ASSERT =============================================================
+		assert.NoError(t, errWrite)
+		assert.NoError(t, errRead)
+		assert.Equal(t, n, nOut)
+		if assert.Len(t, recOut.Recordings(), 1) && assert.Len(t, recOut.Recordings()[0].Binaries(), 1) {
+			binary := recOut.Recordings()[0].Binaries()[0]
+			data, err := ioutil.ReadAll(binary.Data())
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(11), binary.Size())
+			assert.Equal(t, "hello world", string(data))
+		}
+	}
 }
 
 func Test_ErrorsWithNilSubRecording(t *testing.T) {
@@ -139,3 +243,107 @@ func Test_ErrorsWithNilBinaries(t *testing.T) {
 	assert.Len(t, out.Bytes(), 0)
 	assert.EqualError(t, err, "can not serialize recording with nil binaries")
 }
+
+func buildDeterminismTestRecording() format.Recording {
+	block := func(prefix string) metadata.Block {
+		mapping := make(map[string]metadata.Property)
+		for _, key := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+			mapping[prefix+key] = metadata.NewStringProperty(key)
+		}
+		mapping[prefix+"nested"] = metadata.NewMetadataProperty(metadata.NewBlock(map[string]metadata.Property{
+			"x": metadata.NewIntProperty(1),
+			"y": metadata.NewIntProperty(2),
+			"z": metadata.NewIntProperty(3),
+		}))
+		return metadata.NewBlock(mapping)
+	}
+
+	return format.NewRecording(
+		"root",
+		"Session",
+		nil,
+		[]format.Recording{
+			format.NewRecording("child", "Child", nil, nil, block("child-"), []format.Binary{
+				io.NewBinary("voice", []byte("hello"), block("binary-")),
+			}, nil),
+		},
+		block("root-"),
+		nil,
+		nil,
+	)
+}
+
+func Test_WriteIsDeterministic(t *testing.T) {
+	for _, indexed := range []bool{false, true} {
+		// ARRANGE ============================================================
+		newWriter := io.NewWriter
+		if indexed {
+			newWriter = io.NewIndexedWriter
+		}
+		expected := new(bytes.Buffer)
+
+		// ACT ================================================================
+		_, err := newWriter(codecTestEncoders(), true, expected, io.Raw64).Write(buildDeterminismTestRecording())
+
+		// ASSERT =============================================================
+		assert.NoError(t, err)
+		for i := 0; i < 20; i++ {
+			actual := new(bytes.Buffer)
+			_, err := newWriter(codecTestEncoders(), true, actual, io.Raw64).Write(buildDeterminismTestRecording())
+			assert.NoError(t, err)
+			assert.Equal(t, expected.Bytes(), actual.Bytes())
+		}
+	}
+}
+
+func Test_AutoTimeStorage(t *testing.T) {
+	// ARRANGE ================================================================
+	regular := make([]event.Capture, 100)
+	outlier := make([]event.Capture, 100)
+	for i := range regular {
+		regular[i] = event.NewCapture(float64(i)*0.1, "tick", metadata.EmptyBlock())
+		outlier[i] = event.NewCapture(float64(i)*0.1, "tick", metadata.EmptyBlock())
+	}
+	outlier[len(outlier)-1] = event.NewCapture(1000000.123, "late", metadata.EmptyBlock())
+
+	recIn := format.NewRecording(
+		"root",
+		"Session",
+		[]format.CaptureCollection{
+			event.NewCollection("Regular", regular),
+			event.NewCollection("Outlier", outlier),
+		},
+		nil,
+		metadata.EmptyBlock(),
+		nil,
+		nil,
+	)
+
+	selections := make([]io.TimeSelection, 0)
+	report := func(selection io.TimeSelection) {
+		selections = append(selections, selection)
+	}
+	fileData := new(bytes.Buffer)
+
+	// ACT ====================================================================
+	_, errWrite := io.NewWriter(codecTestEncoders(), false, fileData, io.Raw32, io.AutoTimeStorage(0.001, report)).Write(recIn)
+	recOut, _, errRead := io.NewReader(codecTestEncoders(), bytes.NewReader(fileData.Bytes())).Read()
+
+	// ASSERT =================================================================
+	assert.NoError(t, errWrite)
+	assert.NoError(t, errRead)
+	if assert.Len(t, selections, 2) {
+		assert.Equal(t, "Regular", selections[0].Collection)
+		assert.Equal(t, io.BST16, selections[0].Technique)
+		assert.Equal(t, "Outlier", selections[1].Collection)
+		assert.Equal(t, io.Raw64, selections[1].Technique)
+		assert.Equal(t, 0.0, selections[1].MaxError)
+	}
+
+	for i, collection := range recOut.CaptureCollections() {
+		for j, capture := range collection.Captures() {
+			assert.InDelta(t, recIn.CaptureCollections()[i].Captures()[j].Time(), capture.Time(), selections[i].MaxError+1e-12)
+			assert.LessOrEqual(t, selections[i].MaxError, 0.001)
+		}
+	}
+}
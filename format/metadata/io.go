@@ -31,8 +31,26 @@ func readFloat64s(r io.Reader, count int) ([]float64, error) {
 	return outValues, nil
 }
 
-func readNestedMetadatablock(b io.Reader) (Block, error) {
-	metadataKeys, _, err := rapbin.ReadStringArray(b)
+// propertyReader reads properties while enforcing limits on how much memory
+// and recursion the data being read can request.
+type propertyReader struct {
+	maxLength uint64
+	maxDepth  int
+	depth     int
+}
+
+func (pr propertyReader) readNestedMetadatablock(b io.Reader) (Block, error) {
+	nested := propertyReader{
+		maxLength: pr.maxLength,
+		maxDepth:  pr.maxDepth,
+		depth:     pr.depth + 1,
+	}
+	err := rapbin.CheckLimit("metadata nesting depth", uint64(nested.depth), uint64(pr.maxDepth))
+	if err != nil {
+		return EmptyBlock(), err
+	}
+
+	metadataKeys, _, err := rapbin.ReadStringArrayLimited(b, pr.maxLength)
 	if err != nil {
 		return EmptyBlock(), err
 	}
@@ -40,7 +58,7 @@ func readNestedMetadatablock(b io.Reader) (Block, error) {
 	metadata := make(map[string]Property)
 
 	for _, key := range metadataKeys {
-		metadata[key], err = ReadProperty(b)
+		metadata[key], err = nested.readProperty(b)
 		if err != nil {
 			return EmptyBlock(), err
 		}
@@ -49,10 +67,10 @@ func readNestedMetadatablock(b io.Reader) (Block, error) {
 	return NewBlock(metadata), nil
 }
 
-func readPropData(b io.Reader, propertyType byte) (Property, error) {
+func (pr propertyReader) readPropData(b io.Reader, propertyType byte) (Property, error) {
 	switch propertyType {
 	case 0:
-		str, _, err := rapbin.ReadString(b)
+		str, _, err := rapbin.ReadStringLimited(b, pr.maxLength)
 		if err != nil {
 			return nil, err
 		}
@@ -100,7 +118,7 @@ func readPropData(b io.Reader, propertyType byte) (Property, error) {
 		}
 		return NewVector3Property(vals[0], vals[1], vals[2]), nil
 	case 11:
-		metadataBlock, err := readNestedMetadatablock(b)
+		metadataBlock, err := pr.readNestedMetadatablock(b)
 		if err != nil {
 			return nil, err
 		}
@@ -115,7 +133,7 @@ func readPropData(b io.Reader, propertyType byte) (Property, error) {
 		return NewTimeProperty(time.UnixMicro(unixTimeMicro)), nil
 
 	case 16:
-		allbytes, _, err := rapbin.ReadBytesArray(b)
+		allbytes, _, err := rapbin.ReadBytesArrayLimited(b, pr.maxLength)
 		if err != nil {
 			return nil, err
 		}
@@ -126,7 +144,7 @@ func readPropData(b io.Reader, propertyType byte) (Property, error) {
 		}, err
 
 	case 18:
-		allbytes, _, err := rapbin.ReadBytesArray(b)
+		allbytes, _, err := rapbin.ReadBytesArrayLimited(b, pr.maxLength)
 		if err != nil {
 			return nil, err
 		}
@@ -149,24 +167,41 @@ func readPropData(b io.Reader, propertyType byte) (Property, error) {
 		if err != nil {
 			return nil, err
 		}
+		err = rapbin.CheckLimit("array length", len, pr.maxLength)
+		if err != nil {
+			return nil, err
+		}
 		adjustedType := propertyType - 13
-		props := make([]Property, len)
-		for i := 0; i < int(len); i++ {
-			props[i], err = readPropData(b, adjustedType)
+		props := make([]Property, 0, rapbin.InitialCapacity(len))
+		for i := uint64(0); i < len; i++ {
+			prop, err := pr.readPropData(b, adjustedType)
 			if err != nil {
 				return nil, err
 			}
+			props = append(props, prop)
 		}
 		return newArrayProperty(adjustedType, props), nil
 	}
 	return nil, fmt.Errorf("unrecognized property type code: %d", int(propertyType))
 }
 
-func ReadProperty(b io.Reader) (Property, error) {
+func (pr propertyReader) readProperty(b io.Reader) (Property, error) {
 	propByte := make([]byte, 1)
 	_, err := b.Read(propByte)
 	if err != nil {
 		return nil, err
 	}
-	return readPropData(b, propByte[0])
+	return pr.readPropData(b, propByte[0])
+}
+
+func ReadProperty(b io.Reader) (Property, error) {
+	return propertyReader{}.readProperty(b)
+}
+
+// ReadPropertyWithLimits reads a property, erroring if any string or array
+// within it is longer than maxLength, or if metadata blocks are nested deeper
+// than maxDepth. A limit of 0 means there is no limit. Limit errors can be
+// matched with errors.As against format/io's LimitError.
+func ReadPropertyWithLimits(b io.Reader, maxLength uint64, maxDepth int) (Property, error) {
+	return propertyReader{maxLength: maxLength, maxDepth: maxDepth}.readProperty(b)
 }
@@ -66,3 +66,79 @@ func Test_BasicIO(t *testing.T) {
 		})
 	}
 }
+
+func Test_ReadPropertyWithLimits(t *testing.T) {
+	tests := map[string]struct {
+		prop      metadata.Property
+		maxLength uint64
+		maxDepth  int
+		err       string
+	}{
+		"string within limit": {
+			prop:      metadata.NewStringProperty("dee"),
+			maxLength: 3,
+		},
+		"string too long": {
+			prop:      metadata.NewStringProperty("deee"),
+			maxLength: 3,
+			err:       "string length of 4 exceeds limit of 3",
+		},
+		"array too long": {
+			prop:      metadata.NewIntArrayProperty([]int{1, 2, 3, 4}),
+			maxLength: 3,
+			err:       "array length of 4 exceeds limit of 3",
+		},
+		"nested within limit": {
+			prop: metadata.NewMetadataProperty(metadata.NewBlock(map[string]metadata.Property{
+				"a": metadata.NewIntProperty(1),
+			})),
+			maxDepth: 1,
+		},
+		"nested too deep": {
+			prop: metadata.NewMetadataProperty(metadata.NewBlock(map[string]metadata.Property{
+				"a": metadata.NewMetadataProperty(metadata.EmptyBlock()),
+			})),
+			maxDepth: 1,
+			err:      "metadata nesting depth of 2 exceeds limit of 1",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			bufferData := bytes.Buffer{}
+			_, err := metadata.WriteProprty(&bufferData, tc.prop)
+			assert.NoError(t, err)
+
+			propBack, err := metadata.ReadPropertyWithLimits(&bufferData, tc.maxLength, tc.maxDepth)
+
+			if tc.err == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.prop, propBack)
+			} else {
+				assert.Nil(t, propBack)
+				assert.EqualError(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func Test_BlockKeysAreSorted(t *testing.T) {
+	// ARRANGE ================================================================
+	block := metadata.NewBlock(map[string]metadata.Property{
+		"c": metadata.NewIntProperty(3),
+		"a": metadata.NewIntProperty(1),
+		"b": metadata.NewIntProperty(2),
+	})
+	property := metadata.NewMetadataProperty(block)
+
+	// ACT ====================================================================
+	keys := block.Keys()
+	data := property.Data()
+
+	// ASSERT =================================================================
+	assert.Equal(t, []string{"a", "b", "c"}, keys)
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, data, metadata.NewMetadataProperty(block).Data())
+	}
+	assert.Empty(t, metadata.EmptyBlock().Keys())
+}
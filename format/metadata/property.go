@@ -7,7 +7,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"sort"
 	"strings"
 	"time"
 
@@ -467,15 +466,9 @@ func (mp MetadataProperty) Code() byte {
 }
 
 func (mp MetadataProperty) String() string {
-	keys := make([]string, 0, len(mp.block.Mapping()))
-	for k := range mp.block.Mapping() {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-
 	out := strings.Builder{}
 	out.WriteString("{\n")
-	for _, key := range keys {
+	for _, key := range mp.block.Keys() {
 		fmt.Fprintf(&out, "\t\"%s\": %s;\n", key, mp.block.Mapping()[key].String())
 	}
 	out.WriteString("}")
@@ -485,12 +478,7 @@ func (mp MetadataProperty) String() string {
 func (mp MetadataProperty) Data() []byte {
 	buf := new(bytes.Buffer)
 
-	i := 0
-	mappingWithIndex := make([]string, len(mp.block.Mapping()))
-	for key := range mp.block.Mapping() {
-		mappingWithIndex[i] = key
-		i++
-	}
+	mappingWithIndex := mp.block.Keys()
 
 	buf.Write(rapbin.StringArrayToBytes(mappingWithIndex))
 
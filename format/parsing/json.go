@@ -5,13 +5,18 @@ import (
 	"fmt"
 	"strconv"
 
+	vec2 "github.com/EliCDavis/vector/vector2"
 	"github.com/EliCDavis/vector/vector3"
+	"github.com/EliCDavis/vector/vector4"
 	"github.com/Jeffail/gabs"
 	"github.com/recolude/rap/format"
 	"github.com/recolude/rap/format/collection/enum"
 	"github.com/recolude/rap/format/collection/euler"
 	"github.com/recolude/rap/format/collection/event"
 	"github.com/recolude/rap/format/collection/position"
+	"github.com/recolude/rap/format/collection/quaternion"
+	"github.com/recolude/rap/format/collection/transform"
+	"github.com/recolude/rap/format/collection/vector2"
 	"github.com/recolude/rap/format/io"
 	"github.com/recolude/rap/format/metadata"
 )
@@ -214,6 +219,44 @@ func parseVector3(jsonObj *gabs.Container) (vector3.Float64, error) {
 	return vector3.New(x, y, z), err
 }
 
+func parseVector2(jsonObj *gabs.Container) (vec2.Float64, error) {
+	x, err := parseRequiredFloatKey(jsonObj, "vector2 capture", "x")
+	if err != nil {
+		return vec2.Zero[float64](), err
+	}
+
+	y, err := parseRequiredFloatKey(jsonObj, "vector2 capture", "y")
+	if err != nil {
+		return vec2.Zero[float64](), err
+	}
+
+	return vec2.New(x, y), err
+}
+
+func parseVector4(jsonObj *gabs.Container) (vector4.Float64, error) {
+	x, err := parseRequiredFloatKey(jsonObj, "quaternion capture", "x")
+	if err != nil {
+		return vector4.Zero[float64](), err
+	}
+
+	y, err := parseRequiredFloatKey(jsonObj, "quaternion capture", "y")
+	if err != nil {
+		return vector4.Zero[float64](), err
+	}
+
+	z, err := parseRequiredFloatKey(jsonObj, "quaternion capture", "z")
+	if err != nil {
+		return vector4.Zero[float64](), err
+	}
+
+	w, err := parseRequiredFloatKey(jsonObj, "quaternion capture", "w")
+	if err != nil {
+		return vector4.Zero[float64](), err
+	}
+
+	return vector4.New(x, y, z, w), err
+}
+
 func parsePositionCollection(name string, jsonCaptures []*gabs.Container) (format.CaptureCollection, error) {
 	captures := make([]position.Capture, len(jsonCaptures))
 
@@ -234,7 +277,22 @@ func parsePositionCollection(name string, jsonCaptures []*gabs.Container) (forma
 	return position.NewCollection(name, captures), nil
 }
 
-func parseEulerCollection(name string, jsonCaptures []*gabs.Container) (format.CaptureCollection, error) {
+// parseRotationOrder reads the optional order of a collection containing
+// euler angles, defaulting to ZXY.
+func parseRotationOrder(jsonObj *gabs.Container, thing string) (euler.RotationOrder, error) {
+	if jsonObj.Path("order") == nil {
+		return euler.ZXY, nil
+	}
+
+	order, err := parseRequiredStringKey(jsonObj, thing, "order")
+	if err != nil {
+		return euler.ZXY, err
+	}
+
+	return euler.ParseRotationOrder(order)
+}
+
+func parseEulerCollection(name string, order euler.RotationOrder, jsonCaptures []*gabs.Container) (format.CaptureCollection, error) {
 	captures := make([]euler.Capture, len(jsonCaptures))
 
 	for i, jsonCapture := range jsonCaptures {
@@ -248,10 +306,104 @@ func parseEulerCollection(name string, jsonCaptures []*gabs.Container) (format.C
 			return nil, err
 		}
 
-		captures[i] = euler.NewEulerZXYCapture(time, pos.X(), pos.Y(), pos.Z())
+		captures[i] = euler.NewCapture(time, pos.X(), pos.Y(), pos.Z())
 	}
 
-	return euler.NewCollection(name, captures), nil
+	return euler.NewCollectionWithOrder(name, order, captures), nil
+}
+
+func parseQuaternionCollection(name string, jsonCaptures []*gabs.Container) (format.CaptureCollection, error) {
+	captures := make([]quaternion.Capture, len(jsonCaptures))
+
+	for i, jsonCapture := range jsonCaptures {
+		time, err := parseCaptureTime(jsonCapture)
+		if err != nil {
+			return nil, err
+		}
+
+		rot, err := parseVector4(jsonCapture.Path("data"))
+		if err != nil {
+			return nil, err
+		}
+
+		captures[i] = quaternion.NewCapture(time, rot.X(), rot.Y(), rot.Z(), rot.W())
+	}
+
+	return quaternion.NewCollection(name, captures), nil
+}
+
+func parseVector2Collection(name string, jsonCaptures []*gabs.Container) (format.CaptureCollection, error) {
+	captures := make([]vector2.Capture, len(jsonCaptures))
+
+	for i, jsonCapture := range jsonCaptures {
+		time, err := parseCaptureTime(jsonCapture)
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := parseVector2(jsonCapture.Path("data"))
+		if err != nil {
+			return nil, err
+		}
+
+		captures[i] = vector2.NewCapture(time, v.X(), v.Y())
+	}
+
+	return vector2.NewCollection(name, captures), nil
+}
+
+// parseTransform reads a transform capture's data, with scale being
+// optional and defaulting to one along every axis.
+func parseTransform(jsonObj *gabs.Container) (vector3.Float64, vector3.Float64, vector3.Float64, error) {
+	positionNode := jsonObj.Path("position")
+	if positionNode == nil {
+		return vector3.Zero[float64](), vector3.Zero[float64](), vector3.Zero[float64](), errors.New("transform capture requires position property")
+	}
+
+	pos, err := parseVector3(positionNode)
+	if err != nil {
+		return vector3.Zero[float64](), vector3.Zero[float64](), vector3.Zero[float64](), err
+	}
+
+	rotationNode := jsonObj.Path("rotation")
+	if rotationNode == nil {
+		return vector3.Zero[float64](), vector3.Zero[float64](), vector3.Zero[float64](), errors.New("transform capture requires rotation property")
+	}
+
+	rot, err := parseVector3(rotationNode)
+	if err != nil {
+		return vector3.Zero[float64](), vector3.Zero[float64](), vector3.Zero[float64](), err
+	}
+
+	scale := vector3.One[float64]()
+	if scaleNode := jsonObj.Path("scale"); scaleNode != nil {
+		scale, err = parseVector3(scaleNode)
+		if err != nil {
+			return vector3.Zero[float64](), vector3.Zero[float64](), vector3.Zero[float64](), err
+		}
+	}
+
+	return pos, rot, scale, nil
+}
+
+func parseTransformCollection(name string, order euler.RotationOrder, jsonCaptures []*gabs.Container) (format.CaptureCollection, error) {
+	captures := make([]transform.Capture, len(jsonCaptures))
+
+	for i, jsonCapture := range jsonCaptures {
+		time, err := parseCaptureTime(jsonCapture)
+		if err != nil {
+			return nil, err
+		}
+
+		pos, rot, scale, err := parseTransform(jsonCapture.Path("data"))
+		if err != nil {
+			return nil, err
+		}
+
+		captures[i] = transform.NewCapture(time, pos, rot, scale)
+	}
+
+	return transform.NewCollectionWithOrder(name, order, captures), nil
 }
 
 func parseEnumCollection(name string, jsonCaptures []*gabs.Container) (format.CaptureCollection, error) {
@@ -345,7 +497,24 @@ func parseCollectionFromJSON(jsonObj *gabs.Container) (format.CaptureCollection,
 		return parsePositionCollection(name, childCaptures)
 
 	case "recolude.euler":
-		return parseEulerCollection(name, childCaptures)
+		order, err := parseRotationOrder(jsonObj, "euler collection")
+		if err != nil {
+			return nil, err
+		}
+		return parseEulerCollection(name, order, childCaptures)
+
+	case "recolude.transform":
+		order, err := parseRotationOrder(jsonObj, "transform collection")
+		if err != nil {
+			return nil, err
+		}
+		return parseTransformCollection(name, order, childCaptures)
+
+	case "recolude.quaternion":
+		return parseQuaternionCollection(name, childCaptures)
+
+	case "recolude.vector2":
+		return parseVector2Collection(name, childCaptures)
 
 	case "recolude.event":
 		return parseEventCollection(name, childCaptures)
@@ -4,7 +4,9 @@ import (
 	"testing"
 
 	"github.com/recolude/rap/format/collection/enum"
+	"github.com/recolude/rap/format/collection/euler"
 	"github.com/recolude/rap/format/collection/event"
+	"github.com/recolude/rap/format/collection/transform"
 	"github.com/recolude/rap/format/metadata"
 	"github.com/recolude/rap/format/parsing"
 
@@ -873,6 +875,292 @@ func Test_JSONObj_RotationCollectionCaptures(t *testing.T) {
 	assert.Equal(t, "[2.40] Rotation - 4.40, 5.50, 6.60", recording.CaptureCollections()[0].Captures()[1].String())
 }
 
+func Test_JSONObj_RotationCollectionOrder(t *testing.T) {
+	tests := map[string]struct {
+		order         string
+		expectedOrder euler.RotationOrder
+		err           string
+	}{
+		"default": {expectedOrder: euler.ZXY},
+		"XYZ":     {order: `"order": "XYZ",`, expectedOrder: euler.XYZ},
+		"ZYX":     {order: `"order": "ZYX",`, expectedOrder: euler.ZYX},
+		"unknown": {order: `"order": "ABC",`, err: "unrecognized rotation order: 'ABC'"},
+		"number":  {order: `"order": 1,`, err: "euler collection order must be string"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// ARRANGE ========================================================
+			payload := []byte(`{ 
+				"id": "my id", 
+				"name": "my name",
+				"collections": [
+					{
+						"type": "recolude.euler",
+						"name": "Some Rotations",
+						` + tc.order + `
+						"captures": [
+							{
+								"time": 1.3,
+								"data": {
+									"x": 1.1,
+									"y": 2.2,
+									"z": 3.3
+								}
+							}
+						]
+					}
+				]
+			}`)
+
+			// ACT ============================================================
+			recording, err := parsing.FromJSON(payload)
+
+			// ASSERT =========================================================
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			collection := recording.CaptureCollections()[0].(euler.Collection)
+			assert.Equal(t, tc.expectedOrder, collection.Order())
+			assert.Equal(t, "[1.30] Rotation - 1.10, 2.20, 3.30", collection.Captures()[0].String())
+		})
+	}
+}
+
+func Test_JSONObj_QuaternionCollectionCaptures(t *testing.T) {
+	// ARRANGE ================================================================
+	payload := []byte(`{ 
+		"id": "my id", 
+		"name": "my name",
+		"collections": [
+			{
+				"type": "recolude.quaternion",
+				"name": "Headset",
+				"captures": [
+					{
+						"time": 1.3,
+						"data": {
+							"x": 0,
+							"y": 0,
+							"z": 0,
+							"w": 1
+						}
+					},
+					{
+						"time": 2.4,
+						"data": {
+							"x": 0.5,
+							"y": 0.5,
+							"z": 0.5,
+							"w": 0.5
+						}
+					}
+				]
+			}
+		]
+	}`)
+
+	// ACT ====================================================================
+	recording, err := parsing.FromJSON(payload)
+
+	// ASSERT =================================================================
+	assert.NoError(t, err)
+	if assert.NotNil(t, recording) && assert.Len(t, recording.CaptureCollections(), 1) {
+		collection := recording.CaptureCollections()[0]
+		assert.Equal(t, "Headset", collection.Name())
+		assert.Equal(t, "recolude.quaternion", collection.Signature())
+		if assert.Len(t, collection.Captures(), 2) {
+			assert.Equal(t, "[1.30] Quaternion - 0.00, 0.00, 0.00, 1.00", collection.Captures()[0].String())
+			assert.Equal(t, "[2.40] Quaternion - 0.50, 0.50, 0.50, 0.50", collection.Captures()[1].String())
+		}
+	}
+}
+
+func Test_JSONObj_QuaternionCaptureMissingW(t *testing.T) {
+	// ARRANGE ================================================================
+	payload := []byte(`{ 
+		"id": "my id", 
+		"name": "my name",
+		"collections": [
+			{
+				"type": "recolude.quaternion",
+				"name": "Headset",
+				"captures": [
+					{
+						"time": 1.3,
+						"data": {
+							"x": 0,
+							"y": 0,
+							"z": 0
+						}
+					}
+				]
+			}
+		]
+	}`)
+
+	// ACT ====================================================================
+	recording, err := parsing.FromJSON(payload)
+
+	// ASSERT =================================================================
+	assert.EqualError(t, err, "quaternion capture requires w property")
+	assert.Nil(t, recording)
+}
+
+func Test_JSONObj_Vector2CollectionCaptures(t *testing.T) {
+	// ARRANGE ================================================================
+	payload := []byte(`{ 
+		"id": "my id", 
+		"name": "my name",
+		"collections": [
+			{
+				"type": "recolude.vector2",
+				"name": "Cursor",
+				"captures": [
+					{
+						"time": 1.3,
+						"data": {
+							"x": 1,
+							"y": 2
+						}
+					},
+					{
+						"time": 2.4,
+						"data": {
+							"x": 3.5,
+							"y": -4
+						}
+					}
+				]
+			}
+		]
+	}`)
+
+	// ACT ====================================================================
+	recording, err := parsing.FromJSON(payload)
+
+	// ASSERT =================================================================
+	assert.NoError(t, err)
+	if assert.NotNil(t, recording) && assert.Len(t, recording.CaptureCollections(), 1) {
+		collection := recording.CaptureCollections()[0]
+		assert.Equal(t, "Cursor", collection.Name())
+		assert.Equal(t, "recolude.vector2", collection.Signature())
+		if assert.Len(t, collection.Captures(), 2) {
+			assert.Equal(t, "[1.30] Vector2 - 1.00, 2.00", collection.Captures()[0].String())
+			assert.Equal(t, "[2.40] Vector2 - 3.50, -4.00", collection.Captures()[1].String())
+		}
+	}
+}
+
+func Test_JSONObj_Vector2CaptureMissingY(t *testing.T) {
+	// ARRANGE ================================================================
+	payload := []byte(`{ 
+		"id": "my id", 
+		"name": "my name",
+		"collections": [
+			{
+				"type": "recolude.vector2",
+				"name": "Cursor",
+				"captures": [
+					{
+						"time": 1.3,
+						"data": {
+							"x": 1
+						}
+					}
+				]
+			}
+		]
+	}`)
+
+	// ACT ====================================================================
+	recording, err := parsing.FromJSON(payload)
+
+	// ASSERT =================================================================
+	assert.EqualError(t, err, "vector2 capture requires y property")
+	assert.Nil(t, recording)
+}
+
+func Test_JSONObj_TransformCollectionCaptures(t *testing.T) {
+	// ARRANGE ================================================================
+	payload := []byte(`{ 
+		"id": "my id", 
+		"name": "my name",
+		"collections": [
+			{
+				"type": "recolude.transform",
+				"name": "Body",
+				"order": "XYZ",
+				"captures": [
+					{
+						"time": 1.3,
+						"data": {
+							"position": {"x": 1, "y": 2, "z": 3},
+							"rotation": {"x": 4, "y": 5, "z": 6},
+							"scale": {"x": 7, "y": 8, "z": 9}
+						}
+					},
+					{
+						"time": 2.4,
+						"data": {
+							"position": {"x": 1, "y": 2, "z": 3},
+							"rotation": {"x": 4, "y": 5, "z": 6}
+						}
+					}
+				]
+			}
+		]
+	}`)
+
+	// ACT ====================================================================
+	recording, err := parsing.FromJSON(payload)
+
+	// ASSERT =================================================================
+	assert.NoError(t, err)
+	if assert.NotNil(t, recording) && assert.Len(t, recording.CaptureCollections(), 1) {
+		collection := recording.CaptureCollections()[0]
+		assert.Equal(t, "Body", collection.Name())
+		assert.Equal(t, "recolude.transform", collection.Signature())
+		assert.Equal(t, euler.XYZ, collection.(transform.Collection).Order())
+		if assert.Len(t, collection.Captures(), 2) {
+			assert.Equal(t, "[1.30] Transform - (1.00, 2.00, 3.00), (4.00, 5.00, 6.00), (7.00, 8.00, 9.00)", collection.Captures()[0].String())
+			assert.Equal(t, "[2.40] Transform - (1.00, 2.00, 3.00), (4.00, 5.00, 6.00), (1.00, 1.00, 1.00)", collection.Captures()[1].String())
+		}
+	}
+}
+
+func Test_JSONObj_TransformCaptureMissingRotation(t *testing.T) {
+	// ARRANGE ================================================================
+	payload := []byte(`{ 
+		"id": "my id", 
+		"name": "my name",
+		"collections": [
+			{
+				"type": "recolude.transform",
+				"name": "Body",
+				"captures": [
+					{
+						"time": 1.3,
+						"data": {
+							"position": {"x": 1, "y": 2, "z": 3}
+						}
+					}
+				]
+			}
+		]
+	}`)
+
+	// ACT ====================================================================
+	recording, err := parsing.FromJSON(payload)
+
+	// ASSERT =================================================================
+	assert.EqualError(t, err, "transform capture requires rotation property")
+	assert.Nil(t, recording)
+}
+
 func Test_JSONObj_EventCollectionCaptures(t *testing.T) {
 	// ARRANGE ================================================================
 	payload := []byte(`{ 
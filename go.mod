@@ -7,6 +7,8 @@ require (
 	github.com/Jeffail/gabs v1.4.0
 	github.com/golang/mock v1.6.0
 	github.com/golang/protobuf v1.5.3
+	github.com/klauspost/compress v1.16.7
+	github.com/pierrec/lz4/v4 v4.1.21
 	github.com/stretchr/testify v1.8.2
 	github.com/urfave/cli/v2 v2.25.3
 	go.mongodb.org/mongo-driver v1.11.6
@@ -21,19 +21,25 @@ func BytesArrayToBytes(b []byte) []byte {
 // ReadBytesArray first reads the length of the byte array, then reads in a
 // buffer of that length
 func ReadBytesArray(r io.Reader) ([]byte, int, error) {
+	return ReadBytesArrayLimited(r, 0)
+}
+
+// ReadBytesArrayLimited reads a byte array, erroring if it's length exceeds
+// maxLength. A maxLength of 0 means there is no limit.
+func ReadBytesArrayLimited(r io.Reader, maxLength uint64) ([]byte, int, error) {
 	len, bytesRead, err := ReadUvarint(r)
 	if err != nil {
 		return nil, bytesRead, err
 	}
 
-	out := make([]byte, len)
-	read, err := io.ReadFull(r, out)
-	if err != nil && err != io.EOF {
-		return nil, read + bytesRead, err
+	err = CheckLimit("byte array length", len, maxLength)
+	if err != nil {
+		return nil, bytesRead, err
 	}
 
-	if read != int(len) {
-		return nil, read + bytesRead, io.ErrUnexpectedEOF
+	out, read, err := readFixed(r, len)
+	if err != nil {
+		return nil, read + bytesRead, err
 	}
 
 	return out, read + bytesRead, nil
@@ -0,0 +1,69 @@
+package binary
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+)
+
+// LimitError is returned when a value read exceeds a limit set by the caller,
+// typically because the data being read is malformed or hostile.
+type LimitError struct {
+	// Limit describes what was being limited, like "string length"
+	Limit string
+
+	// Value is what was found in the data
+	Value uint64
+
+	// Max is the largest value permitted
+	Max uint64
+}
+
+func (e LimitError) Error() string {
+	return fmt.Sprintf("%s of %d exceeds limit of %d", e.Limit, e.Value, e.Max)
+}
+
+// CheckLimit returns a LimitError if the value exceeds max. A max of 0 means
+// there is no limit.
+func CheckLimit(limit string, value, max uint64) error {
+	if max > 0 && value > max {
+		return LimitError{Limit: limit, Value: value, Max: max}
+	}
+	return nil
+}
+
+// maxUpfrontAllocation is the largest buffer a length prefix can allocate
+// before any data backing it has actually been read.
+const maxUpfrontAllocation = 64 * 1024
+
+// InitialCapacity returns how much space to reserve for a length prefixed
+// collection, so a corrupt length can't allocate more than the data supports.
+func InitialCapacity(length uint64) int {
+	if length > maxUpfrontAllocation {
+		return maxUpfrontAllocation
+	}
+	return int(length)
+}
+
+// readFixed reads exactly length bytes, growing the buffer as data arrives
+// instead of trusting the length up front.
+func readFixed(r io.Reader, length uint64) ([]byte, int, error) {
+	if length <= maxUpfrontAllocation {
+		out := make([]byte, length)
+		read, err := io.ReadFull(r, out)
+		return out, read, err
+	}
+
+	if length > math.MaxInt64 {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+
+	buf := bytes.Buffer{}
+	buf.Grow(maxUpfrontAllocation)
+	read, err := io.CopyN(&buf, r, int64(length))
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return buf.Bytes(), int(read), err
+}
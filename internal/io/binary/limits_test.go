@@ -0,0 +1,79 @@
+package binary_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	rapbinary "github.com/recolude/rap/internal/io/binary"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ReadLimited_ErrorsWhenLengthExceedsLimit(t *testing.T) {
+	tests := map[string]struct {
+		data  []byte
+		read  func(r io.Reader) error
+		limit string
+	}{
+		"string": {
+			data: rapbinary.StringToBytes("hello"),
+			read: func(r io.Reader) error {
+				_, _, err := rapbinary.ReadStringLimited(r, 4)
+				return err
+			},
+			limit: "string length",
+		},
+		"bytes": {
+			data: rapbinary.BytesArrayToBytes([]byte{1, 2, 3, 4, 5}),
+			read: func(r io.Reader) error {
+				_, _, err := rapbinary.ReadBytesArrayLimited(r, 4)
+				return err
+			},
+			limit: "byte array length",
+		},
+		"string array": {
+			data: rapbinary.StringArrayToBytes([]string{"a", "b", "c", "d", "e"}),
+			read: func(r io.Reader) error {
+				_, _, err := rapbinary.ReadStringArrayLimited(r, 4)
+				return err
+			},
+			limit: "array length",
+		},
+		"uint array": {
+			data: rapbinary.UvarintArrayToBytes([]uint{1, 2, 3, 4, 5}),
+			read: func(r io.Reader) error {
+				_, _, err := rapbinary.ReadUvarIntArrayLimited(r, 4)
+				return err
+			},
+			limit: "array length",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.read(bytes.NewReader(tc.data))
+
+			var limitErr rapbinary.LimitError
+			if assert.ErrorAs(t, err, &limitErr) {
+				assert.Equal(t, tc.limit, limitErr.Limit)
+				assert.Equal(t, uint64(5), limitErr.Value)
+				assert.Equal(t, uint64(4), limitErr.Max)
+			}
+		})
+	}
+}
+
+func Test_ReadBytesArray_HugeLengthPrefixDoesNotTrustLength(t *testing.T) {
+	// ARRANGE ================================================================
+	data := make([]byte, binary.MaxVarintLen64)
+	read := binary.PutUvarint(data, 1<<40)
+	data = append(data[:read], 1, 2, 3)
+
+	// ACT ====================================================================
+	out, _, err := rapbinary.ReadBytesArray(bytes.NewReader(data))
+
+	// ASSERT =================================================================
+	assert.Nil(t, out)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
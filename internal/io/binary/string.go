@@ -16,13 +16,23 @@ func StringToBytes(str string) []byte {
 }
 
 func ReadString(r io.Reader) (string, int, error) {
+	return ReadStringLimited(r, 0)
+}
+
+// ReadStringLimited reads a string, erroring if it's length exceeds
+// maxLength. A maxLength of 0 means there is no limit.
+func ReadStringLimited(r io.Reader, maxLength uint64) (string, int, error) {
 	len, bytesRead, err := ReadUvarint(r)
 	if err != nil {
 		return "", bytesRead, err
 	}
 
-	strBuffer := make([]byte, len)
-	moreBytes, err := io.ReadFull(r, strBuffer)
+	err = CheckLimit("string length", len, maxLength)
+	if err != nil {
+		return "", bytesRead, err
+	}
+
+	strBuffer, moreBytes, err := readFixed(r, len)
 	if err != nil {
 		return "", bytesRead + moreBytes, err
 	}
@@ -44,19 +54,31 @@ func StringArrayToBytes(strs []string) []byte {
 }
 
 func ReadStringArray(r io.Reader) ([]string, int, error) {
+	return ReadStringArrayLimited(r, 0)
+}
+
+// ReadStringArrayLimited reads an array of strings, erroring if the number
+// of strings or the length of any one string exceeds maxLength. A maxLength
+// of 0 means there is no limit.
+func ReadStringArrayLimited(r io.Reader, maxLength uint64) ([]string, int, error) {
 	len, bytesRead, err := ReadUvarint(r)
 	if err != nil {
 		return nil, bytesRead, err
 	}
 
-	out := make([]string, len)
-	for i := range out {
-		str, read, err := ReadString(r)
+	err = CheckLimit("array length", len, maxLength)
+	if err != nil {
+		return nil, bytesRead, err
+	}
+
+	out := make([]string, 0, InitialCapacity(len))
+	for i := uint64(0); i < len; i++ {
+		str, read, err := ReadStringLimited(r, maxLength)
 		bytesRead += read
 		if err != nil {
 			return nil, bytesRead, err
 		}
-		out[i] = str
+		out = append(out, str)
 	}
 
 	return out, bytesRead, nil
@@ -21,19 +21,31 @@ func UvarintArrayToBytes(strs []uint) []byte {
 }
 
 func ReadUvarIntArray(r io.Reader) ([]uint, int, error) {
+	return ReadUvarIntArrayLimited(r, 0)
+}
+
+// ReadUvarIntArrayLimited reads an array of unsigned integers, erroring if
+// the number of entries exceeds maxLength. A maxLength of 0 means there is no
+// limit.
+func ReadUvarIntArrayLimited(r io.Reader, maxLength uint64) ([]uint, int, error) {
 	len, bytesRead, err := ReadUvarint(r)
 	if err != nil {
 		return nil, bytesRead, err
 	}
 
-	out := make([]uint, len)
-	for i := range out {
+	err = CheckLimit("array length", len, maxLength)
+	if err != nil {
+		return nil, bytesRead, err
+	}
+
+	out := make([]uint, 0, InitialCapacity(len))
+	for i := uint64(0); i < len; i++ {
 		str, read, err := ReadUvarint(r)
 		bytesRead += read
 		if err != nil {
 			return nil, bytesRead, err
 		}
-		out[i] = uint(str)
+		out = append(out, uint(str))
 	}
 
 	return out, bytesRead, nil
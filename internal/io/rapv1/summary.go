@@ -0,0 +1,96 @@
+package rapv1
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/recolude/rap/format/metadata"
+)
+
+// CollectionSummary describes a capture collection without holding onto any
+// of it's captures.
+type CollectionSummary struct {
+	Name      string
+	Signature string
+	Captures  int
+	Start     float64
+	End       float64
+}
+
+// RecordingSummary describes a recording without converting any of it's
+// captures into collections.
+type RecordingSummary struct {
+	ID          string
+	Name        string
+	Metadata    metadata.Block
+	Collections []CollectionSummary
+	Recordings  []RecordingSummary
+}
+
+func summarizeCollection(name, signature string, count int, timeAt func(int) float32) CollectionSummary {
+	summary := CollectionSummary{
+		Name:      name,
+		Signature: signature,
+		Captures:  count,
+	}
+
+	if count > 0 {
+		summary.Start = float64(timeAt(0))
+		summary.End = float64(timeAt(count - 1))
+	}
+
+	return summary
+}
+
+func summarizeEvents(events []*CustomEventCapture) CollectionSummary {
+	return summarizeCollection("Custom Event", "recolude.event", len(events), func(i int) float32 {
+		return events[i].GetTime()
+	})
+}
+
+// SummarizeRecording reads a v1 recording, describing the collections it
+// would contain once read without ever building them.
+func SummarizeRecording(file io.Reader, quaternionRotations bool) (RecordingSummary, int, error) {
+	recording, bytesRead, err := readProtobuf(file)
+	if err != nil {
+		return RecordingSummary{}, bytesRead, err
+	}
+
+	rotationSignature := "recolude.euler"
+	if quaternionRotations {
+		rotationSignature = "recolude.quaternion"
+	}
+
+	subjects := make([]RecordingSummary, len(recording.GetSubjects()))
+	for i, subject := range recording.GetSubjects() {
+		positions := subject.GetCapturedPositions()
+		rotations := subject.GetCapturedRotations()
+		lifeCycle := subject.GetLifecycleEvents()
+
+		subjects[i] = RecordingSummary{
+			ID:       fmt.Sprint(subject.GetId()),
+			Name:     subject.GetName(),
+			Metadata: convertMetadata(subject.GetMetadata()),
+			Collections: []CollectionSummary{
+				summarizeCollection("Position", "recolude.position", len(positions), func(i int) float32 {
+					return positions[i].GetTime()
+				}),
+				summarizeCollection("Rotation", rotationSignature, len(rotations), func(i int) float32 {
+					return rotations[i].GetTime()
+				}),
+				summarizeEvents(subject.GetCustomEvents()),
+				summarizeCollection("Life Cycle", "recolude.enum", len(lifeCycle), func(i int) float32 {
+					return lifeCycle[i].GetTime()
+				}),
+			},
+		}
+	}
+
+	return RecordingSummary{
+		ID:          recording.GetName(),
+		Name:        recording.GetName(),
+		Metadata:    convertMetadata(recording.GetMetadata()),
+		Collections: []CollectionSummary{summarizeEvents(recording.GetCustomEvents())},
+		Recordings:  subjects,
+	}, bytesRead, nil
+}
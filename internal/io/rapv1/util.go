@@ -16,6 +16,7 @@ import (
 	"github.com/recolude/rap/format/collection/euler"
 	"github.com/recolude/rap/format/collection/event"
 	"github.com/recolude/rap/format/collection/position"
+	"github.com/recolude/rap/format/collection/quaternion"
 	"github.com/recolude/rap/format/metadata"
 )
 
@@ -79,7 +80,9 @@ func convertMetadata(original map[string]string) metadata.Block {
 	return metadata.NewBlock(out)
 }
 
-func protobufToStd(inRec *Recording) (format.Recording, error) {
+// protobufToStd converts a v1 recording, optionally converting it's euler
+// rotations into quaternions.
+func protobufToStd(inRec *Recording, quaternionRotations bool) (format.Recording, error) {
 	subjectRecordings := make([]format.Recording, len(inRec.GetSubjects()))
 	lifecycleEnumMembers := []string{"START", "ENABLE", "DISABLE", "DESTROY"}
 	for subjectIndex, rec := range inRec.GetSubjects() {
@@ -105,6 +108,20 @@ func protobufToStd(inRec *Recording) (format.Recording, error) {
 			)
 		}
 
+		var rotationStream format.CaptureCollection = euler.NewCollection("Rotation", rotationCaptures)
+		if quaternionRotations {
+			quaternionCaptures := make([]quaternion.Capture, len(rotationCaptures))
+			for rotIndex, rot := range rotationCaptures {
+				quaternionCaptures[rotIndex] = quaternion.NewEulerZXYCapture(
+					rot.Time(),
+					rot.EulerZXY().X(),
+					rot.EulerZXY().Y(),
+					rot.EulerZXY().Z(),
+				)
+			}
+			rotationStream = quaternion.NewCollection("Rotation", quaternionCaptures)
+		}
+
 		for lifeIndex, lifeEvent := range rec.GetLifecycleEvents() {
 			lifeCycleCaptures[lifeIndex] = enum.NewCapture(
 				float64(lifeEvent.GetTime()),
@@ -113,7 +130,6 @@ func protobufToStd(inRec *Recording) (format.Recording, error) {
 		}
 
 		positionStream := position.NewCollection("Position", positionCaptures)
-		rotationStream := euler.NewCollection("Rotation", rotationCaptures)
 		lifeStream := enum.NewCollection("Life Cycle", lifecycleEnumMembers, lifeCycleCaptures)
 
 		subjectRecordings[subjectIndex] = &recordingV1{
@@ -138,7 +154,9 @@ func protobufToStd(inRec *Recording) (format.Recording, error) {
 	}, nil
 }
 
-func ReadRecording(file io.Reader) (format.Recording, int, error) {
+// readProtobuf reads and decompresses the single protobuf recording found in
+// a v1 file.
+func readProtobuf(file io.Reader) (*Recording, int, error) {
 	numberOfRecordings, bytesReadNumberRec, err := getNumberOfRecordings(file)
 	if err != nil {
 		return nil, bytesReadNumberRec, err
@@ -187,7 +205,19 @@ func ReadRecording(file io.Reader) (format.Recording, int, error) {
 		return nil, bytesRead, err
 	}
 
-	rec, err := protobufToStd(recording)
+	return recording, bytesRead, nil
+}
+
+// ReadRecording reads a v1 recording, whose rotations are read in as
+// recolude.quaternion collections instead of recolude.euler collections when
+// quaternionRotations is set.
+func ReadRecording(file io.Reader, quaternionRotations bool) (format.Recording, int, error) {
+	recording, bytesRead, err := readProtobuf(file)
+	if err != nil {
+		return nil, bytesRead, err
+	}
+
+	rec, err := protobufToStd(recording, quaternionRotations)
 
 	return rec, bytesRead, err
 }